@@ -1,24 +1,44 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/affandisy/goshop/cmd/route"
+	"github.com/affandisy/goshop/internal/currency"
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/event"
 	"github.com/affandisy/goshop/internal/handler"
+	"github.com/affandisy/goshop/internal/inventory"
 	"github.com/affandisy/goshop/internal/middleware"
+	"github.com/affandisy/goshop/internal/notification"
 	"github.com/affandisy/goshop/internal/repository"
 	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/internal/webhook"
 	"github.com/affandisy/goshop/pkg/cache"
 	"github.com/affandisy/goshop/pkg/config"
 	"github.com/affandisy/goshop/pkg/database"
+	"github.com/affandisy/goshop/pkg/i18n"
+	"github.com/affandisy/goshop/pkg/idempotency"
+	"github.com/affandisy/goshop/pkg/logger"
+	"github.com/affandisy/goshop/pkg/mailer"
+	"github.com/affandisy/goshop/pkg/metrics"
 	"github.com/affandisy/goshop/pkg/payment"
+	"github.com/affandisy/goshop/pkg/ratelimit"
 	"github.com/affandisy/goshop/pkg/redis"
+	"github.com/affandisy/goshop/pkg/tracing"
 	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/affandisy/goshop/pkg/worker"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
@@ -30,6 +50,21 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Structured logger; the standard log package is routed through it too
+	appLogger := logger.New(cfg.Environment)
+	slog.SetDefault(appLogger)
+
+	// Tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		SampleRatio:  cfg.TracingSampleRatio,
+		Environment:  cfg.Environment,
+	})
+	if err != nil {
+		log.Fatalf("Tracing initialization failed: %v", err)
+	}
+
 	// Init jwt
 	utils.InitJWt(cfg.AuthSecret)
 
@@ -37,7 +72,19 @@ func main() {
 	if err := database.Connect(cfg.DatabaseURI, cfg.Environment == "production"); err != nil {
 		log.Fatalf("Database connection failed: %v", err)
 	}
-	defer database.Close()
+
+	// Metrics
+	if err := metrics.Register(); err != nil {
+		log.Fatalf("Metrics registration failed: %v", err)
+	}
+
+	if err := database.GetDB().Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Database metrics plugin failed: %v", err)
+	}
+
+	if err := database.GetDB().Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Database tracing plugin failed: %v", err)
+	}
 
 	// Auto Migration
 	if err := database.AutoMigrate(); err != nil {
@@ -53,56 +100,315 @@ func main() {
 	if err := redis.Connect(cfg.RedisURI, cfg.RedisPassword, cfg.RedisDB); err != nil {
 		log.Fatalf("Redis connection failed: %v", err)
 	}
-	defer redis.Close()
 
 	redisClient := redis.GetClient()
 	cacheService := cache.NewCacheService(redisClient)
 
 	// midtrans client
-	midtransClient := payment.NewMidtransClient(cfg.MidtransServerKey, cfg.MidtransClientKey, cfg.MidtransEnvironment)
+	midtransClient := payment.NewMidtransClient(cfg.MidtransServerKey, cfg.MidtransClientKey, cfg.MidtransEnvironment, appLogger)
+
+	// Mailer
+	appMailer, err := mailer.New(mailer.Config{
+		Driver: cfg.Mailer.Driver,
+		From:   cfg.Mailer.From,
+		SMTP: mailer.SMTPConfig{
+			Host:     cfg.Mailer.SMTPHost,
+			Port:     cfg.Mailer.SMTPPort,
+			Username: cfg.Mailer.SMTPUsername,
+			Password: cfg.Mailer.SMTPPassword,
+		},
+		FileDir: cfg.Mailer.FileDir,
+	}, appLogger)
+	if err != nil {
+		log.Fatalf("Mailer initialization failed: %v", err)
+	}
 
+	// Notifications
+	var notificationChannels []notification.Channel
+	for _, name := range cfg.Notification.Channels {
+		switch name {
+		case notification.ChannelEmail:
+			notificationChannels = append(notificationChannels, notification.NewEmailChannel(appMailer))
+		case notification.ChannelWebhook:
+			if cfg.Notification.WebhookURL == "" {
+				log.Fatal("Notification webhook channel requires notification.webhook_url")
+			}
+			notificationChannels = append(notificationChannels, notification.NewWebhookChannel(cfg.Notification.WebhookURL, cfg.Notification.WebhookSecret, cfg.Notification.WebhookTimeout))
+		case notification.ChannelLog:
+			notificationChannels = append(notificationChannels, notification.NewLogChannel(appLogger))
+		default:
+			log.Fatalf("Unknown notification channel %q", name)
+		}
+	}
+	orderLinks := service.OrderLinks{BaseURL: cfg.AppBaseURL, GuestTokenTTL: cfg.GuestOrderTokenTTL}
+	renderer, err := notification.NewRenderer(cfg.Notification.DefaultLocale, orderLinks.URL)
+	if err != nil {
+		log.Fatalf("Notification templates failed to load: %v", err)
+	}
 	db := database.GetDB()
+	processedEventRepo := repository.NewProcessedEventRepository(db)
+	notifier := notification.NewDispatcher(renderer, notificationChannels, processedEventRepo, notification.DispatcherConfig{
+		QueueSize:      cfg.Notification.QueueSize,
+		Workers:        cfg.Notification.Workers,
+		MaxAttempts:    cfg.Notification.MaxAttempts,
+		RetryBaseDelay: cfg.Notification.RetryBaseDelay,
+		RetryMaxDelay:  cfg.Notification.RetryMaxDelay,
+	}, appLogger)
+
 	userRepo := repository.NewUserRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
 	categoryRepo := repository.NewCategoryRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	priceRuleRepo := repository.NewPriceRuleRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+	productTranslationRepo := repository.NewProductTranslationRepository(db)
+	categoryTranslationRepo := repository.NewCategoryTranslationRepository(db)
 	orderRepo := repository.NewOrderRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	webhookEndpointRepo := repository.NewWebhookEndpointRepository(db)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	wishlistRepo := repository.NewWishlistRepository(db)
+	warehouseRepo := repository.NewWarehouseRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	stockTransferRepo := repository.NewStockTransferRepository(db)
+	stockReservationRepo := repository.NewStockReservationRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	orderSequenceRepo := repository.NewOrderSequenceRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Rate limiter
+	var rateLimiter ratelimit.Limiter
+	switch cfg.RateLimit.Backend {
+	case "redis":
+		rateLimiter = ratelimit.NewRedisLimiter(redisClient)
+	case "memory":
+		rateLimiter = ratelimit.NewMemoryLimiter()
+	}
+
+	// Idempotency keys
+	var idempotencyStore idempotency.Store
+	switch cfg.Idempotency.Backend {
+	case "redis":
+		idempotencyStore = idempotency.NewRedisStore(redisClient)
+	case "memory":
+		idempotencyStore = idempotency.NewMemoryStore()
+	}
+
+	webhookService := service.NewWebhookService(webhookEndpointRepo, webhookDeliveryRepo)
+	wishlistService := service.NewWishlistService(wishlistRepo, productRepo, notifier, rateLimiter, service.WishlistServiceConfig{
+		AlertLimit:          cfg.Wishlist.AlertLimit,
+		AlertWindow:         cfg.Wishlist.AlertWindow,
+		BackInStockCooldown: cfg.Wishlist.BackInStockCooldown,
+	}, appLogger)
+
+	// Domain events
+	eventPublisher := event.NewOutboxPublisher(outboxRepo)
+	eventBus := event.NewBus(processedEventRepo)
+	service.RegisterEventHandlers(eventBus, orderRepo, cacheService, notifier, webhookService, wishlistService)
+
+	var eventSinks []event.Sink
+	if cfg.Outbox.RedisStream != "" {
+		eventSinks = append(eventSinks, event.NewRedisStreamSink(redisClient, cfg.Outbox.RedisStream, cfg.Outbox.RedisStreamMaxLen))
+	}
+	outboxRelay := event.NewRelay(transactor, outboxRepo, eventBus, eventSinks, event.RelayConfig{
+		PollInterval:   cfg.Outbox.PollInterval,
+		BatchSize:      cfg.Outbox.BatchSize,
+		MaxAttempts:    cfg.Outbox.MaxAttempts,
+		RetryBaseDelay: cfg.Outbox.RetryBaseDelay,
+		RetryMaxDelay:  cfg.Outbox.RetryMaxDelay,
+		LeaseDuration:  cfg.Outbox.LeaseDuration,
+		Retention:      cfg.Outbox.Retention,
+	}, appLogger)
+
+	webhookDeliverer := webhook.NewDeliverer(transactor, webhookEndpointRepo, webhookDeliveryRepo, webhook.DelivererConfig{
+		PollInterval:   cfg.Webhooks.PollInterval,
+		BatchSize:      cfg.Webhooks.BatchSize,
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		RetryBaseDelay: cfg.Webhooks.RetryBaseDelay,
+		RetryMaxDelay:  cfg.Webhooks.RetryMaxDelay,
+		Timeout:        cfg.Webhooks.Timeout,
+		DisableAfter:   cfg.Webhooks.DisableAfter,
+	}, appLogger)
+
+	var lowStockAlerter inventory.Alerter = inventory.NewLogAlerter(appLogger)
+	if len(cfg.Inventory.AlertRecipients) > 0 {
+		lowStockAlerter = inventory.NewNotifierAlerter(notifier, cfg.Inventory.AlertRecipients)
+	}
+	lowStockMonitor := inventory.NewLowStockMonitor(transactor, productRepo, lowStockAlerter, inventory.MonitorConfig{
+		Interval:  cfg.Inventory.LowStockCheckInterval,
+		BatchSize: cfg.Inventory.LowStockBatchSize,
+	}, appLogger)
+
+	allocator, err := inventory.NewAllocator(inventory.Strategy(cfg.Inventory.AllocationStrategy))
+	if err != nil {
+		log.Fatalf("Invalid inventory config: %v", err)
+	}
+	stockManager := inventory.NewManager(inventoryRepo, warehouseRepo, stockReservationRepo, allocator)
+
+	roundingPolicy := domain.RoundingPolicy{
+		Mode:       domain.RoundingMode(cfg.Currency.Rounding),
+		Increments: make(map[domain.Currency]int64, len(cfg.Currency.RoundingIncrements)),
+	}
+	for code, increment := range cfg.Currency.RoundingIncrements {
+		roundingPolicy.Increments[domain.Currency(strings.ToUpper(code))] = increment
+	}
+	if err := roundingPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid currency config: %v", err)
+	}
+	converter := currency.NewConverter(exchangeRateRepo, roundingPolicy)
+
+	orderNumberFormat := domain.OrderNumberFormat{
+		Layout:         cfg.OrderNumber.Format,
+		SequenceDigits: cfg.OrderNumber.SequenceDigits,
+	}
+	if err := orderNumberFormat.Validate(); err != nil {
+		log.Fatalf("Invalid order number config: %v", err)
+	}
+	orderNumbers := service.NewOrderNumberGenerator(orderSequenceRepo, orderNumberFormat)
 
-	userService := service.NewUserService(userRepo)
-	categoryService := service.NewCategoryService(categoryRepo, cacheService)
-	productService := service.NewProductService(productRepo, categoryRepo, cacheService)
-	orderService := service.NewOrderService(orderRepo, productRepo)
-	paymentService := service.NewPaymentService(paymentRepo, orderRepo, midtransClient)
+	userService := service.NewUserService(userRepo, loginAttemptRepo, userTokenRepo, orderRepo, appMailer, service.UserServiceConfig{
+		Lockout: domain.LockoutPolicy{
+			MaxFailedAttempts: cfg.Lockout.MaxFailedAttempts,
+			BaseDuration:      cfg.Lockout.BaseDuration,
+			MaxDuration:       cfg.Lockout.MaxDuration,
+			IPMaxFailures:     cfg.Lockout.IPMaxFailures,
+			IPWindow:          cfg.Lockout.IPWindow,
+		},
+		VerificationTokenTTL:  cfg.VerificationTokenTTL,
+		PasswordResetTokenTTL: cfg.PasswordResetTokenTTL,
+		AppBaseURL:            cfg.AppBaseURL,
+	}, appLogger)
+	categoryService := service.NewCategoryService(categoryRepo, categoryTranslationRepo, cacheService)
+	productService := service.NewProductService(productRepo, categoryRepo, priceRuleRepo, priceHistoryRepo, productTranslationRepo, categoryTranslationRepo, cacheService, transactor, stockManager, eventPublisher, converter)
+	orderService := service.NewOrderService(orderRepo, productRepo, priceRuleRepo, userRepo, transactor, stockManager, cfg.Inventory.ReservationTTL, eventPublisher, notifier, orderLinks, orderNumbers, converter, appLogger)
+	reviewService := service.NewReviewService(reviewRepo, productRepo, orderRepo, userRepo, cacheService, transactor)
+	reportService := service.NewReportService(userRepo, productRepo, orderRepo)
+	paymentService := service.NewPaymentService(paymentRepo, orderRepo, midtransClient, transactor, stockManager, eventPublisher, notifier, appLogger)
+	reservationSweeper := inventory.NewReservationSweeper(stockReservationRepo, orderService, inventory.SweeperConfig{
+		Interval:  cfg.Inventory.ReservationSweepInterval,
+		BatchSize: cfg.Inventory.ReservationSweepBatchSize,
+	}, appLogger)
+	wishlistAlertSweeper := service.NewWishlistAlertSweeper(wishlistService, service.WishlistAlertSweeperConfig{
+		Interval:  cfg.Wishlist.AlertSweepInterval,
+		BatchSize: cfg.Wishlist.AlertSweepBatchSize,
+	}, appLogger)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo)
+	inventoryService := service.NewInventoryService(warehouseRepo, inventoryRepo, stockTransferRepo, productRepo, stockManager, transactor, eventPublisher, cacheService)
 
 	userHandler := handler.NewUserHandler(userService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	productHandler := handler.NewProductHandler(productService)
 	orderHandler := handler.NewOrderHandler(orderService)
 	cacheHandler := handler.NewCacheHandler(cacheService)
-	paymentHandler := handler.NewPaymentHandler(paymentService)
+	paymentHandler := handler.NewPaymentHandler(paymentService, appLogger)
+	reportHandler := handler.NewReportHandler(reportService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	wishlistHandler := handler.NewWishlistHandler(wishlistService)
+	warehouseHandler := handler.NewWarehouseHandler(inventoryService)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateService)
+
+	// Background workers. Producers publish the events and notifications
+	// that the deliverers send, so they are stopped first.
+	producers := worker.NewGroup()
+	producers.Go("outbox-relay", outboxRelay.Run)
+	producers.Go("low-stock-monitor", lowStockMonitor.Run)
+	producers.Go("reservation-sweeper", reservationSweeper.Run)
+	producers.Go("wishlist-alert-sweeper", wishlistAlertSweeper.Run)
 
-	router := gin.Default()
+	deliverers := worker.NewGroup()
+	deliverers.Go("notifications", notifier.Run)
+	deliverers.Go("webhook-deliverer", webhookDeliverer.Run)
+
+	if !i18n.Supported(cfg.I18n.DefaultLocale) {
+		log.Fatalf("Unsupported i18n.default_locale %q", cfg.I18n.DefaultLocale)
+	}
 
+	if err := handler.RegisterValidators(); err != nil {
+		log.Fatalf("Validator registration failed: %v", err)
+	}
+
+	router := gin.New()
+	// Without trusted proxies the client IP is the connection's remote
+	// address, so rate limits and lockouts can't be dodged by spoofing
+	// X-Forwarded-For.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Trusted proxies configuration failed: %v", err)
+	}
+
+	router.Use(otelgin.Middleware(tracing.ServiceName))
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LocaleMiddleware(cfg.I18n.DefaultLocale))
 	router.Use(middleware.CORSMiddleware())
-	router.Use(middleware.LoggerMiddleware())
+	router.Use(middleware.LoggerMiddleware(appLogger))
+	router.Use(middleware.MetricsMiddleware())
+	// Inside the logger and metrics so that panics and errors are recorded
+	// with the status they were answered with.
+	router.Use(middleware.Recovery(appLogger))
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.TimeoutMiddleware(cfg.RequestTimeout))
 
-	route.SetupRoutes(router, userHandler, categoryHandler, productHandler, orderHandler, paymentHandler, cacheHandler)
+	route.SetupRoutes(router, userHandler, categoryHandler, productHandler, orderHandler, paymentHandler, cacheHandler, reportHandler, webhookHandler, reviewHandler, wishlistHandler, warehouseHandler, exchangeRateHandler, rateLimiter, cfg.RateLimit, idempotencyStore, cfg.Idempotency)
 
 	log.Printf("Starting HTTP server on port %s", cfg.HTTPPort)
 	log.Printf("Environment: %s", cfg.Environment)
 	log.Printf("Health check: http://localhost:%s/health", cfg.HTTPPort)
 	log.Printf("API Base URL: http://localhost:%s/api/v1", cfg.HTTPPort)
 
-	// Graceful shutdown
+	srv := &http.Server{
+		Addr:         ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  cfg.HTTPIdleTimeout,
+	}
+
 	go func() {
-		if err := router.Run(":" + cfg.HTTPPort); err != nil {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting new requests and drain in-flight ones first, so nothing
+	// is still using the database or redis when they are closed below.
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("HTTP server forced to shutdown: %v", err)
+	}
+
+	// Then stop whatever feeds the deliverers, and only then let the
+	// deliverers drain what was handed to them.
+	if err := producers.Stop(ctx); err != nil {
+		log.Printf("Background producers did not stop in time: %v", err)
+	}
+
+	if err := deliverers.Stop(ctx); err != nil {
+		log.Printf("Background deliverers did not stop in time: %v", err)
+	}
+
+	if err := database.Close(); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
+
+	if err := redis.Close(); err != nil {
+		log.Printf("Failed to close redis: %v", err)
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+
+	log.Println("Server exited")
 }
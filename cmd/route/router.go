@@ -3,10 +3,30 @@ package route
 import (
 	"github.com/affandisy/goshop/internal/handler"
 	"github.com/affandisy/goshop/internal/middleware"
+	"github.com/affandisy/goshop/pkg/config"
+	"github.com/affandisy/goshop/pkg/idempotency"
+	"github.com/affandisy/goshop/pkg/metrics"
+	"github.com/affandisy/goshop/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryHandler *handler.CategoryHandler, productHandler *handler.ProductHandler, orderHandler *handler.OrderHandler, paymentHandler *handler.PaymentHandler, cacheHandler *handler.CacheHandler) {
+func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryHandler *handler.CategoryHandler, productHandler *handler.ProductHandler, orderHandler *handler.OrderHandler, paymentHandler *handler.PaymentHandler, cacheHandler *handler.CacheHandler, reportHandler *handler.ReportHandler, webhookHandler *handler.WebhookHandler, reviewHandler *handler.ReviewHandler, wishlistHandler *handler.WishlistHandler, warehouseHandler *handler.WarehouseHandler, exchangeRateHandler *handler.ExchangeRateHandler, rateLimiter ratelimit.Limiter, rateLimits config.RateLimitConfig, idempotencyStore idempotency.Store, idempotencyCfg config.IdempotencyConfig) {
+	limit := func(name string, rule config.RateLimitRule, key middleware.RateLimitKeyFunc) gin.HandlerFunc {
+		return middleware.RateLimitMiddleware(rateLimiter, middleware.RateLimitPolicy{
+			Name:   name,
+			Limit:  rule.Limit,
+			Window: rule.Window,
+			Key:    key,
+		})
+	}
+	idempotent := func(name string) gin.HandlerFunc {
+		return middleware.IdempotencyMiddleware(idempotencyStore, middleware.IdempotencyPolicy{
+			Name:        name,
+			TTL:         idempotencyCfg.TTL,
+			LockTimeout: idempotencyCfg.LockTimeout,
+		})
+	}
+
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":   "OK",
@@ -16,6 +36,8 @@ func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryH
 		})
 	})
 
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
 	v1 := router.Group("/api/v1")
 	{
 		// Ping endpoint
@@ -23,25 +45,46 @@ func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryH
 			c.JSON(200, gin.H{"message": "pong"})
 		})
 
-		// Auth routes (public - tidak perlu auth)
+		// Auth routes (public - no auth needed)
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", userHandler.Register)
-			auth.POST("/login", userHandler.Login)
+			auth.POST("/register",
+				limit("register", rateLimits.Register, middleware.RateLimitByIP),
+				userHandler.Register)
+			auth.POST("/login",
+				limit("login", rateLimits.Login, middleware.RateLimitByIP),
+				limit("login_email", rateLimits.LoginPerEmail, middleware.RateLimitByEmail),
+				userHandler.Login)
+			auth.POST("/verify-email", userHandler.VerifyEmail)
+			auth.POST("/resend-verification",
+				limit("resend_verification", rateLimits.Register, middleware.RateLimitByIP),
+				userHandler.ResendVerification)
+			auth.POST("/forgot-password",
+				limit("forgot_password", rateLimits.Register, middleware.RateLimitByIP),
+				limit("forgot_password_email", rateLimits.LoginPerEmail, middleware.RateLimitByEmail),
+				userHandler.ForgotPassword)
+			auth.POST("/reset-password",
+				limit("reset_password", rateLimits.Login, middleware.RateLimitByIP),
+				userHandler.ResetPassword)
 		}
 
-		// User routes (protected - perlu auth)
+		// User routes (protected - need auth)
 		users := v1.Group("/users")
 		users.Use(middleware.AuthMiddleware())
 		{
 			users.GET("/profile", userHandler.GetProfile)
 			users.PUT("/profile", userHandler.UpdateProfile)
+			users.GET("/wishlist", wishlistHandler.GetWishlist)
+			users.POST("/wishlist", wishlistHandler.Add)
+			users.DELETE("/wishlist/:product_id", wishlistHandler.Remove)
 
 			// Admin only routes
 			users.GET("", middleware.AdminMiddleware(), userHandler.GetUsers)
+			users.GET("/login-attempts", middleware.AdminMiddleware(), userHandler.GetLoginAttempts)
+			users.POST("/:id/unlock", middleware.AdminMiddleware(), userHandler.UnlockUser)
 		}
 
-		// Category routes (public untuk read, admin untuk write)
+		// Category routes (public read, admin write)
 		categories := v1.Group("/categories")
 		{
 			categories.GET("", categoryHandler.GetAll)
@@ -52,13 +95,19 @@ func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryH
 			categories.POST("", categoryHandler.Create)
 			categories.PUT("/:id", categoryHandler.Update)
 			categories.DELETE("/:id", categoryHandler.Delete)
+			categories.GET("/:id/translations", categoryHandler.GetTranslations)
+			categories.PUT("/:id/translations/:locale", categoryHandler.SetTranslation)
+			categories.DELETE("/:id/translations/:locale", categoryHandler.DeleteTranslation)
 		}
 
-		// Product routes (public untuk read, admin untuk write)
+		// Product routes (public read, admin write)
 		products := v1.Group("/products")
 		{
 			products.GET("", productHandler.List)
 			products.GET("/:id", productHandler.GetByID)
+			products.GET("/:id/price-rules", productHandler.GetPriceRules)
+			products.GET("/:id/reviews", reviewHandler.GetProductReviews)
+			products.POST("/:id/reviews", middleware.AuthMiddleware(), reviewHandler.Create)
 
 			// Admin only
 			adminProducts := products.Group("")
@@ -68,32 +117,63 @@ func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryH
 				adminProducts.PUT("/:id", productHandler.Update)
 				adminProducts.DELETE("/:id", productHandler.Delete)
 				adminProducts.PATCH("/:id/stock", productHandler.UpdateStock)
+				adminProducts.GET("/low-stock", productHandler.GetLowStock)
+				adminProducts.GET("/:id/inventory", warehouseHandler.GetProductInventory)
+				adminProducts.POST("/:id/price-rules", productHandler.CreatePriceRule)
+				adminProducts.DELETE("/:id/price-rules/:rule_id", productHandler.DeletePriceRule)
+				adminProducts.GET("/:id/price-history", productHandler.GetPriceHistory)
+				adminProducts.GET("/:id/translations", productHandler.GetTranslations)
+				adminProducts.PUT("/:id/translations/:locale", productHandler.SetTranslation)
+				adminProducts.DELETE("/:id/translations/:locale", productHandler.DeleteTranslation)
 			}
 		}
 
+		// Review moderation routes (admin only)
+		reviews := v1.Group("/reviews")
+		reviews.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			reviews.GET("", reviewHandler.GetAll)
+			reviews.POST("/:id/approve", reviewHandler.Approve)
+			reviews.POST("/:id/hide", reviewHandler.Hide)
+		}
+
 		// Order routes (protected - need auth)
 		orders := v1.Group("/orders")
 		orders.Use(middleware.AuthMiddleware())
 		{
-			orders.POST("", orderHandler.CreateOrder)
+			orders.POST("", idempotent("create_order"), orderHandler.CreateOrder)
 			orders.GET("", orderHandler.GetMyOrders)
 			orders.GET("/:id", orderHandler.GetOrderByID)
 			orders.POST("/:id/cancel", orderHandler.CancelOrder)
+			orders.POST("/claim", orderHandler.ClaimGuestOrders)
 
 			// Admin only
 			orders.GET("/all", middleware.AdminMiddleware(), orderHandler.GetAllOrders)
 			orders.PATCH("/:id/status", middleware.AdminMiddleware(), orderHandler.UpdateOrderStatus)
 		}
 
+		// Guest checkout routes (public - access via order token)
+		guest := v1.Group("/guest")
+		{
+			guest.POST("/orders",
+				idempotent("create_guest_order"),
+				limit("guest_checkout", rateLimits.GuestCheckout, middleware.RateLimitByIP),
+				orderHandler.CreateGuestOrder)
+			guest.GET("/orders/:id", orderHandler.GetGuestOrder)
+			guest.POST("/payments", idempotent("create_guest_payment"), paymentHandler.CreateGuestPayment)
+		}
+
 		// Payment routes
 		payments := v1.Group("/payments")
 		{
 			// Public webhook endpoint (no auth - Midtrans callback)
-			payments.POST("/notification", paymentHandler.HandleNotification)
+			payments.POST("/notification",
+				limit("payment_notification", rateLimits.PaymentNotification, middleware.RateLimitByIP),
+				paymentHandler.HandleNotification)
 
 			// Protected routes
 			payments.Use(middleware.AuthMiddleware())
-			payments.POST("", paymentHandler.CreatePayment)
+			payments.POST("", idempotent("create_payment"), paymentHandler.CreatePayment)
 			payments.GET("/:id", paymentHandler.GetPaymentByID)
 			payments.GET("/order/:order_id", paymentHandler.GetPaymentByOrderID)
 
@@ -110,5 +190,54 @@ func SetupRoutes(router *gin.Engine, userHandler *handler.UserHandler, categoryH
 			cacheRoutes.DELETE("/categories", cacheHandler.ClearCategoriesCache)
 			cacheRoutes.DELETE("/all", cacheHandler.ClearAllCache)
 		}
+
+		// Report routes (admin only)
+		reports := v1.Group("/reports")
+		reports.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			reports.GET("", reportHandler.GetReportTypes)
+			reports.GET("/users", reportHandler.GenerateUsersReport)
+			reports.GET("/products", reportHandler.GenerateProductsReport)
+			reports.GET("/orders", reportHandler.GenerateOrdersReport)
+			reports.GET("/low-stock", reportHandler.GenerateLowStockReport)
+		}
+
+		// Warehouse routes (admin only)
+		warehouses := v1.Group("/warehouses")
+		warehouses.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			warehouses.GET("", warehouseHandler.GetAll)
+			warehouses.POST("", warehouseHandler.Create)
+			warehouses.GET("/transfers", warehouseHandler.GetTransfers)
+			warehouses.POST("/transfers", warehouseHandler.Transfer)
+			warehouses.GET("/:id", warehouseHandler.GetByID)
+			warehouses.PUT("/:id", warehouseHandler.Update)
+			warehouses.DELETE("/:id", warehouseHandler.Delete)
+			warehouses.GET("/:id/inventory", warehouseHandler.GetInventory)
+			warehouses.PATCH("/:id/stock", warehouseHandler.AdjustStock)
+		}
+
+		// Exchange rate routes (admin only)
+		exchangeRates := v1.Group("/exchange-rates")
+		exchangeRates.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			exchangeRates.GET("", exchangeRateHandler.GetAll)
+			exchangeRates.POST("", exchangeRateHandler.Create)
+			exchangeRates.DELETE("/:id", exchangeRateHandler.Delete)
+		}
+
+		// Merchant webhook routes (admin only)
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			webhooks.GET("", webhookHandler.GetAll)
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("/:id", webhookHandler.GetByID)
+			webhooks.PUT("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+			webhooks.POST("/:id/rotate-secret", webhookHandler.RotateSecret)
+			webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
 	}
 }
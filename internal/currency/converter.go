@@ -0,0 +1,59 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// Converter shows prices, which are kept in the base currency, in the
+// currencies customers shop in.
+type Converter interface {
+	// Rate returns the exchange rate in effect now for currency. The base
+	// currency has a rate of 1.
+	Rate(ctx context.Context, currency domain.Currency) (domain.ExchangeRate, error)
+	// Convert turns a base currency amount into the rate's currency,
+	// rounded with the configured policy.
+	Convert(amount domain.Money, rate domain.ExchangeRate) (domain.Money, error)
+}
+
+type converter struct {
+	rateRepo repository.ExchangeRateRepository
+	policy   domain.RoundingPolicy
+}
+
+func NewConverter(rateRepo repository.ExchangeRateRepository, policy domain.RoundingPolicy) Converter {
+	return &converter{rateRepo: rateRepo, policy: policy}
+}
+
+func (c *converter) Rate(ctx context.Context, currency domain.Currency) (domain.ExchangeRate, error) {
+	if !currency.Valid() {
+		return domain.ExchangeRate{}, fmt.Errorf("%w: %q", domain.ErrUnsupportedCurrency, currency)
+	}
+
+	if currency == domain.BaseCurrency {
+		return domain.BaseRate(), nil
+	}
+
+	rate, err := c.rateRepo.GetEffective(ctx, currency, time.Now())
+	if errors.Is(err, domain.ErrExchangeRateNotFound) {
+		return domain.ExchangeRate{}, fmt.Errorf("%w: %s", domain.ErrNoExchangeRate, currency)
+	}
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	// Rates stored before they were validated may have rounded to zero.
+	if rate.Rate <= 0 {
+		return domain.ExchangeRate{}, fmt.Errorf("%w: %s %v", domain.ErrInvalidExchangeRate, currency, rate.Rate)
+	}
+
+	return *rate, nil
+}
+
+func (c *converter) Convert(amount domain.Money, rate domain.ExchangeRate) (domain.Money, error) {
+	return rate.Convert(amount, c.policy)
+}
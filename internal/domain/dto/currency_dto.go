@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type ExchangeRateRequest struct {
+	Currency      string     `json:"currency" binding:"required,len=3"`
+	Rate          float64    `json:"rate" binding:"required,gte=0.000001"` // price of one unit in the base currency, to 6 decimals
+	EffectiveFrom *time.Time `json:"effective_from"`                       // defaults to now
+}
+
+type ExchangeRateQuery struct {
+	Currency string `form:"currency"`
+	Page     int    `form:"page,default=1"`
+	Limit    int    `form:"limit,default=10"`
+}
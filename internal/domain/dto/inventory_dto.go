@@ -0,0 +1,32 @@
+package dto
+
+type WarehouseRequest struct {
+	Code     string `json:"code" binding:"required,max=20"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Address  string `json:"address" binding:"max=255"`
+	City     string `json:"city" binding:"max=100"`
+	Province string `json:"province" binding:"max=100"`
+	Priority int    `json:"priority"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type AdjustInventoryRequest struct {
+	ProductID string `json:"product_id" binding:"required,uuid"`
+	Quantity  int    `json:"quantity" binding:"required"` // change, negative to remove
+	Note      string `json:"note" binding:"max=255"`
+}
+
+type StockTransferRequest struct {
+	ProductID       string `json:"product_id" binding:"required,uuid"`
+	FromWarehouseID string `json:"from_warehouse_id" binding:"required,uuid"`
+	ToWarehouseID   string `json:"to_warehouse_id" binding:"required,uuid"`
+	Quantity        int    `json:"quantity" binding:"required,gt=0"`
+	Note            string `json:"note" binding:"max=255"`
+}
+
+type StockTransferQuery struct {
+	ProductID   string `form:"product_id"`
+	WarehouseID string `form:"warehouse_id"` // either side of the transfer
+	Page        int    `form:"page,default=1"`
+	Limit       int    `form:"limit,default=10"`
+}
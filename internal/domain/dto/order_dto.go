@@ -3,12 +3,62 @@ package dto
 import "github.com/affandisy/goshop/internal/domain"
 
 type CreateOrderRequest struct {
-	Items []OrderItemRequest `json:"items" binding:"required,min=1"`
-	Notes string             `json:"notes"`
+	Items           []OrderItemRequest      `json:"items" binding:"required,min=1,dive"`
+	Notes           string                  `json:"notes"`
+	ShippingAddress *ShippingAddressRequest `json:"shipping_address"`
+	Currency        string                  `json:"currency" binding:"omitempty,len=3"` // display currency, base by default
+}
+
+type CreateGuestOrderRequest struct {
+	Items           []OrderItemRequest     `json:"items" binding:"required,min=1,dive"`
+	Notes           string                 `json:"notes"`
+	Contact         GuestContactRequest    `json:"contact" binding:"required"`
+	ShippingAddress ShippingAddressRequest `json:"shipping_address" binding:"required"`
+	Currency        string                 `json:"currency" binding:"omitempty,len=3"` // display currency, base by default
+}
+
+type GuestContactRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Email string `json:"email" binding:"required,email,max=100"`
+	Phone string `json:"phone" binding:"required,id_phone"`
+}
+
+type ShippingAddressRequest struct {
+	RecipientName string `json:"recipient_name" binding:"required,max=100"`
+	Phone         string `json:"phone" binding:"required,id_phone"`
+	Line1         string `json:"line1" binding:"required,max=255"`
+	Line2         string `json:"line2" binding:"max=255"`
+	City          string `json:"city" binding:"required,max=100"`
+	Province      string `json:"province" binding:"required,max=100"`
+	PostalCode    string `json:"postal_code" binding:"required,max=10"`
+}
+
+func (r ShippingAddressRequest) ToDomain() domain.ShippingAddress {
+	return domain.ShippingAddress{
+		RecipientName: r.RecipientName,
+		Phone:         r.Phone,
+		Line1:         r.Line1,
+		Line2:         r.Line2,
+		City:          r.City,
+		Province:      r.Province,
+		PostalCode:    r.PostalCode,
+	}
+}
+
+// GuestOrderResponse is returned once, at checkout. The access token is
+// also emailed and is needed to view or pay for the order later.
+type GuestOrderResponse struct {
+	Order       *domain.Order `json:"order"`
+	AccessToken string        `json:"access_token"`
+	TrackingURL string        `json:"tracking_url"`
+}
+
+type ClaimGuestOrdersResponse struct {
+	Claimed int64 `json:"claimed"`
 }
 
 type OrderItemRequest struct {
-	ProductID string `json:"product_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required,uuid"`
 	Quantity  int    `json:"quantity" binding:"required,gt=0"`
 }
 
@@ -7,7 +7,7 @@ import (
 )
 
 type CreatePaymentRequest struct {
-	OrderID       string               `json:"order_id" binding:"required"`
+	OrderID       string               `json:"order_id" binding:"required,uuid"`
 	PaymentMethod domain.PaymentMethod `json:"payment_method"`
 }
 
@@ -30,7 +30,7 @@ type PaymentResponse struct {
 	ID                string               `json:"id"`
 	OrderID           string               `json:"order_id"`
 	OrderNumber       string               `json:"order_number"`
-	Amount            float64              `json:"amount"`
+	Amount            domain.Money         `json:"amount"`
 	PaymentMethod     domain.PaymentMethod `json:"payment_method"`
 	Status            domain.PaymentStatus `json:"status"`
 	MidtransSnapToken string               `json:"snap_token,omitempty"`
@@ -1,21 +1,41 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
+
 type ProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int     `json:"stock" binding:"required,gte=0"`
-	SKU         string  `json:"sku" binding:"required"`
-	CategoryID  string  `json:"category_id"`
-	ImageURL    string  `json:"image_url"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description"`
+	Price       domain.Money `json:"price" binding:"required,gt=0"`
+	Stock       int          `json:"stock" binding:"required,gte=0"`
+	SKU         string       `json:"sku" binding:"required,max=100,sku"`
+	CategoryID  string       `json:"category_id" binding:"omitempty,uuid"`
+	ImageURL    string       `json:"image_url"`
+
+	ReorderLevel    int `json:"reorder_level" binding:"gte=0"`
+	ReorderQuantity int `json:"reorder_quantity" binding:"gte=0"`
 }
 
 type ProductQuery struct {
-	Name       string  `form:"name"`
-	CategoryID string  `form:"category_id"`
-	MinPrice   float64 `form:"min_price"`
-	MaxPrice   float64 `form:"max_price"`
-	IsActive   *bool   `form:"is_active"`
-	Page       int     `form:"page,default=1"`
-	Limit      int     `form:"limit,default=10"`
+	Name       string       `form:"name"`
+	CategoryID string       `form:"category_id"`
+	MinPrice   domain.Money `form:"min_price"`
+	MaxPrice   domain.Money `form:"max_price"`
+	IsActive   *bool        `form:"is_active"`
+	Sort       string       `form:"sort"`     // "rating" for best rated first
+	Currency   string       `form:"currency"` // display prices in this currency
+	Page       int          `form:"page,default=1"`
+	Limit      int          `form:"limit,default=10"`
+}
+
+type PriceRuleRequest struct {
+	Name        string       `json:"name" binding:"max=100"`
+	Type        string       `json:"type" binding:"required,oneof=sale tier"`
+	Price       domain.Money `json:"price" binding:"required,gt=0"`
+	MinQuantity int          `json:"min_quantity" binding:"gte=0"` // tiers only, at least 2
+	StartsAt    *time.Time   `json:"starts_at"`                    // required for sales
+	EndsAt      *time.Time   `json:"ends_at"`                      // required for sales
 }
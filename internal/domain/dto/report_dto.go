@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+const dateLayout = "2006-01-02"
+
+type ReportQuery struct {
+	Format string `form:"format,default=pdf" binding:"oneof=pdf excel"`
+}
+
+type OrdersReportQuery struct {
+	Format    string `form:"format,default=pdf" binding:"oneof=pdf excel"`
+	StartDate string `form:"start_date" binding:"omitempty,datetime=2006-01-02"` // default a month before now
+	EndDate   string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`   // default today
+}
+
+// Period returns the report's start and end dates. The dates have already
+// been validated by binding.
+func (q OrdersReportQuery) Period(now time.Time) (time.Time, time.Time) {
+	start := now.AddDate(0, -1, 0).Format(dateLayout)
+	if q.StartDate != "" {
+		start = q.StartDate
+	}
+	end := now.Format(dateLayout)
+	if q.EndDate != "" {
+		end = q.EndDate
+	}
+
+	startDate, _ := time.Parse(dateLayout, start)
+	endDate, _ := time.Parse(dateLayout, end)
+	return startDate, endDate
+}
@@ -0,0 +1,15 @@
+package dto
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Title  string `json:"title" binding:"required,max=150"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+type ReviewQuery struct {
+	ProductID string `form:"product_id"`
+	UserID    string `form:"user_id"`
+	Status    string `form:"status"`
+	Page      int    `form:"page,default=1"`
+	Limit     int    `form:"limit,default=10"`
+}
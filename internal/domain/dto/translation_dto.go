@@ -0,0 +1,6 @@
+package dto
+
+type TranslationRequest struct {
+	Name        string `json:"name" binding:"required,max=200"`
+	Description string `json:"description"`
+}
@@ -1,12 +1,16 @@
 package dto
 
-import "github.com/affandisy/goshop/internal/domain"
+import (
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
 
 type UserRegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Name     string `json:"name" binding:"required"`
-	Phone    string `json:"phone"`
+	Phone    string `json:"phone" binding:"omitempty,id_phone"`
 }
 
 type UserLoginRequest struct {
@@ -14,6 +18,28 @@ type UserLoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type LoginAttemptQuery struct {
+	UserID string `form:"user_id"`
+	Email  string `form:"email"`
+	IP     string `form:"ip"`
+	Result string `form:"result"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=10"`
+}
+
 type UserResponse struct {
 	ID       string `json:"id"`
 	Email    string `json:"email"`
@@ -21,6 +47,9 @@ type UserResponse struct {
 	Phone    string `json:"phone"`
 	Role     string `json:"role"`
 	IsActive bool   `json:"is_active"`
+
+	EmailVerified bool       `json:"email_verified"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
 }
 
 func UserMapToResponse(u *domain.User) UserResponse {
@@ -31,5 +60,8 @@ func UserMapToResponse(u *domain.User) UserResponse {
 		Phone:    u.Phone,
 		Role:     u.Role,
 		IsActive: u.IsActive,
+
+		EmailVerified: u.IsEmailVerified(),
+		LockedUntil:   u.LockedUntil,
 	}
 }
@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/affandisy/goshop/internal/domain"
+
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events"` // empty subscribes to every event
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookEndpointSecretResponse is returned when an endpoint is created or
+// its secret rotated; it is the only time the secret is shown.
+type WebhookEndpointSecretResponse struct {
+	*domain.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryQuery struct {
+	Status string `form:"status"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=10"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type WishlistRequest struct {
+	ProductID string `json:"product_id" binding:"required,uuid"`
+}
@@ -1,37 +1,114 @@
 package domain
 
-import "errors"
+// ErrorKind says what sort of failure an Error is, which decides how it is
+// reported to API clients.
+type ErrorKind int
+
+const (
+	KindInternal     ErrorKind = iota // unexpected failure
+	KindInvalid                       // the request is invalid
+	KindUnauthorized                  // the caller is not authenticated
+	KindForbidden                     // the caller may not do this
+	KindNotFound                      // the resource does not exist
+	KindConflict                      // the resource's state does not allow this
+	KindRateLimited                   // the caller must wait before trying again
+)
+
+// Error is a domain error with a stable code for API clients, such as
+// "product_not_found". Services may wrap it with more detail; errors.Is and
+// errors.As still find it.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
 
 var (
 	// User errors
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUnauthorized       = errors.New("unauthorized")
+	ErrUserNotFound       = newError(KindNotFound, "user_not_found", "user not found")
+	ErrUserAlreadyExists  = newError(KindConflict, "user_already_exists", "user already exists")
+	ErrInvalidCredentials = newError(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrUnauthorized       = newError(KindUnauthorized, "unauthorized", "unauthorized")
+	ErrAccountLocked      = newError(KindRateLimited, "account_locked", "account temporarily locked")
+	ErrTooManyAttempts    = newError(KindRateLimited, "too_many_attempts", "too many failed login attempts")
+	ErrInvalidToken       = newError(KindInvalid, "invalid_token", "invalid or expired token")
+	ErrInvalidOrderToken  = newError(KindUnauthorized, "invalid_order_token", "invalid or expired order access token")
+	ErrEmailNotVerified   = newError(KindForbidden, "email_not_verified", "email not verified")
 
 	// Product errors
-	ErrProductNotFound     = errors.New("product not found")
-	ErrProductNotAvailable = errors.New("product not available")
-	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrProductNotFound     = newError(KindNotFound, "product_not_found", "product not found")
+	ErrProductNotAvailable = newError(KindConflict, "product_not_available", "product not available")
+	ErrInsufficientStock   = newError(KindConflict, "insufficient_stock", "insufficient stock")
+	ErrSKUAlreadyExists    = newError(KindConflict, "sku_already_exists", "sku already exists")
+	ErrPriceRuleNotFound   = newError(KindNotFound, "price_rule_not_found", "price rule not found")
+	ErrInvalidPriceRule    = newError(KindInvalid, "invalid_price_rule", "invalid price rule")
 
 	// Category errors
-	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryNotFound = newError(KindNotFound, "category_not_found", "category not found")
 
 	// Order errors
-	ErrOrderNotFound      = errors.New("order not found")
-	ErrInvalidOrderStatus = errors.New("invalid order status")
-	ErrCannotCancelOrder  = errors.New("cannot cancel order")
-	ErrEmptyCart          = errors.New("cart is empty")
+	ErrOrderNotFound      = newError(KindNotFound, "order_not_found", "order not found")
+	ErrInvalidOrderStatus = newError(KindInvalid, "invalid_order_status", "invalid order status")
+	ErrCannotCancelOrder  = newError(KindConflict, "cannot_cancel_order", "cannot cancel order")
+	ErrInvalidTransition  = newError(KindConflict, "invalid_status_transition", "order cannot move to that status")
+	ErrEmptyCart          = newError(KindInvalid, "empty_cart", "cart is empty")
 
 	// Payment errors
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrPaymentAlreadyExists = errors.New("payment already exists for this order")
-	ErrPaymentFailed        = errors.New("payment failed")
-	ErrInvalidPaymentStatus = errors.New("invalid payment status")
-	ErrOrderAlreadyPaid     = errors.New("order already paid")
+	ErrPaymentNotFound      = newError(KindNotFound, "payment_not_found", "payment not found")
+	ErrPaymentAlreadyExists = newError(KindConflict, "payment_already_exists", "payment already exists for this order")
+	ErrPaymentFailed        = newError(KindInternal, "payment_failed", "payment failed")
+	ErrInvalidPaymentStatus = newError(KindInvalid, "invalid_payment_status", "invalid payment status")
+	ErrOrderAlreadyPaid     = newError(KindConflict, "order_already_paid", "order already paid")
+	ErrAmountMismatch       = newError(KindInvalid, "amount_mismatch", "gross amount does not match the payment")
+
+	// Money errors
+	ErrInvalidAmount        = newError(KindInvalid, "invalid_amount", "invalid amount")
+	ErrUnsupportedCurrency  = newError(KindInvalid, "unsupported_currency", "unsupported currency")
+	ErrCurrencyMismatch     = newError(KindInvalid, "currency_mismatch", "amounts are in different currencies")
+	ErrExchangeRateNotFound = newError(KindNotFound, "exchange_rate_not_found", "exchange rate not found")
+	ErrNoExchangeRate       = newError(KindInvalid, "no_exchange_rate", "no exchange rate for the currency")
+	ErrInvalidExchangeRate  = newError(KindInvalid, "invalid_exchange_rate", "invalid exchange rate")
+
+	// Warehouse errors
+	ErrWarehouseNotFound      = newError(KindNotFound, "warehouse_not_found", "warehouse not found")
+	ErrWarehouseAlreadyExists = newError(KindConflict, "warehouse_already_exists", "warehouse code already exists")
+	ErrWarehouseNotEmpty      = newError(KindConflict, "warehouse_not_empty", "warehouse still holds stock")
+	ErrNoActiveWarehouse      = newError(KindConflict, "no_active_warehouse", "no active warehouse")
+	ErrInvalidTransfer        = newError(KindInvalid, "invalid_transfer", "source and destination warehouse must differ")
+
+	// Wishlist errors
+	ErrWishlistItemNotFound = newError(KindNotFound, "wishlist_item_not_found", "product is not in the wishlist")
+
+	// Review errors
+	ErrReviewNotFound      = newError(KindNotFound, "review_not_found", "review not found")
+	ErrReviewAlreadyExists = newError(KindConflict, "review_already_exists", "you have already reviewed this product")
+	ErrReviewNotAllowed    = newError(KindForbidden, "review_not_allowed", "only buyers with a delivered order can review this product")
+
+	// Webhook errors
+	ErrWebhookEndpointNotFound = newError(KindNotFound, "webhook_endpoint_not_found", "webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = newError(KindNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+	ErrInvalidWebhookEvent     = newError(KindInvalid, "invalid_webhook_event", "unknown webhook event type")
+	ErrInvalidWebhookURL       = newError(KindInvalid, "invalid_webhook_url", "webhook url must be an absolute http or https url")
+
+	// Translation errors
+	ErrTranslationNotFound = newError(KindNotFound, "translation_not_found", "translation not found")
+	ErrUnsupportedLocale   = newError(KindInvalid, "unsupported_locale", "unsupported locale")
+
+	// Idempotency errors
+	ErrInvalidIdempotencyKey = newError(KindInvalid, "invalid_idempotency_key", "idempotency key must be 1 to 255 printable ASCII characters")
+	ErrIdempotencyKeyReused  = newError(KindConflict, "idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrIdempotencyKeyInUse   = newError(KindConflict, "idempotency_key_in_use", "a request with this idempotency key is still in progress")
 
 	// General errors
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrInternalServer = errors.New("internal server error")
-	ErrForbidden      = errors.New("forbidden")
+	ErrInvalidInput   = newError(KindInvalid, "invalid_input", "invalid input")
+	ErrInternalServer = newError(KindInternal, "internal_error", "internal server error")
+	ErrForbidden      = newError(KindForbidden, "forbidden", "forbidden")
 )
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of Currency in the base currency,
+// e.g. 11680.5 for SGD. It applies from EffectiveFrom until the next rate
+// for the currency takes effect.
+type ExchangeRate struct {
+	BaseModel
+	Currency      Currency  `gorm:"type:varchar(3);not null;index:idx_exchange_rates_currency_from" json:"currency"`
+	Rate          float64   `gorm:"type:decimal(18,6);not null" json:"rate"`
+	EffectiveFrom time.Time `gorm:"not null;index:idx_exchange_rates_currency_from" json:"effective_from"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// BaseRate is the rate for prices shown in the base currency.
+func BaseRate() ExchangeRate {
+	return ExchangeRate{Currency: BaseCurrency, Rate: 1}
+}
+
+// Convert turns an amount in the base currency into the rate's currency,
+// rounded as the policy says. It fails with ErrInvalidExchangeRate unless
+// the rate is positive.
+func (r ExchangeRate) Convert(amount Money, policy RoundingPolicy) (Money, error) {
+	if r.Currency == amount.Code() {
+		return amount, nil
+	}
+
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(r.Rate, 'f', -1, 64))
+	if !ok || rate.Sign() <= 0 {
+		return Money{}, fmt.Errorf("%w: %s %v", ErrInvalidExchangeRate, r.Currency, r.Rate)
+	}
+
+	// minor units of r.Currency = amount / 10^base digits / rate * 10^digits
+	x := new(big.Rat).SetInt64(amount.Amount)
+	x.Mul(x, new(big.Rat).SetInt(pow10(r.Currency.Digits())))
+	x.Quo(x, new(big.Rat).SetInt(pow10(amount.Code().Digits())))
+	x.Quo(x, rate)
+
+	return Money{Amount: policy.round(x, r.Currency), Currency: r.Currency}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // to the nearest step, halves away from zero
+	RoundHalfEven RoundingMode = "half_even" // to the nearest step, halves to the even step
+	RoundUp       RoundingMode = "up"        // away from zero
+	RoundDown     RoundingMode = "down"      // towards zero
+)
+
+// RoundingPolicy rounds converted prices. Increments are the smallest step
+// per currency in minor units, e.g. 5 for MYR rounded to 5 sen; the default
+// is 1.
+type RoundingPolicy struct {
+	Mode       RoundingMode
+	Increments map[Currency]int64
+}
+
+func (p RoundingPolicy) Validate() error {
+	switch p.Mode {
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+	default:
+		return fmt.Errorf("unknown rounding mode %q", p.Mode)
+	}
+
+	for currency, increment := range p.Increments {
+		if !currency.Valid() {
+			return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+		}
+		if increment <= 0 {
+			return fmt.Errorf("rounding increment for %s must be positive", currency)
+		}
+	}
+
+	return nil
+}
+
+func (p RoundingPolicy) round(x *big.Rat, currency Currency) int64 {
+	increment := p.Increments[currency]
+	if increment <= 0 {
+		increment = 1
+	}
+
+	steps := new(big.Rat).Quo(x, new(big.Rat).SetInt64(increment))
+	q, r := new(big.Int).QuoRem(steps.Num(), steps.Denom(), new(big.Int))
+	if r.Sign() != 0 {
+		away := false
+		switch p.Mode {
+		case RoundUp:
+			away = true
+		case RoundHalfUp, RoundHalfEven:
+			// Compare the remainder with half the denominator.
+			cmp := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(steps.Denom())
+			away = cmp > 0 || (cmp == 0 && (p.Mode == RoundHalfUp || q.Bit(0) == 1))
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(steps.Sign())))
+		}
+	}
+
+	return q.Int64() * increment
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExchangeRateConvert(t *testing.T) {
+	rate := ExchangeRate{Currency: CurrencyUSD, Rate: 16000}
+
+	got, err := rate.Convert(IDR(24000), RoundingPolicy{Mode: RoundHalfUp})
+	if err != nil || !got.Equal(NewMoney(150, CurrencyUSD)) {
+		t.Errorf("Convert = %s, %v; want 1.50 USD", got, err)
+	}
+
+	for _, bad := range []float64{0, -1} {
+		rate.Rate = bad
+		if _, err := rate.Convert(IDR(24000), RoundingPolicy{Mode: RoundHalfUp}); !errors.Is(err, ErrInvalidExchangeRate) {
+			t.Errorf("Convert at rate %v = %v, want %v", bad, err, ErrInvalidExchangeRate)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+type LoginAttemptResult string
+
+const (
+	LoginAttemptSuccess            LoginAttemptResult = "success"
+	LoginAttemptInvalidCredentials LoginAttemptResult = "invalid_credentials"
+	LoginAttemptLocked             LoginAttemptResult = "locked"
+	LoginAttemptInactive           LoginAttemptResult = "inactive"
+	LoginAttemptIPBlocked          LoginAttemptResult = "ip_blocked"
+)
+
+// LoginAttempt is an audit record of a single call to the login endpoint.
+type LoginAttempt struct {
+	BaseModel
+	UserID    *string            `gorm:"type:uuid;index" json:"user_id,omitempty"` // nil when the email is unknown
+	Email     string             `gorm:"type:varchar(100);index;not null" json:"email"`
+	IPAddress string             `gorm:"type:varchar(45);index;not null" json:"ip_address"`
+	UserAgent string             `gorm:"type:varchar(500)" json:"user_agent"`
+	Result    LoginAttemptResult `gorm:"type:varchar(30);index;not null" json:"result"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+func (a *LoginAttempt) IsFailure() bool {
+	return a.Result != LoginAttemptSuccess
+}
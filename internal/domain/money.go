@@ -0,0 +1,285 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	CurrencyIDR Currency = "IDR"
+	CurrencyUSD Currency = "USD"
+	CurrencyEUR Currency = "EUR"
+	CurrencySGD Currency = "SGD"
+	CurrencyMYR Currency = "MYR"
+	CurrencyJPY Currency = "JPY"
+)
+
+// BaseCurrency is the currency prices are stored and charged in.
+const BaseCurrency = CurrencyIDR
+
+// minorUnitDigits is the number of decimal places in each currency's minor
+// unit. Rupiah are charged in whole units, as Midtrans rejects fractions.
+var minorUnitDigits = map[Currency]int{
+	CurrencyIDR: 0,
+	CurrencyUSD: 2,
+	CurrencyEUR: 2,
+	CurrencySGD: 2,
+	CurrencyMYR: 2,
+	CurrencyJPY: 0,
+}
+
+// Valid reports whether the currency is supported.
+func (c Currency) Valid() bool {
+	_, ok := minorUnitDigits[c]
+	return ok
+}
+
+// Digits is the number of decimal places in the currency's minor unit.
+func (c Currency) Digits() int {
+	return minorUnitDigits[c]
+}
+
+// Money is an amount in the minor unit of its currency. The zero Currency
+// is the base currency.
+//
+// Money is stored as a bigint column holding the amount in the base
+// currency. In JSON it is {"amount": <minor units>, "currency": "IDR"};
+// a bare number is also accepted and read as a decimal amount of the base
+// currency.
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// NewMoney returns amount minor units of currency.
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// IDR returns an amount of rupiah.
+func IDR(amount int64) Money {
+	return Money{Amount: amount, Currency: CurrencyIDR}
+}
+
+// ParseMoney reads a decimal amount such as "12.50" in currency. It fails
+// rather than round when the amount has more decimal places than the
+// currency's minor unit.
+func ParseMoney(s string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+
+	digits := currency.Digits()
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > digits {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, s, digits)
+	}
+
+	amount, err := strconv.ParseInt(whole+frac+strings.Repeat("0", digits-len(frac)), 10, 64)
+	if err != nil || whole == "" || strings.ContainsAny(frac, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Code is the money's currency.
+func (m Money) Code() Currency {
+	if m.Currency == "" {
+		return BaseCurrency
+	}
+	return m.Currency
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Code() != o.Code() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Code(), o.Code())
+	}
+	return nil
+}
+
+// Add fails with ErrCurrencyMismatch unless both amounts are in the same
+// currency, as do Sub and LessThan.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Code()}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Code()}, nil
+}
+
+// Mul returns the amount for quantity units.
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Code()}
+}
+
+// Div splits the amount n ways, rounding half away from zero.
+func (m Money) Div(n int) Money {
+	d := int64(n)
+	q, r := m.Amount/d, m.Amount%d
+	if r != 0 && 2*abs(r) >= abs(d) {
+		if (m.Amount < 0) != (d < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money{Amount: q, Currency: m.Code()}
+}
+
+// Equal reports whether both amounts are the same in the same currency.
+func (m Money) Equal(o Money) bool {
+	return m.Code() == o.Code() && m.Amount == o.Amount
+}
+
+func (m Money) LessThan(o Money) (bool, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return false, err
+	}
+	return m.Amount < o.Amount, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Float64 is the amount in major units, for display such as spreadsheet
+// cells. Calculations should stay in Money.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return f
+}
+
+// Decimal formats the amount in major units, e.g. "12.50".
+func (m Money) Decimal() string {
+	digits := m.Code().Digits()
+	s := strconv.FormatInt(m.Amount, 10)
+	if digits == 0 {
+		return s
+	}
+
+	sign := ""
+	if m.Amount < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Code())
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type moneyJSON struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Amount, Currency: m.Code()})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '{' {
+		parsed, err := ParseMoney(strings.Trim(string(data), `"`), BaseCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Currency == "" {
+		v.Currency = BaseCurrency
+	}
+	if !v.Currency.Valid() {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, v.Currency)
+	}
+
+	*m = Money{Amount: v.Amount, Currency: v.Currency}
+	return nil
+}
+
+// UnmarshalParam reads query and form values as decimal amounts of the
+// base currency.
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := ParseMoney(param, BaseCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+func (m Money) Value() (driver.Value, error) {
+	if m.Code() != BaseCurrency {
+		return nil, fmt.Errorf("domain: cannot store a %s amount", m.Code())
+	}
+	return m.Amount, nil
+}
+
+func (m *Money) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Money{Currency: BaseCurrency}
+	case int64:
+		*m = Money{Amount: v, Currency: BaseCurrency}
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return errors.New("domain: unsupported money value")
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	parsed, err := ParseMoney(s, BaseCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, b := IDR(1500), Money{Amount: 500}
+
+	if got, err := a.Add(b); err != nil || !got.Equal(IDR(2000)) {
+		t.Errorf("Add = %s, %v; want 2000 IDR", got, err)
+	}
+	if got, err := a.Sub(b); err != nil || !got.Equal(IDR(1000)) {
+		t.Errorf("Sub = %s, %v; want 1000 IDR", got, err)
+	}
+	if less, err := b.LessThan(a); err != nil || !less {
+		t.Errorf("LessThan = %t, %v; want true", less, err)
+	}
+}
+
+func TestMoneyCurrencyMismatch(t *testing.T) {
+	idr, usd := IDR(1500), NewMoney(150, CurrencyUSD)
+
+	tests := []struct {
+		name string
+		op   func() error
+	}{
+		{"Add", func() error { _, err := idr.Add(usd); return err }},
+		{"Sub", func() error { _, err := idr.Sub(usd); return err }},
+		{"LessThan", func() error { _, err := idr.LessThan(usd); return err }},
+		{"ResolvePrice", func() error {
+			_, _, err := ResolvePrice(idr, []PriceRule{{Price: usd}}, 1, time.Now())
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.op(); !errors.Is(err, ErrCurrencyMismatch) {
+				t.Errorf("%s = %v, want %v", tt.name, err, ErrCurrencyMismatch)
+			}
+		})
+	}
+}
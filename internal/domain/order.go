@@ -16,26 +16,85 @@ const (
 type Order struct {
 	BaseModel
 	OrderNumber string      `gorm:"type:varchar(50);uniqueIndex;not null" json:"order_number"`
-	UserID      string      `gorm:"type:uuid;not null" json:"user_id"`
-	TotalAmount float64     `gorm:"type:decimal(10,2);not null" json:"total_amount"`
+	UserID      *string     `gorm:"type:uuid;index" json:"user_id"` // nil for guest orders
+	TotalAmount Money       `gorm:"type:bigint;not null" json:"total_amount"`
 	Status      OrderStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	Notes       string      `gorm:"type:text" json:"notes"`
 	PaidAt      *time.Time  `json:"paid_at,omitempty"`
+	// ReservedUntil is when the stock held for an unpaid order is
+	// released and the order cancelled.
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
 
-	User       *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	OrderItems []OrderItem `gorm:"foreignKey:OrderID" json:"order_items,omitempty"`
+	// The currency the customer shopped in and its rate at checkout.
+	// Amounts are kept and charged in the base currency; DisplayTotal is
+	// TotalAmount at that rate.
+	Currency     Currency `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`
+	ExchangeRate float64  `gorm:"type:decimal(18,6);not null;default:1" json:"exchange_rate"`
+	DisplayTotal *Money   `gorm:"-" json:"display_total,omitempty"`
+
+	// Contact details captured at checkout. Guests have no User to fall
+	// back on, so these are the only way to reach them.
+	CustomerName    string          `gorm:"type:varchar(100)" json:"customer_name"`
+	CustomerEmail   string          `gorm:"type:varchar(100);index" json:"customer_email"`
+	CustomerPhone   string          `gorm:"type:varchar(20)" json:"customer_phone"`
+	ShippingAddress ShippingAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
+
+	User        *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	OrderItems  []OrderItem       `gorm:"foreignKey:OrderID" json:"order_items,omitempty"`
+	Allocations []OrderAllocation `gorm:"foreignKey:OrderID" json:"allocations,omitempty"`
+}
+
+type ShippingAddress struct {
+	RecipientName string `gorm:"type:varchar(100)" json:"recipient_name"`
+	Phone         string `gorm:"type:varchar(20)" json:"phone"`
+	Line1         string `gorm:"type:varchar(255)" json:"line1"`
+	Line2         string `gorm:"type:varchar(255)" json:"line2,omitempty"`
+	City          string `gorm:"type:varchar(100)" json:"city"`
+	Province      string `gorm:"type:varchar(100)" json:"province"`
+	PostalCode    string `gorm:"type:varchar(10)" json:"postal_code"`
+}
+
+// Contact is who to reach about an order.
+type Contact struct {
+	Name  string
+	Email string
+	Phone string
 }
 
 func (Order) TableName() string {
 	return "orders"
 }
 
-func (o *Order) CalculateTotalAmount() {
-	total := 0.0
+func (o *Order) CalculateTotalAmount() error {
+	var total Money
 	for _, item := range o.OrderItems {
-		total += item.Price * float64(item.Quantity)
+		var err error
+		if total, err = total.Add(item.Price.Mul(item.Quantity)); err != nil {
+			return err
+		}
 	}
 	o.TotalAmount = total
+	return nil
+}
+
+// orderTransitions lists the statuses an order may move to from each
+// status. Cancelled and delivered orders are final, and only a pending
+// order can be paid, as paying commits the stock it holds.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:       {OrderStatusProcessing, OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusDelivered},
+}
+
+// CanTransitionTo reports whether the order may move to next.
+func (o *Order) CanTransitionTo(next OrderStatus) bool {
+	for _, status := range orderTransitions[o.Status] {
+		if status == next {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *Order) CanBeCancelled() bool {
@@ -47,3 +106,29 @@ func (o *Order) MarkAsPaid() {
 	now := time.Now()
 	o.PaidAt = &now
 }
+
+func (o *Order) IsGuest() bool {
+	return o.UserID == nil
+}
+
+func (o *Order) IsOwnedBy(userID string) bool {
+	return o.UserID != nil && *o.UserID == userID
+}
+
+// OwnerID returns the ordering user's ID, or "" for guest orders.
+func (o *Order) OwnerID() string {
+	if o.UserID == nil {
+		return ""
+	}
+	return *o.UserID
+}
+
+// Contact returns the details captured at checkout, falling back to the
+// user's profile for orders placed before they were recorded.
+func (o *Order) Contact() Contact {
+	c := Contact{Name: o.CustomerName, Email: o.CustomerEmail, Phone: o.CustomerPhone}
+	if c.Email == "" && o.User != nil {
+		c = Contact{Name: o.User.Name, Email: o.User.Email, Phone: o.User.Phone}
+	}
+	return c
+}
@@ -2,10 +2,16 @@ package domain
 
 type OrderItem struct {
 	BaseModel
-	OrderID   string  `gorm:"type:uuid;not null" json:"order_id"`
-	ProductID string  `gorm:"type:uuid;not null" json:"product_id"`
-	Quantity  int     `gorm:"not null" json:"quantity"`
-	Price     float64 `gorm:"type:decimal(10,2);not null" json:"price"` // harga saat order dibuat
+	OrderID   string `gorm:"type:uuid;not null" json:"order_id"`
+	ProductID string `gorm:"type:uuid;not null" json:"product_id"`
+	Quantity  int    `gorm:"not null" json:"quantity"`
+	Price     Money  `gorm:"type:bigint;not null" json:"price"` // harga saat order dibuat
+
+	// Pricing at order time: the product's base price and the price rule
+	// that set Price, if any. The rule name is kept in case it is deleted.
+	ListPrice     Money   `gorm:"type:bigint;not null;default:0" json:"list_price"`
+	PriceRuleID   *string `gorm:"type:uuid" json:"price_rule_id,omitempty"`
+	PriceRuleName string  `gorm:"type:varchar(100)" json:"price_rule_name,omitempty"`
 
 	// Relasi
 	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// OrderNumberFormat lays out order numbers. Layout may hold {date} for the
+// day as YYYYMMDD, {seq} for the sequence number zero-padded to
+// SequenceDigits and {check} for a Luhn check digit over the other digits:
+// "GS-{date}-{seq}{check}" gives GS-20261018-0001234 for number 123.
+type OrderNumberFormat struct {
+	Layout         string
+	SequenceDigits int
+}
+
+// OrderNumberSequence is the Postgres sequence order numbers are drawn from.
+// Its numbers are never handed out twice, even when the order that took one
+// is rolled back.
+const OrderNumberSequence = "order_number_seq"
+
+// maxOrderNumberLength is the size of the orders.order_number column.
+const maxOrderNumberLength = 50
+
+func (f OrderNumberFormat) Validate() error {
+	if strings.Count(f.Layout, "{seq}") != 1 {
+		return fmt.Errorf("order number layout %q must contain {seq} once", f.Layout)
+	}
+	if strings.Count(f.Layout, "{check}") > 1 {
+		return fmt.Errorf("order number layout %q contains {check} more than once", f.Layout)
+	}
+	if f.SequenceDigits < 1 || f.SequenceDigits > 18 {
+		return fmt.Errorf("order number sequence digits must be between 1 and 18, got %d", f.SequenceDigits)
+	}
+	if n := len(f.Format(time.Now(), math.MaxInt64)); n > maxOrderNumberLength {
+		return fmt.Errorf("order number layout %q can be %d characters, more than %d", f.Layout, n, maxOrderNumberLength)
+	}
+	return nil
+}
+
+// Format returns the order number for the seq'th order, placed on day.
+func (f OrderNumberFormat) Format(day time.Time, seq int64) string {
+	number := strings.NewReplacer(
+		"{date}", day.Format("20060102"),
+		"{seq}", fmt.Sprintf("%0*d", f.SequenceDigits, seq),
+	).Replace(f.Layout)
+
+	if !strings.Contains(number, "{check}") {
+		return number
+	}
+	check := luhnCheckDigit(strings.Replace(number, "{check}", "", 1))
+	return strings.Replace(number, "{check}", string(check), 1)
+}
+
+// luhnCheckDigit returns the digit that makes the digits of s, followed by
+// it, pass the Luhn check. Other characters are ignored.
+func luhnCheckDigit(s string) byte {
+	sum, double := 0, true
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
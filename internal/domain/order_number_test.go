@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderNumberFormatFormat(t *testing.T) {
+	day := time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format OrderNumberFormat
+		seq    int64
+		want   string
+	}{
+		{"default layout", OrderNumberFormat{Layout: "ORD-{date}-{seq}{check}", SequenceDigits: 6}, 123, "ORD-20261018-0001234"},
+		{"without check digit", OrderNumberFormat{Layout: "{seq}", SequenceDigits: 4}, 7, "0007"},
+		{"date without check digit", OrderNumberFormat{Layout: "{date}/{seq}", SequenceDigits: 2}, 1, "20261018/01"},
+		{"check digit first", OrderNumberFormat{Layout: "{check}{seq}", SequenceDigits: 2}, 5, "905"},
+		{"sequence wider than digits", OrderNumberFormat{Layout: "{seq}", SequenceDigits: 3}, 12345, "12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Format(day, tt.seq); got != tt.want {
+				t.Errorf("Format(%d) = %q, want %q", tt.seq, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderNumberFormatCheckDigitValidates(t *testing.T) {
+	format := OrderNumberFormat{Layout: "GS-{date}-{seq}{check}", SequenceDigits: 6}
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	for seq := int64(1); seq <= 1000; seq++ {
+		number := format.Format(day, seq)
+		if !luhnValid(number) {
+			t.Fatalf("Format(%d) = %q fails the Luhn check", seq, number)
+		}
+	}
+}
+
+func TestOrderNumberFormatValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  OrderNumberFormat
+		wantErr string
+	}{
+		{"default", OrderNumberFormat{Layout: "ORD-{date}-{seq}{check}", SequenceDigits: 6}, ""},
+		{"missing seq", OrderNumberFormat{Layout: "ORD-{date}", SequenceDigits: 6}, "must contain {seq} once"},
+		{"seq twice", OrderNumberFormat{Layout: "{seq}-{seq}", SequenceDigits: 6}, "must contain {seq} once"},
+		{"check twice", OrderNumberFormat{Layout: "{seq}{check}{check}", SequenceDigits: 6}, "{check} more than once"},
+		{"no digits", OrderNumberFormat{Layout: "{seq}", SequenceDigits: 0}, "between 1 and 18"},
+		{"too many digits", OrderNumberFormat{Layout: "{seq}", SequenceDigits: 19}, "between 1 and 18"},
+		{"too long", OrderNumberFormat{Layout: strings.Repeat("X", 40) + "-{seq}", SequenceDigits: 6}, "more than 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.format.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want byte
+	}{
+		{"", '0'},
+		{"0", '0'},
+		{"1", '8'},
+		{"12345", '5'},
+		{"7992739871", '3'},
+		{"ORD-7992739871", '3'},
+		{"20261018000123", '4'},
+	}
+
+	for _, tt := range tests {
+		if got := luhnCheckDigit(tt.in); got != tt.want {
+			t.Errorf("luhnCheckDigit(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// luhnValid reports whether the digits of s pass the Luhn check.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestOrderCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusPending, OrderStatusPaid, true},
+		{OrderStatusPending, OrderStatusCancelled, true},
+		{OrderStatusPending, OrderStatusShipped, false},
+		{OrderStatusPaid, OrderStatusProcessing, true},
+		{OrderStatusPaid, OrderStatusCancelled, true},
+		{OrderStatusPaid, OrderStatusPending, false},
+		{OrderStatusProcessing, OrderStatusPaid, false},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusShipped, OrderStatusCancelled, false},
+		{OrderStatusDelivered, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPending, false},
+		{OrderStatusCancelled, OrderStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		order := Order{Status: tt.from}
+		if got := order.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s to %s = %t, want %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
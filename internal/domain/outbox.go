@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusPublished OutboxStatus = "published"
+	OutboxStatusFailed    OutboxStatus = "failed" // gave up after the retry limit
+)
+
+// OutboxEvent is a domain event written in the same transaction as the
+// state change it describes, and delivered afterwards by the outbox relay.
+type OutboxEvent struct {
+	BaseModel
+	EventType     string       `gorm:"type:varchar(50);not null;index" json:"event_type"`
+	AggregateID   string       `gorm:"type:varchar(100);not null;index" json:"aggregate_id"`
+	Payload       string       `gorm:"type:jsonb;not null" json:"payload"`
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_pending,priority:1" json:"status"`
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time    `gorm:"not null;index:idx_outbox_pending,priority:2" json:"next_attempt_at"`
+	LastError     string       `gorm:"type:text" json:"last_error,omitempty"`
+	PublishedAt   *time.Time   `json:"published_at,omitempty"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// ProcessedEvent records that a consumer has handled an event, so that
+// redelivered events are skipped.
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"type:varchar(100);primaryKey" json:"consumer"`
+	EventID     string    `gorm:"type:uuid;primaryKey" json:"event_id"`
+	ProcessedAt time.Time `gorm:"not null" json:"processed_at"`
+}
+
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
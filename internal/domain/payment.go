@@ -27,7 +27,7 @@ const (
 type Payment struct {
 	BaseModel
 	OrderID           string        `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
-	Amount            float64       `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Amount            Money         `gorm:"type:bigint;not null" json:"amount"`
 	PaymentMethod     PaymentMethod `gorm:"type:varchar(50)" json:"payment_method"`
 	Status            PaymentStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
 	MidtransOrderID   string        `gorm:"type:varchar(100);uniqueIndex" json:"midtrans_order_id"`
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type PriceRuleType string
+
+const (
+	// PriceRuleSale is a scheduled sale price.
+	PriceRuleSale PriceRuleType = "sale"
+	// PriceRuleTier is a unit price for buying at least MinQuantity.
+	PriceRuleTier PriceRuleType = "tier"
+)
+
+// PriceRule is an alternative unit price for a product. A rule applies
+// between StartsAt and EndsAt, either of which may be open, to order lines
+// of at least MinQuantity units.
+type PriceRule struct {
+	BaseModel
+	ProductID   string        `gorm:"type:uuid;not null;index" json:"product_id"`
+	Name        string        `gorm:"type:varchar(100)" json:"name"`
+	Type        PriceRuleType `gorm:"type:varchar(20);not null" json:"type"`
+	Price       Money         `gorm:"type:bigint;not null" json:"price"`
+	MinQuantity int           `gorm:"not null;default:1" json:"min_quantity"`
+	StartsAt    *time.Time    `json:"starts_at,omitempty"`
+	EndsAt      *time.Time    `json:"ends_at,omitempty"`
+}
+
+func (PriceRule) TableName() string {
+	return "price_rules"
+}
+
+// AppliesTo reports whether the rule prices a line of quantity units at t.
+func (r *PriceRule) AppliesTo(quantity int, t time.Time) bool {
+	if quantity < r.MinQuantity {
+		return false
+	}
+	if r.StartsAt != nil && t.Before(*r.StartsAt) {
+		return false
+	}
+	if r.EndsAt != nil && !t.Before(*r.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Label names the rule for order items.
+func (r *PriceRule) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	if r.Type == PriceRuleTier {
+		return fmt.Sprintf("%d+ units", r.MinQuantity)
+	}
+	return string(r.Type)
+}
+
+// ResolvePrice returns the unit price for a line of quantity units at t:
+// the lowest of basePrice and the applicable rules. The rule is nil when
+// the base price applies.
+func ResolvePrice(basePrice Money, rules []PriceRule, quantity int, t time.Time) (Money, *PriceRule, error) {
+	price := basePrice
+	var applied *PriceRule
+
+	for i := range rules {
+		if !rules[i].AppliesTo(quantity, t) {
+			continue
+		}
+		lower, err := rules[i].Price.LessThan(price)
+		if err != nil {
+			return Money{}, nil, err
+		}
+		if lower {
+			price = rules[i].Price
+			applied = &rules[i]
+		}
+	}
+
+	return price, applied, nil
+}
+
+// PriceHistory records a change to a product's base price.
+type PriceHistory struct {
+	BaseModel
+	ProductID     string `gorm:"type:uuid;not null;index" json:"product_id"`
+	PreviousPrice Money  `gorm:"type:bigint;not null" json:"previous_price"` // 0 when the product was created
+	Price         Money  `gorm:"type:bigint;not null" json:"price"`
+}
+
+func (PriceHistory) TableName() string {
+	return "price_histories"
+}
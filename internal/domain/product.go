@@ -1,15 +1,30 @@
 package domain
 
+import "time"
+
 type Product struct {
 	BaseModel
-	Name        string  `gorm:"type:varchar(200);not null" json:"name"`
-	Description string  `gorm:"type:text" json:"description"`
-	Price       float64 `gorm:"type:decimal(10,2);not null" json:"price"`
-	Stock       int     `gorm:"not null;default:0" json:"stock"`
-	SKU         string  `gorm:"type:varchar(100);uniqueIndex" json:"sku"` // Stock Keeping Unit
-	CategoryID  string  `gorm:"type:uuid" json:"category_id"`
-	ImageURL    string  `gorm:"type:varchar(500)" json:"image_url"`
-	IsActive    bool    `gorm:"default:true" json:"is_active"`
+	Name        string `gorm:"type:varchar(200);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Price       Money  `gorm:"type:bigint;not null" json:"price"`
+	Stock       int    `gorm:"not null;default:0" json:"stock"`
+	Reserved    int    `gorm:"not null;default:0" json:"reserved"`       // held for unpaid orders
+	SKU         string `gorm:"type:varchar(100);uniqueIndex" json:"sku"` // Stock Keeping Unit
+	CategoryID  string `gorm:"type:uuid" json:"category_id"`
+	ImageURL    string `gorm:"type:varchar(500)" json:"image_url"`
+	IsActive    bool   `gorm:"default:true" json:"is_active"`
+
+	// Aggregated from approved reviews.
+	RatingAverage float64 `gorm:"type:decimal(3,2);not null;default:0" json:"rating_average"`
+	RatingCount   int     `gorm:"not null;default:0" json:"rating_count"`
+
+	// Restocking. A ReorderLevel of 0 turns low-stock alerts off.
+	ReorderLevel      int        `gorm:"not null;default:0" json:"reorder_level"`
+	ReorderQuantity   int        `gorm:"not null;default:0" json:"reorder_quantity"` // suggested amount to reorder
+	LowStockAlertedAt *time.Time `json:"-"`
+
+	// DisplayPrice is Price in the currency the customer asked for.
+	DisplayPrice *Money `gorm:"-" json:"display_price,omitempty"`
 
 	Category *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 }
@@ -18,8 +33,18 @@ func (Product) TableName() string {
 	return "products"
 }
 
+// Available is the stock that can still be ordered.
+func (p *Product) Available() int {
+	return p.Stock - p.Reserved
+}
+
 func (p *Product) IsAvailable() bool {
-	return p.IsActive && p.Stock > 0
+	return p.IsActive && p.Available() > 0
+}
+
+// IsLowStock reports whether stock is at or below the reorder level.
+func (p *Product) IsLowStock() bool {
+	return p.ReorderLevel > 0 && p.Stock <= p.ReorderLevel
 }
 
 func (p *Product) ReduceStock(quantity int) error {
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusCommitted ReservationStatus = "committed"
+	ReservationStatusReleased  ReservationStatus = "released"
+)
+
+// StockReservation holds stock in a warehouse for an unpaid order. Paying
+// the order commits it, taking the stock for good; cancelling the order or
+// passing ExpiresAt releases it.
+type StockReservation struct {
+	BaseModel
+	OrderID     string            `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID   string            `gorm:"type:uuid;not null" json:"product_id"`
+	WarehouseID string            `gorm:"type:uuid;not null" json:"warehouse_id"`
+	Quantity    int               `gorm:"not null" json:"quantity"`
+	Status      ReservationStatus `gorm:"type:varchar(20);not null;index:idx_reservations_status_expires" json:"status"`
+	ExpiresAt   time.Time         `gorm:"not null;index:idx_reservations_status_expires" json:"expires_at"`
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusHidden   ReviewStatus = "hidden"
+)
+
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+)
+
+// Review is a rating left by a buyer. Only approved reviews are public and
+// count towards the product's rating.
+type Review struct {
+	BaseModel
+	ProductID   string       `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user" json:"product_id"`
+	UserID      string       `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user" json:"user_id"`
+	OrderItemID string       `gorm:"type:uuid;not null" json:"order_item_id"` // the delivered purchase backing the review
+	AuthorName  string       `gorm:"type:varchar(100)" json:"author_name"`
+	Rating      int          `gorm:"not null" json:"rating"`
+	Title       string       `gorm:"type:varchar(150);not null" json:"title"`
+	Body        string       `gorm:"type:text" json:"body"`
+	Status      ReviewStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ModeratedAt *time.Time   `json:"moderated_at,omitempty"`
+}
+
+func (Review) TableName() string {
+	return "reviews"
+}
+
+func (r *Review) Moderate(status ReviewStatus) {
+	now := time.Now()
+	r.Status = status
+	r.ModeratedAt = &now
+}
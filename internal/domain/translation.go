@@ -0,0 +1,45 @@
+package domain
+
+// ProductTranslation is a product's name and description in another locale.
+// Requests in a locale without a translation get the product's own text.
+type ProductTranslation struct {
+	BaseModel
+	ProductID   string `gorm:"type:uuid;not null;uniqueIndex:idx_product_translations_locale" json:"product_id"`
+	Locale      string `gorm:"type:varchar(10);not null;uniqueIndex:idx_product_translations_locale" json:"locale"`
+	Name        string `gorm:"type:varchar(200);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+}
+
+func (ProductTranslation) TableName() string {
+	return "product_translations"
+}
+
+// CategoryTranslation is a category's name and description in another
+// locale.
+type CategoryTranslation struct {
+	BaseModel
+	CategoryID  string `gorm:"type:uuid;not null;uniqueIndex:idx_category_translations_locale" json:"category_id"`
+	Locale      string `gorm:"type:varchar(10);not null;uniqueIndex:idx_category_translations_locale" json:"locale"`
+	Name        string `gorm:"type:varchar(200);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+}
+
+func (CategoryTranslation) TableName() string {
+	return "category_translations"
+}
+
+// Localize replaces the product's text with t.
+func (p *Product) Localize(t ProductTranslation) {
+	p.Name = t.Name
+	if t.Description != "" {
+		p.Description = t.Description
+	}
+}
+
+// Localize replaces the category's text with t.
+func (c *Category) Localize(t CategoryTranslation) {
+	c.Name = t.Name
+	if t.Description != "" {
+		c.Description = t.Description
+	}
+}
@@ -1,13 +1,22 @@
 package domain
 
+import "time"
+
 type User struct {
 	BaseModel
 	Email    string `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password string `gorm:"type:varchar(255);not null" json:"-"` // "-" agar tidak muncul di JSON
+	Password string `gorm:"type:varchar(255);not null" json:"-"` // "-" keeps it out of JSON
 	Name     string `gorm:"type:varchar(100)" json:"name"`
 	Phone    string `gorm:"type:varchar(20)" json:"phone"`
 	Role     string `gorm:"type:varchar(20);default:'customer'" json:"role"` // customer, admin
 	IsActive bool   `gorm:"default:true" json:"is_active"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// Account lockout
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockoutCount        int        `gorm:"not null;default:0" json:"-"` // consecutive lockouts, drives the backoff
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
 }
 
 func (User) TableName() string {
@@ -17,3 +26,39 @@ func (User) TableName() string {
 func (u *User) IsAdmin() bool {
 	return u.Role == "admin"
 }
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) MarkEmailVerified() {
+	now := time.Now()
+	u.EmailVerifiedAt = &now
+}
+
+// LockoutPolicy controls when repeated failed logins lock an account.
+// Each lockout lasts BaseDuration * 2^(previous lockouts), capped at
+// MaxDuration. Independently, an IP with IPMaxFailures failed attempts
+// within IPWindow is refused.
+type LockoutPolicy struct {
+	MaxFailedAttempts int
+	BaseDuration      time.Duration
+	MaxDuration       time.Duration
+	IPMaxFailures     int
+	IPWindow          time.Duration
+}
+
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// ResetLoginFailures clears lockout state after a successful login.
+func (u *User) ResetLoginFailures() {
+	u.FailedLoginAttempts = 0
+	u.LockoutCount = 0
+	u.LockedUntil = nil
+}
+
+func (u *User) Unlock() {
+	u.ResetLoginFailures()
+}
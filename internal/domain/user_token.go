@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use, expiring token sent to a user by email. Only
+// the SHA-256 hash of the token is stored.
+type UserToken struct {
+	BaseModel
+	UserID    string           `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   UserTokenPurpose `gorm:"type:varchar(30);not null" json:"purpose"`
+	TokenHash string           `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time        `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
+
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,73 @@
+package domain
+
+// Warehouse is a location stock is held and shipped from.
+type Warehouse struct {
+	BaseModel
+	Code     string `gorm:"type:varchar(20);uniqueIndex;not null" json:"code"`
+	Name     string `gorm:"type:varchar(100);not null" json:"name"`
+	Address  string `gorm:"type:varchar(255)" json:"address"`
+	City     string `gorm:"type:varchar(100)" json:"city"`
+	Province string `gorm:"type:varchar(100)" json:"province"`
+	Priority int    `gorm:"not null;default:0" json:"priority"` // lower ships first
+	IsActive bool   `gorm:"default:true" json:"is_active"`
+}
+
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// InventoryLevel is the stock of one product in one warehouse. The sums over
+// active warehouses are kept in Product.Stock and Product.Reserved.
+type InventoryLevel struct {
+	BaseModel
+	ProductID   string `gorm:"type:uuid;not null;uniqueIndex:idx_inventory_product_warehouse" json:"product_id"`
+	WarehouseID string `gorm:"type:uuid;not null;uniqueIndex:idx_inventory_product_warehouse;index" json:"warehouse_id"`
+	Quantity    int    `gorm:"not null;default:0" json:"quantity"`
+	Reserved    int    `gorm:"not null;default:0" json:"reserved"` // part of Quantity held by active reservations
+
+	Product   *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Warehouse *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+}
+
+func (InventoryLevel) TableName() string {
+	return "inventory_levels"
+}
+
+// Available is the stock that can still be allocated.
+func (l *InventoryLevel) Available() int {
+	return l.Quantity - l.Reserved
+}
+
+// StockTransfer records stock moved between warehouses.
+type StockTransfer struct {
+	BaseModel
+	ProductID       string `gorm:"type:uuid;not null;index" json:"product_id"`
+	FromWarehouseID string `gorm:"type:uuid;not null;index" json:"from_warehouse_id"`
+	ToWarehouseID   string `gorm:"type:uuid;not null;index" json:"to_warehouse_id"`
+	Quantity        int    `gorm:"not null" json:"quantity"`
+	Note            string `gorm:"type:varchar(255)" json:"note"`
+	CreatedBy       string `gorm:"type:uuid" json:"created_by"`
+
+	Product       *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	FromWarehouse *Warehouse `gorm:"foreignKey:FromWarehouseID" json:"from_warehouse,omitempty"`
+	ToWarehouse   *Warehouse `gorm:"foreignKey:ToWarehouseID" json:"to_warehouse,omitempty"`
+}
+
+func (StockTransfer) TableName() string {
+	return "stock_transfers"
+}
+
+// OrderAllocation is the part of an order line shipped from one warehouse.
+type OrderAllocation struct {
+	BaseModel
+	OrderID     string `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID   string `gorm:"type:uuid;not null" json:"product_id"`
+	WarehouseID string `gorm:"type:uuid;not null;index" json:"warehouse_id"`
+	Quantity    int    `gorm:"not null" json:"quantity"`
+
+	Warehouse *Warehouse `gorm:"foreignKey:WarehouseID" json:"warehouse,omitempty"`
+}
+
+func (OrderAllocation) TableName() string {
+	return "order_allocations"
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// WebhookEndpoint is a merchant-registered URL that receives signed event
+// payloads. An empty Events list subscribes to every event type.
+type WebhookEndpoint struct {
+	BaseModel
+	URL                 string     `gorm:"type:varchar(500);not null" json:"url"`
+	Description         string     `gorm:"type:varchar(255)" json:"description"`
+	Secret              string     `gorm:"type:varchar(100);not null" json:"-"`
+	Events              []string   `gorm:"type:jsonb;serializer:json" json:"events"`
+	IsActive            bool       `gorm:"not null;default:true" json:"is_active"`
+	ConsecutiveFailures int        `gorm:"not null;default:0" json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Enable re-activates an endpoint and clears its failure streak.
+func (e *WebhookEndpoint) Enable() {
+	e.IsActive = true
+	e.ConsecutiveFailures = 0
+	e.DisabledAt = nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one endpoint, together with the
+// outcome of the latest attempt.
+type WebhookDelivery struct {
+	BaseModel
+	EndpointID     string                `gorm:"type:uuid;not null;index" json:"endpoint_id"`
+	EventID        string                `gorm:"type:uuid;not null;index" json:"event_id"`
+	EventType      string                `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        string                `gorm:"type:jsonb;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null;index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	ResponseBody   string                `gorm:"type:text" json:"response_body,omitempty"`
+	LastError      string                `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	RedeliveryOf   *string               `gorm:"type:uuid" json:"redelivery_of,omitempty"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
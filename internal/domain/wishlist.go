@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+type WishlistItem struct {
+	BaseModel
+	UserID        string `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_product" json:"user_id"`
+	ProductID     string `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_product;index" json:"product_id"`
+	PriceSnapshot Money  `gorm:"type:bigint;not null" json:"price_snapshot"` // price when added
+
+	// Alert bookkeeping, so the same alert is not sent twice.
+	LastAlertedPrice     *Money     `gorm:"type:bigint" json:"-"`
+	BackInStockAlertedAt *time.Time `json:"-"`
+
+	// Rate-limited alerts, sent once they are due.
+	BackInStockAlertDueAt *time.Time `gorm:"index" json:"-"`
+	PriceDropAlertDueAt   *time.Time `gorm:"index" json:"-"`
+
+	User    *User    `gorm:"foreignKey:UserID" json:"-"`
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+func (WishlistItem) TableName() string {
+	return "wishlist_items"
+}
+
+// WishlistAlert is a kind of wishlist alert.
+type WishlistAlert string
+
+const (
+	WishlistAlertBackInStock WishlistAlert = "back_in_stock"
+	WishlistAlertPriceDrop   WishlistAlert = "price_drop"
+)
+
+// PriceDropBelow reports whether price is lower than the snapshot and than
+// any price the user was already alerted about.
+func (w *WishlistItem) PriceDropBelow(price Money) (bool, error) {
+	dropped, err := price.LessThan(w.PriceSnapshot)
+	if err != nil || !dropped || w.LastAlertedPrice == nil {
+		return dropped, err
+	}
+	return price.LessThan(*w.LastAlertedPrice)
+}
+
+// PreviousPrice is the price a drop alert compares against.
+func (w *WishlistItem) PreviousPrice() Money {
+	if w.LastAlertedPrice != nil {
+		return *w.LastAlertedPrice
+	}
+	return w.PriceSnapshot
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+type Handler func(ctx context.Context, e Event) error
+
+type subscription struct {
+	consumer string
+	handler  Handler
+}
+
+// Bus delivers events to in-process subscribers. Each subscriber is named;
+// the name is used to remember which events it has already handled, so a
+// redelivered event only reaches the subscribers that failed it.
+type Bus struct {
+	subs      map[Type][]subscription
+	processed repository.ProcessedEventRepository
+}
+
+func NewBus(processed repository.ProcessedEventRepository) *Bus {
+	return &Bus{subs: map[Type][]subscription{}, processed: processed}
+}
+
+// Subscribe registers handler for eventType. It is not safe to call once
+// the relay is running.
+func (b *Bus) Subscribe(consumer string, eventType Type, handler Handler) {
+	b.subs[eventType] = append(b.subs[eventType], subscription{consumer: consumer, handler: handler})
+}
+
+// Dispatch runs every subscriber of e that has not handled it yet and
+// returns the joined errors of those that failed.
+func (b *Bus) Dispatch(ctx context.Context, e Event) error {
+	var errs []error
+
+	for _, sub := range b.subs[e.Type] {
+		done, err := b.processed.Exists(ctx, sub.consumer, e.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if done {
+			continue
+		}
+
+		if err := sub.handler(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.consumer, err))
+			continue
+		}
+
+		if err := b.processed.Create(ctx, &domain.ProcessedEvent{
+			Consumer:    sub.consumer,
+			EventID:     e.ID,
+			ProcessedAt: time.Now(),
+		}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,157 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
+
+type Type string
+
+const (
+	OrderCreated       Type = "order.created"
+	OrderPaid          Type = "order.paid"
+	OrderCancelled     Type = "order.cancelled"
+	OrderStatusChanged Type = "order.status_changed"
+	PaymentSucceeded   Type = "payment.succeeded"
+	PaymentFailed      Type = "payment.failed"
+	ProductCreated     Type = "product.created"
+	ProductUpdated     Type = "product.updated"
+	ProductDeleted     Type = "product.deleted"
+	StockChanged       Type = "stock.changed"
+)
+
+// Types lists every event type, in the order they are documented.
+var Types = []Type{
+	OrderCreated,
+	OrderPaid,
+	OrderCancelled,
+	OrderStatusChanged,
+	PaymentSucceeded,
+	PaymentFailed,
+	ProductCreated,
+	ProductUpdated,
+	ProductDeleted,
+	StockChanged,
+}
+
+func IsValidType(t Type) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a domain event as delivered to subscribers. ID is stable across
+// redeliveries and is what consumers de-duplicate on.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        Type            `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Decode unmarshals the payload into v.
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+type OrderItem struct {
+	ProductID string       `json:"product_id"`
+	Quantity  int          `json:"quantity"`
+	Price     domain.Money `json:"price"`
+}
+
+type OrderCreatedPayload struct {
+	OrderID     string       `json:"order_id"`
+	OrderNumber string       `json:"order_number"`
+	UserID      string       `json:"user_id"`
+	TotalAmount domain.Money `json:"total_amount"`
+	Items       []OrderItem  `json:"items"`
+}
+
+type OrderPaidPayload struct {
+	OrderID     string       `json:"order_id"`
+	OrderNumber string       `json:"order_number"`
+	UserID      string       `json:"user_id"`
+	PaymentID   string       `json:"payment_id,omitempty"` // empty when marked paid by an admin
+	Amount      domain.Money `json:"amount"`
+}
+
+type OrderCancelledPayload struct {
+	OrderID     string `json:"order_id"`
+	OrderNumber string `json:"order_number"`
+	UserID      string `json:"user_id"`
+}
+
+type OrderStatusChangedPayload struct {
+	OrderID     string `json:"order_id"`
+	OrderNumber string `json:"order_number"`
+	UserID      string `json:"user_id"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+}
+
+type PaymentPayload struct {
+	PaymentID string       `json:"payment_id"`
+	OrderID   string       `json:"order_id"`
+	Amount    domain.Money `json:"amount"`
+	Status    string       `json:"status"`
+	Method    string       `json:"method,omitempty"`
+}
+
+func NewPaymentPayload(p *domain.Payment) PaymentPayload {
+	return PaymentPayload{
+		PaymentID: p.ID,
+		OrderID:   p.OrderID,
+		Amount:    p.Amount,
+		Status:    string(p.Status),
+		Method:    string(p.PaymentMethod),
+	}
+}
+
+type ProductPayload struct {
+	ProductID  string       `json:"product_id"`
+	SKU        string       `json:"sku"`
+	Name       string       `json:"name"`
+	Price      domain.Money `json:"price"`
+	Stock      int          `json:"stock"`
+	CategoryID string       `json:"category_id"`
+	IsActive   bool         `json:"is_active"`
+}
+
+func NewProductPayload(p *domain.Product) ProductPayload {
+	return ProductPayload{
+		ProductID:  p.ID,
+		SKU:        p.SKU,
+		Name:       p.Name,
+		Price:      p.Price,
+		Stock:      p.Stock,
+		CategoryID: p.CategoryID,
+		IsActive:   p.IsActive,
+	}
+}
+
+type StockReason string
+
+const (
+	StockReasonOrder      StockReason = "order"
+	StockReasonCancel     StockReason = "cancel"
+	StockReasonAdjustment StockReason = "adjustment"
+	StockReasonTransfer   StockReason = "transfer"
+	StockReasonExpired    StockReason = "expired"
+)
+
+type StockChangedPayload struct {
+	ProductID string      `json:"product_id"`
+	Delta     int         `json:"delta"`
+	Stock     int         `json:"stock"` // available to order, net of reservations
+	Reason    StockReason `json:"reason"`
+	OrderID   string      `json:"order_id,omitempty"`
+	// WarehouseID is set when the change concerns a single warehouse.
+	WarehouseID string `json:"warehouse_id,omitempty"`
+}
@@ -0,0 +1,40 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// Publisher records domain events. Call it with the context of the
+// transaction that makes the state change, so that both commit or neither
+// does.
+type Publisher interface {
+	Publish(ctx context.Context, eventType Type, aggregateID string, payload any) error
+}
+
+type outboxPublisher struct {
+	outboxRepo repository.OutboxRepository
+}
+
+func NewOutboxPublisher(outboxRepo repository.OutboxRepository) Publisher {
+	return &outboxPublisher{outboxRepo: outboxRepo}
+}
+
+func (p *outboxPublisher) Publish(ctx context.Context, eventType Type, aggregateID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return p.outboxRepo.Create(ctx, &domain.OutboxEvent{
+		EventType:     string(eventType),
+		AggregateID:   aggregateID,
+		Payload:       string(data),
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	})
+}
@@ -0,0 +1,36 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink appends every event to a Redis stream, trimmed to
+// roughly maxLen entries. Stream consumers should de-duplicate on the "id"
+// field, as an event can be appended more than once.
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) Sink {
+	return &redisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *redisStreamSink) Publish(ctx context.Context, e Event) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"id":           e.ID,
+			"type":         string(e.Type),
+			"aggregate_id": e.AggregateID,
+			"occurred_at":  e.OccurredAt.Format(time.RFC3339Nano),
+			"payload":      string(e.Payload),
+		},
+	}).Err()
+}
@@ -0,0 +1,186 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+// Sink forwards events outside the process, e.g. to a Redis stream.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+type RelayConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	LeaseDuration  time.Duration // how long a claimed batch is hidden from other relays
+	Retention      time.Duration // how long published events are kept
+}
+
+// Relay moves events from the outbox to the bus and sinks. An event is
+// marked published only after every subscriber and sink accepted it, so
+// delivery is at least once.
+type Relay struct {
+	transactor  repository.Transactor
+	outboxRepo  repository.OutboxRepository
+	bus         *Bus
+	sinks       []Sink
+	cfg         RelayConfig
+	logger      *slog.Logger
+	lastCleanup time.Time
+}
+
+func NewRelay(transactor repository.Transactor, outboxRepo repository.OutboxRepository, bus *Bus, sinks []Sink, cfg RelayConfig, logger *slog.Logger) *Relay {
+	return &Relay{
+		transactor: transactor,
+		outboxRepo: outboxRepo,
+		bus:        bus,
+		sinks:      sinks,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back.
+		for {
+			n, err := r.processBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "Outbox relay batch failed", slog.Any("error", err))
+			}
+			if err != nil || n < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		r.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) processBatch(ctx context.Context) (int, error) {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Subscribers may be slow, e.g. sending email, so they run after the
+	// claim has committed and hold no locks or connections meanwhile.
+	for i := range events {
+		r.deliver(ctx, &events[i])
+
+		if err := r.outboxRepo.Update(ctx, &events[i]); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// claim leases a batch of due events by pushing their next attempt past the
+// lease, so another relay doesn't pick them up meanwhile and a crash only
+// delays them.
+func (r *Relay) claim(ctx context.Context) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		events, err = r.outboxRepo.FetchDue(ctx, time.Now(), r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		lease := time.Now().Add(r.cfg.LeaseDuration)
+		for i := range events {
+			events[i].NextAttemptAt = lease
+			if err := r.outboxRepo.Update(ctx, &events[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return events, err
+}
+
+func (r *Relay) deliver(ctx context.Context, row *domain.OutboxEvent) {
+	e := Event{
+		ID:          row.ID,
+		Type:        Type(row.EventType),
+		AggregateID: row.AggregateID,
+		OccurredAt:  row.CreatedAt,
+		Payload:     json.RawMessage(row.Payload),
+	}
+
+	errs := []error{r.bus.Dispatch(ctx, e)}
+	for _, sink := range r.sinks {
+		errs = append(errs, sink.Publish(ctx, e))
+	}
+
+	row.Attempts++
+	err := errors.Join(errs...)
+	if err == nil {
+		now := time.Now()
+		row.Status = domain.OutboxStatusPublished
+		row.PublishedAt = &now
+		row.LastError = ""
+		metrics.OutboxDeliveriesTotal.WithLabelValues(row.EventType, "published").Inc()
+		return
+	}
+
+	row.LastError = err.Error()
+	attrs := []any{
+		slog.String("event_id", row.ID),
+		slog.String("event_type", row.EventType),
+		slog.Int("attempt", row.Attempts),
+		slog.Any("error", err),
+	}
+
+	if row.Attempts >= r.cfg.MaxAttempts {
+		row.Status = domain.OutboxStatusFailed
+		metrics.OutboxDeliveriesTotal.WithLabelValues(row.EventType, "failed").Inc()
+		r.logger.ErrorContext(ctx, "Outbox event delivery failed, giving up", attrs...)
+		return
+	}
+
+	row.NextAttemptAt = time.Now().Add(utils.Backoff(row.Attempts, r.cfg.RetryBaseDelay, r.cfg.RetryMaxDelay))
+	metrics.OutboxDeliveriesTotal.WithLabelValues(row.EventType, "retry").Inc()
+	r.logger.WarnContext(ctx, "Outbox event delivery failed, retrying", attrs...)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	if r.cfg.Retention <= 0 || time.Since(r.lastCleanup) < time.Hour {
+		return
+	}
+	r.lastCleanup = time.Now()
+
+	deleted, err := r.outboxRepo.DeletePublishedBefore(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Outbox cleanup failed", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.InfoContext(ctx, "Outbox cleanup", slog.Int64("deleted", deleted))
+	}
+}
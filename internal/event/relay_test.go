@@ -0,0 +1,142 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// memoryOutbox keeps outbox rows in memory. Reads return copies, as the
+// database would.
+type memoryOutbox struct {
+	repository.OutboxRepository
+
+	mu   sync.Mutex
+	rows map[string]domain.OutboxEvent
+}
+
+func (m *memoryOutbox) FetchDue(_ context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []domain.OutboxEvent
+	for _, row := range m.rows {
+		if row.Status == domain.OutboxStatusPending && !row.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, row)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryOutbox) Update(_ context.Context, row *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[row.ID] = *row
+	return nil
+}
+
+func (m *memoryOutbox) row(id string) domain.OutboxEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rows[id]
+}
+
+// memoryProcessed keeps processed events in memory.
+type memoryProcessed struct {
+	repository.ProcessedEventRepository
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func (r *memoryProcessed) Exists(_ context.Context, consumer, eventID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.done[consumer+"/"+eventID], nil
+}
+
+func (r *memoryProcessed) Create(_ context.Context, processed *domain.ProcessedEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done[processed.Consumer+"/"+processed.EventID] = true
+	return nil
+}
+
+// trackingTransactor reports whether a transaction is open.
+type trackingTransactor struct {
+	mu   sync.Mutex
+	open bool
+}
+
+func (t *trackingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	t.open = true
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		t.open = false
+		t.mu.Unlock()
+	}()
+	return fn(ctx)
+}
+
+func (t *trackingTransactor) isOpen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.open
+}
+
+func TestRelayDeliversLeasedEventsOutsideTransaction(t *testing.T) {
+	outbox := &memoryOutbox{rows: map[string]domain.OutboxEvent{}}
+	for _, id := range []string{"event-1", "event-2"} {
+		row := domain.OutboxEvent{EventType: string(OrderPaid), Payload: "{}", Status: domain.OutboxStatusPending}
+		row.ID = id
+		outbox.rows[id] = row
+	}
+
+	const lease = time.Hour
+	transactor := &trackingTransactor{}
+	bus := NewBus(&memoryProcessed{done: map[string]bool{}})
+	relay := NewRelay(transactor, outbox, bus, nil, RelayConfig{
+		BatchSize:      10,
+		MaxAttempts:    3,
+		RetryBaseDelay: time.Millisecond,
+		RetryMaxDelay:  time.Millisecond,
+		LeaseDuration:  lease,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	fail := errors.New("smtp unavailable")
+	bus.Subscribe("test", OrderPaid, func(_ context.Context, e Event) error {
+		if transactor.isOpen() {
+			t.Error("subscriber ran inside the claim transaction")
+		}
+		if until := outbox.row(e.ID).NextAttemptAt; time.Until(until) < lease-time.Minute {
+			t.Errorf("event %s is due again at %v while being delivered, want it leased", e.ID, until)
+		}
+		if e.ID == "event-2" {
+			return fail
+		}
+		return nil
+	})
+
+	n, err := relay.processBatch(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("processBatch = %d, %v; want 2 events", n, err)
+	}
+
+	if row := outbox.row("event-1"); row.Status != domain.OutboxStatusPublished {
+		t.Errorf("event-1 status = %s, want published", row.Status)
+	}
+	row := outbox.row("event-2")
+	if row.Status != domain.OutboxStatusPending || row.Attempts != 1 || time.Until(row.NextAttemptAt) > time.Minute {
+		t.Errorf("event-2 status = %s after %d attempts, next at %v; want a retry from the backoff, not the lease", row.Status, row.Attempts, row.NextAttemptAt)
+	}
+}
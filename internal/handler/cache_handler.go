@@ -1,8 +1,6 @@
 package handler
 
 import (
-	"context"
-
 	"github.com/affandisy/goshop/pkg/cache"
 	"github.com/affandisy/goshop/pkg/response"
 	"github.com/gin-gonic/gin"
@@ -17,17 +15,17 @@ func NewCacheHandler(cacheService cache.CacheService) *CacheHandler {
 }
 
 func (h *CacheHandler) ClearProductsCache(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	err := h.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
 	if err != nil {
-		response.InternalServerError(c, "Failed to clear cache", err)
+		c.Error(err)
 		return
 	}
 
 	err = h.cacheService.DeleteByPattern(ctx, cache.ProductPrefix+"*")
 	if err != nil {
-		response.InternalServerError(c, "Failed to clear cache", err)
+		c.Error(err)
 		return
 	}
 
@@ -35,11 +33,11 @@ func (h *CacheHandler) ClearProductsCache(c *gin.Context) {
 }
 
 func (h *CacheHandler) ClearCategoriesCache(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	err := h.cacheService.DeleteByPattern(ctx, cache.CategoryPrefix+"*")
 	if err != nil {
-		response.InternalServerError(c, "Failed to clear cache", err)
+		c.Error(err)
 		return
 	}
 
@@ -47,11 +45,11 @@ func (h *CacheHandler) ClearCategoriesCache(c *gin.Context) {
 }
 
 func (h *CacheHandler) ClearAllCache(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	err := h.cacheService.FlushAll(ctx)
 	if err != nil {
-		response.InternalServerError(c, "Failed to clear cache", err)
+		c.Error(err)
 		return
 	}
 
@@ -59,7 +57,7 @@ func (h *CacheHandler) ClearAllCache(c *gin.Context) {
 }
 
 func (h *CacheHandler) GetCacheStats(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	stats := gin.H{
 		"products_cached":   h.cacheService.Exists(ctx, cache.ProductsPrefix+"list:page:1:limit:10"),
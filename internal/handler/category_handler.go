@@ -1,9 +1,6 @@
 package handler
 
 import (
-	"errors"
-
-	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/service"
 	"github.com/affandisy/goshop/pkg/response"
@@ -24,13 +21,13 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 	var req dto.CategoryRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	category, err := h.categoryService.Create(req)
+	category, err := h.categoryService.Create(c.Request.Context(), req)
 	if err != nil {
-		response.InternalServerError(c, "Failed to create category", err)
+		c.Error(err)
 		return
 	}
 
@@ -38,15 +35,14 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 }
 
 func (h *CategoryHandler) GetByID(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	category, err := h.categoryService.GetByID(id)
+	category, err := h.categoryService.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrCategoryNotFound) {
-			response.NotFound(c, "Category not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to get category", err)
+		c.Error(err)
 		return
 	}
 
@@ -54,9 +50,9 @@ func (h *CategoryHandler) GetByID(c *gin.Context) {
 }
 
 func (h *CategoryHandler) GetAll(c *gin.Context) {
-	categories, err := h.categoryService.GetAll()
+	categories, err := h.categoryService.GetAll(c.Request.Context())
 	if err != nil {
-		response.InternalServerError(c, "Failed to get categories", err)
+		c.Error(err)
 		return
 	}
 
@@ -64,21 +60,20 @@ func (h *CategoryHandler) GetAll(c *gin.Context) {
 }
 
 func (h *CategoryHandler) Update(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
 	var req dto.CategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	category, err := h.categoryService.Update(id, req)
+	category, err := h.categoryService.Update(c.Request.Context(), id, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrCategoryNotFound) {
-			response.NotFound(c, "Category not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to update category", err)
+		c.Error(err)
 		return
 	}
 
@@ -86,17 +81,67 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 }
 
 func (h *CategoryHandler) Delete(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	err := h.categoryService.Delete(id)
+	err := h.categoryService.Delete(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrCategoryNotFound) {
-			response.NotFound(c, "Category not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to delete category", err)
+		c.Error(err)
 		return
 	}
 
 	response.Success(c, "Category deleted successfully", nil)
 }
+
+func (h *CategoryHandler) GetTranslations(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	translations, err := h.categoryService.ListTranslations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translations retrieved successfully", translations)
+}
+
+func (h *CategoryHandler) SetTranslation(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.TranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	translation, err := h.categoryService.SetTranslation(c.Request.Context(), id, c.Param("locale"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translation saved successfully", translation)
+}
+
+func (h *CategoryHandler) DeleteTranslation(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.categoryService.DeleteTranslation(c.Request.Context(), id, c.Param("locale"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translation deleted successfully", nil)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateService service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(exchangeRateService service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+func (h *ExchangeRateHandler) Create(c *gin.Context) {
+	var req dto.ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	rate, err := h.exchangeRateService.Create(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Exchange rate created successfully", rate)
+}
+
+func (h *ExchangeRateHandler) GetAll(c *gin.Context) {
+	var query dto.ExchangeRateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		query.Page = 1
+		query.Limit = 10
+	}
+
+	rates, total, err := h.exchangeRateService.List(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(query.Page, query.Limit, total, rates)
+	response.Success(c, "Exchange rates retrieved successfully", paginationResp)
+}
+
+func (h *ExchangeRateHandler) Delete(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.exchangeRateService.Delete(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Exchange rate deleted successfully", nil)
+}
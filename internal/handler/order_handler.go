@@ -1,9 +1,6 @@
 package handler
 
 import (
-	"errors"
-
-	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/middleware"
 	"github.com/affandisy/goshop/internal/service"
@@ -29,25 +26,13 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	var req dto.CreateOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(userID, req)
+	order, err := h.orderService.CreateOrder(c.Request.Context(), userID, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrEmptyCart) {
-			response.BadRequest(c, "Cart is empty", err)
-			return
-		}
-		if errors.Is(err, domain.ErrProductNotFound) {
-			response.BadRequest(c, "Product not found", err)
-			return
-		}
-		if errors.Is(err, domain.ErrInsufficientStock) {
-			response.BadRequest(c, "Insufficient stock", err)
-			return
-		}
-		response.InternalServerError(c, "Failed to create order", err)
+		c.Error(err)
 		return
 	}
 
@@ -64,19 +49,14 @@ func (h *OrderHandler) GetOrderByID(c *gin.Context) {
 	role, _ := middleware.GetUserRole(c)
 	isAdmin := role == "admin"
 
-	orderID := c.Param("id")
+	orderID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	order, err := h.orderService.GetOrderByID(orderID, userID, isAdmin)
+	order, err := h.orderService.GetOrderByID(c.Request.Context(), orderID, userID, isAdmin)
 	if err != nil {
-		if errors.Is(err, domain.ErrOrderNotFound) {
-			response.NotFound(c, "Order not found")
-			return
-		}
-		if errors.Is(err, domain.ErrForbidden) {
-			response.Forbidden(c, "You dont have access to this order")
-			return
-		}
-		response.InternalServerError(c, "Failed to get order", err)
+		c.Error(err)
 		return
 	}
 
@@ -96,9 +76,9 @@ func (h *OrderHandler) GetMyOrders(c *gin.Context) {
 		params.Limit = 10
 	}
 
-	orders, total, err := h.orderService.GetMyOrders(userID, params.Page, params.Limit)
+	orders, total, err := h.orderService.GetMyOrders(c.Request.Context(), userID, params.Page, params.Limit)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get orders", err)
+		c.Error(err)
 		return
 	}
 
@@ -113,9 +93,9 @@ func (h *OrderHandler) GetAllOrders(c *gin.Context) {
 		params.Limit = 10
 	}
 
-	orders, total, err := h.orderService.GetAllOrders(params.Page, params.Limit)
+	orders, total, err := h.orderService.GetAllOrders(c.Request.Context(), params.Page, params.Limit)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get orders", err)
+		c.Error(err)
 		return
 	}
 
@@ -124,21 +104,20 @@ func (h *OrderHandler) GetAllOrders(c *gin.Context) {
 }
 
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
-	orderID := c.Param("id")
+	orderID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
 	var req dto.UpdateOrderStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	order, err := h.orderService.UpdateOrderStatus(orderID, req)
+	order, err := h.orderService.UpdateOrderStatus(c.Request.Context(), orderID, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrOrderNotFound) {
-			response.NotFound(c, "Order not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to update order status", err)
+		c.Error(err)
 		return
 	}
 
@@ -148,29 +127,76 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
-		response.Unauthorized(c, "User not authentication")
+		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	orderID := c.Param("id")
+	orderID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	err = h.orderService.CancelOrder(orderID, userID)
+	err = h.orderService.CancelOrder(c.Request.Context(), orderID, userID)
 	if err != nil {
-		if errors.Is(err, domain.ErrOrderNotFound) {
-			response.NotFound(c, "Order not found")
-			return
-		}
-		if errors.Is(err, domain.ErrForbidden) {
-			response.Forbidden(c, "You dont have access to this order")
-			return
-		}
-		if errors.Is(err, domain.ErrCannotCancelOrder) {
-			response.BadRequest(c, "Order cannot be cancelled", err)
-			return
-		}
-		response.InternalServerError(c, "Failed to cancel order", err)
+		c.Error(err)
 		return
 	}
 
 	response.Success(c, "Order cancelled successfully", nil)
 }
+
+func (h *OrderHandler) CreateGuestOrder(c *gin.Context) {
+	var req dto.CreateGuestOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	resp, err := h.orderService.CreateGuestOrder(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Order created successfully. Keep the access token to track your order", resp)
+}
+
+func (h *OrderHandler) GetGuestOrder(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetGuestOrder(c.Request.Context(), id, orderAccessToken(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Order retrieved successfully", order)
+}
+
+func (h *OrderHandler) ClaimGuestOrders(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	claimed, err := h.orderService.ClaimGuestOrders(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Guest orders claimed successfully", dto.ClaimGuestOrdersResponse{Claimed: claimed})
+}
+
+// orderAccessToken reads a guest order access token from the X-Order-Token
+// header, or the token query parameter used by emailed links.
+func orderAccessToken(c *gin.Context) string {
+	if token := c.GetHeader("X-Order-Token"); token != "" {
+		return token
+	}
+	return c.Query("token")
+}
@@ -1,10 +1,8 @@
 package handler
 
 import (
-	"errors"
-	"log"
+	"log/slog"
 
-	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/middleware"
 	"github.com/affandisy/goshop/internal/service"
@@ -15,11 +13,13 @@ import (
 
 type PaymentHandler struct {
 	paymentService service.PaymentService
+	logger         *slog.Logger
 }
 
-func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
+func NewPaymentHandler(paymentService service.PaymentService, logger *slog.Logger) *PaymentHandler {
 	return &PaymentHandler{
 		paymentService: paymentService,
+		logger:         logger,
 	}
 }
 
@@ -32,29 +32,29 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 
 	var req dto.CreatePaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	payment, err := h.paymentService.CreatePayment(userID, req)
+	payment, err := h.paymentService.CreatePayment(c.Request.Context(), userID, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrOrderNotFound) {
-			response.NotFound(c, "Order not found")
-			return
-		}
-		if errors.Is(err, domain.ErrForbidden) {
-			response.Forbidden(c, "You don't have access to this order")
-			return
-		}
-		if errors.Is(err, domain.ErrOrderAlreadyPaid) {
-			response.BadRequest(c, "Order already paid", err)
-			return
-		}
-		if errors.Is(err, domain.ErrPaymentAlreadyExists) {
-			response.BadRequest(c, "Payment already exists for this order", err)
-			return
-		}
-		response.InternalServerError(c, "Failed to create payment", err)
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Payment created successfully. Please complete payment via Snap URL", dto.PaymentMapToResponse(payment))
+}
+
+func (h *PaymentHandler) CreateGuestPayment(c *gin.Context) {
+	var req dto.CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	payment, err := h.paymentService.CreateGuestPayment(c.Request.Context(), orderAccessToken(c), req)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -62,15 +62,14 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 }
 
 func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	payment, err := h.paymentService.GetPaymentByID(id)
+	payment, err := h.paymentService.GetPaymentByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrPaymentNotFound) {
-			response.NotFound(c, "Payment not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to get payment", err)
+		c.Error(err)
 		return
 	}
 
@@ -78,37 +77,43 @@ func (h *PaymentHandler) GetPaymentByID(c *gin.Context) {
 }
 
 func (h *PaymentHandler) GetPaymentByOrderID(c *gin.Context) {
-	orderID := c.Param("order_id")
+	orderID, ok := uuidParam(c, "order_id")
+	if !ok {
+		return
+	}
 
-	payment, err := h.paymentService.GetPaymentByOrderID(orderID)
+	payment, err := h.paymentService.GetPaymentByOrderID(c.Request.Context(), orderID)
 	if err != nil {
-		if errors.Is(err, domain.ErrPaymentNotFound) {
-			response.NotFound(c, "Payment not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to get payment", err)
+		c.Error(err)
 		return
 	}
 
-	response.Success(c, "Payment retrieved sucessfully", dto.PaymentMapToResponse(payment))
+	response.Success(c, "Payment retrieved successfully", dto.PaymentMapToResponse(payment))
 }
 
 func (h *PaymentHandler) HandleNotification(c *gin.Context) {
 	var notification dto.PaymentNotification
 
+	ctx := c.Request.Context()
+
 	if err := c.ShouldBindJSON(&notification); err != nil {
-		log.Printf("Invalid notification body: %v", err)
-		response.BadRequest(c, "Invalid notification body", err)
+		h.logger.WarnContext(ctx, "Invalid notification body", slog.Any("error", err))
+		response.ValidationError(c, err)
 		return
 	}
 
-	log.Printf("Received payment notification for order: %s, status: %s",
-		notification.OrderID, notification.TransactionStatus)
+	h.logger.InfoContext(ctx, "Received payment notification",
+		slog.String("order_id", notification.OrderID),
+		slog.String("transaction_status", notification.TransactionStatus),
+	)
 
-	err := h.paymentService.HandleNotification(notification)
+	err := h.paymentService.HandleNotification(c.Request.Context(), notification)
 	if err != nil {
-		log.Printf("Failed to handle notification: %v", err)
-		response.InternalServerError(c, "Failed to process notification", err)
+		h.logger.ErrorContext(ctx, "Failed to handle notification",
+			slog.String("order_id", notification.OrderID),
+			slog.Any("error", err),
+		)
+		c.Error(err)
 		return
 	}
 
@@ -123,9 +128,9 @@ func (h *PaymentHandler) GetAllPayments(c *gin.Context) {
 		params.Limit = 10
 	}
 
-	payments, total, err := h.paymentService.GetAllPayments(params.Page, params.Limit)
+	payments, total, err := h.paymentService.GetAllPayments(c.Request.Context(), params.Page, params.Limit)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get payments", err)
+		c.Error(err)
 		return
 	}
 
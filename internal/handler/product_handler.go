@@ -1,9 +1,6 @@
 package handler
 
 import (
-	"errors"
-
-	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/service"
 	"github.com/affandisy/goshop/pkg/response"
@@ -29,13 +26,13 @@ func (h *ProductHandler) Create(c *gin.Context) {
 	var req dto.ProductRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	product, err := h.productService.Create(req)
+	product, err := h.productService.Create(c.Request.Context(), req)
 	if err != nil {
-		response.InternalServerError(c, "Failed to create product", err)
+		c.Error(err)
 		return
 	}
 
@@ -43,15 +40,14 @@ func (h *ProductHandler) Create(c *gin.Context) {
 }
 
 func (h *ProductHandler) GetByID(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	product, err := h.productService.GetByID(id)
+	product, err := h.productService.GetByID(c.Request.Context(), id, c.Query("currency"))
 	if err != nil {
-		if errors.Is(err, domain.ErrProductNotFound) {
-			response.NotFound(c, "Product not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to get product", err)
+		c.Error(err)
 		return
 	}
 
@@ -66,9 +62,9 @@ func (h *ProductHandler) List(c *gin.Context) {
 		query.Limit = 10
 	}
 
-	products, total, err := h.productService.List(query)
+	products, total, err := h.productService.List(c.Request.Context(), query)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get products", err)
+		c.Error(err)
 		return
 	}
 
@@ -77,21 +73,20 @@ func (h *ProductHandler) List(c *gin.Context) {
 }
 
 func (h *ProductHandler) Update(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
 	var req dto.ProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	product, err := h.productService.Update(id, req)
+	product, err := h.productService.Update(c.Request.Context(), id, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrProductNotFound) {
-			response.NotFound(c, "Product nof found")
-			return
-		}
-		response.InternalServerError(c, "Failed to update product", err)
+		c.Error(err)
 		return
 	}
 
@@ -99,15 +94,14 @@ func (h *ProductHandler) Update(c *gin.Context) {
 }
 
 func (h *ProductHandler) Delete(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
-	err := h.productService.Delete(id)
+	err := h.productService.Delete(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrProductNotFound) {
-			response.NotFound(c, "Product not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to delete product", err)
+		c.Error(err)
 		return
 	}
 
@@ -115,26 +109,166 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 }
 
 func (h *ProductHandler) UpdateStock(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
 
 	if err := c.ShouldBindJSON(&UpdateStockRequest); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	err := h.productService.UpdateStock(id, UpdateStockRequest.Quantity)
+	err := h.productService.UpdateStock(c.Request.Context(), id, UpdateStockRequest.Quantity)
 	if err != nil {
-		if errors.Is(err, domain.ErrProductNotFound) {
-			response.NotFound(c, "Product not found")
-			return
-		}
-		if errors.Is(err, domain.ErrInsufficientStock) {
-			response.BadRequest(c, "Insufficient stock", err)
-			return
-		}
-		response.InternalServerError(c, "Failed to update stock", err)
+		c.Error(err)
 		return
 	}
 
 	response.Success(c, "Stock updated successfully", nil)
 }
+
+func (h *ProductHandler) GetLowStock(c *gin.Context) {
+	var params utils.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		params.Page = 1
+		params.Limit = 10
+	}
+
+	products, total, err := h.productService.ListLowStock(c.Request.Context(), params.Page, params.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(params.Page, params.Limit, total, products)
+	response.Success(c, "Low stock products retrieved successfully", paginationResp)
+}
+
+func (h *ProductHandler) GetPriceRules(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	rules, err := h.productService.ListPriceRules(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Price rules retrieved successfully", rules)
+}
+
+func (h *ProductHandler) CreatePriceRule(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PriceRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	rule, err := h.productService.CreatePriceRule(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Price rule created successfully", rule)
+}
+
+func (h *ProductHandler) DeletePriceRule(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	ruleID, ok := uuidParam(c, "rule_id")
+	if !ok {
+		return
+	}
+
+	err := h.productService.DeletePriceRule(c.Request.Context(), id, ruleID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Price rule deleted successfully", nil)
+}
+
+func (h *ProductHandler) GetPriceHistory(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params utils.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		params.Page = 1
+		params.Limit = 10
+	}
+
+	history, total, err := h.productService.ListPriceHistory(c.Request.Context(), id, params.Page, params.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(params.Page, params.Limit, total, history)
+	response.Success(c, "Price history retrieved successfully", paginationResp)
+}
+
+func (h *ProductHandler) GetTranslations(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	translations, err := h.productService.ListTranslations(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translations retrieved successfully", translations)
+}
+
+func (h *ProductHandler) SetTranslation(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.TranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	translation, err := h.productService.SetTranslation(c.Request.Context(), id, c.Param("locale"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translation saved successfully", translation)
+}
+
+func (h *ProductHandler) DeleteTranslation(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.productService.DeleteTranslation(c.Request.Context(), id, c.Param("locale"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Translation deleted successfully", nil)
+}
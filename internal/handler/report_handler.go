@@ -3,6 +3,7 @@ package handler
 import (
 	"time"
 
+	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/service"
 	"github.com/affandisy/goshop/pkg/response"
 	"github.com/gin-gonic/gin"
@@ -17,16 +18,16 @@ func NewReportHandler(reportService service.ReportService) *ReportHandler {
 }
 
 func (h *ReportHandler) GenerateUsersReport(c *gin.Context) {
-	format := c.DefaultQuery("format", "pdf")
-
-	if format != "pdf" && format != "excel" {
-		response.BadRequest(c, "Invalid format. Use 'pdf' or 'excel'", nil)
+	var query dto.ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationError(c, err)
 		return
 	}
+	format := query.Format
 
-	data, filename, err := h.reportService.GenerateUsersReport(format)
+	data, filename, err := h.reportService.GenerateUsersReport(c.Request.Context(), format)
 	if err != nil {
-		response.InternalServerError(c, "Failed to generate report", nil)
+		c.Error(err)
 		return
 	}
 
@@ -41,16 +42,16 @@ func (h *ReportHandler) GenerateUsersReport(c *gin.Context) {
 }
 
 func (h *ReportHandler) GenerateProductsReport(c *gin.Context) {
-	format := c.DefaultQuery("format", "pdf")
-
-	if format != "pdf" && format != "excel" {
-		response.BadRequest(c, "Invalid format. Use 'pdf' or 'excel'", nil)
+	var query dto.ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationError(c, err)
 		return
 	}
+	format := query.Format
 
-	data, filename, err := h.reportService.GenerateProductsReport(format)
+	data, filename, err := h.reportService.GenerateProductsReport(c.Request.Context(), format)
 	if err != nil {
-		response.InternalServerError(c, "Failed to generate report", nil)
+		c.Error(err)
 		return
 	}
 
@@ -65,31 +66,42 @@ func (h *ReportHandler) GenerateProductsReport(c *gin.Context) {
 }
 
 func (h *ReportHandler) GenerateOrdersReport(c *gin.Context) {
-	format := c.DefaultQuery("format", "pdf")
-
-	if format != "pdf" && format != "excel" {
-		response.BadRequest(c, "Invalid format. Use 'pdf' or 'excel'", nil)
+	var query dto.OrdersReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationError(c, err)
 		return
 	}
+	format := query.Format
 
-	startDateStr := c.DefaultQuery("start_date", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
-	endDateStr := c.DefaultQuery("end_date", time.Now().Format("2006-01-02"))
+	startDate, endDate := query.Period(time.Now())
 
-	startDate, err := time.Parse("2006-01-02", startDateStr)
+	data, filename, err := h.reportService.GenerateOrdersReport(c.Request.Context(), startDate, endDate, format)
 	if err != nil {
-		response.BadRequest(c, "Invalid start_date format. Use YYYY-MM-DD", err)
+		c.Error(err)
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		response.BadRequest(c, "Invalid end_date format. Use YYYY-MM-DD", err)
+	contentType := "application/pdf"
+	if format == "excel" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(200, contentType, data)
+}
+
+func (h *ReportHandler) GenerateLowStockReport(c *gin.Context) {
+	var query dto.ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationError(c, err)
 		return
 	}
+	format := query.Format
 
-	data, filename, err := h.reportService.GenerateOrdersReport(startDate, endDate, format)
+	data, filename, err := h.reportService.GenerateLowStockReport(c.Request.Context(), format)
 	if err != nil {
-		response.InternalServerError(c, "Failed to generate report", nil)
+		c.Error(err)
 		return
 	}
 
@@ -124,6 +136,12 @@ func (h *ReportHandler) GetReportTypes(c *gin.Context) {
 			"formats":     "pdf, excel",
 			"parameters":  "start_date, end_date",
 		},
+		{
+			"name":        "Low Stock Report",
+			"endpoint":    "/api/v1/reports/low-stock",
+			"description": "Report of active products at or below their reorder level",
+			"formats":     "pdf, excel",
+		},
 	}
 
 	response.Success(c, "Available reports", reportTypes)
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/middleware"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	reviewService service.ReviewService
+}
+
+func NewReviewHandler(reviewService service.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+func (h *ReviewHandler) Create(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	review, err := h.reviewService.Create(c.Request.Context(), userID, id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Review submitted and awaiting moderation", review)
+}
+
+func (h *ReviewHandler) GetProductReviews(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params utils.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		params.Page = 1
+		params.Limit = 10
+	}
+
+	reviews, total, err := h.reviewService.ListProductReviews(c.Request.Context(), id, params.Page, params.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(params.Page, params.Limit, total, reviews)
+	response.Success(c, "Reviews retrieved successfully", paginationResp)
+}
+
+func (h *ReviewHandler) GetAll(c *gin.Context) {
+	var query dto.ReviewQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		query.Page = 1
+		query.Limit = 10
+	}
+
+	reviews, total, err := h.reviewService.List(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(query.Page, query.Limit, total, reviews)
+	response.Success(c, "Reviews retrieved successfully", paginationResp)
+}
+
+func (h *ReviewHandler) Approve(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	review, err := h.reviewService.Approve(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Review approved successfully", review)
+}
+
+func (h *ReviewHandler) Hide(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	review, err := h.reviewService.Hide(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Review hidden successfully", review)
+}
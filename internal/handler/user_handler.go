@@ -1,9 +1,6 @@
 package handler
 
 import (
-	"errors"
-
-	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/middleware"
 	"github.com/affandisy/goshop/internal/service"
@@ -24,18 +21,13 @@ func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.UserRegisterRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	user, err := h.userService.Register(req)
+	user, err := h.userService.Register(c.Request.Context(), req)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserAlreadyExists) {
-			response.BadRequest(c, "Email already registered", err)
-			return
-		}
-
-		response.InternalServerError(c, "Failed to register user", err)
+		c.Error(err)
 		return
 	}
 
@@ -46,21 +38,13 @@ func (h *UserHandler) Login(c *gin.Context) {
 	var req dto.UserLoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	user, token, err := h.userService.Login(req)
+	user, token, err := h.userService.Login(c.Request.Context(), req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidCredentials) {
-			response.Unauthorized(c, "Invalid email or password")
-			return
-		}
-		if errors.Is(err, domain.ErrUnauthorized) {
-			response.Unauthorized(c, "Unauthorized access")
-			return
-		}
-		response.InternalServerError(c, "Failed to login", err)
+		c.Error(err)
 		return
 	}
 
@@ -77,14 +61,9 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetProfile(userID)
+	user, err := h.userService.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			response.NotFound(c, "User not found")
-			return
-		}
-
-		response.InternalServerError(c, "Failed to get user profile", err)
+		c.Error(err)
 		return
 	}
 
@@ -100,17 +79,13 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	var req dto.UserRegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err)
+		response.ValidationError(c, err)
 		return
 	}
 
-	user, err := h.userService.UpdateProfile(userID, req)
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			response.NotFound(c, "User not found")
-			return
-		}
-		response.InternalServerError(c, "Failed to update profile", err)
+		c.Error(err)
 		return
 	}
 
@@ -124,9 +99,9 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		params.Limit = 10
 	}
 
-	users, total, err := h.userService.GetUsers(params.Page, params.Limit)
+	users, total, err := h.userService.GetUsers(c.Request.Context(), params.Page, params.Limit)
 	if err != nil {
-		response.InternalServerError(c, "Failed to get users", err)
+		c.Error(err)
 		return
 	}
 
@@ -139,3 +114,96 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 
 	response.Success(c, "Users retrieved successfully", paginationResp)
 }
+
+func (h *UserHandler) GetLoginAttempts(c *gin.Context) {
+	var query dto.LoginAttemptQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		query.Page = 1
+		query.Limit = 10
+	}
+
+	attempts, total, err := h.userService.GetLoginAttempts(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(query.Page, query.Limit, total, attempts)
+	response.Success(c, "Login attempts retrieved successfully", paginationResp)
+}
+
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UnlockUser(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "User unlocked successfully", dto.UserMapToResponse(user))
+}
+
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	user, err := h.userService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Email verified successfully", dto.UserMapToResponse(user))
+}
+
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dto.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.userService.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "If the email is registered and unverified, a verification link has been sent", nil)
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.userService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	if err := h.userService.ResetPassword(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Password reset successfully", nil)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// skuPattern matches SKUs such as MBP-16-M3MAX-1TB.
+	skuPattern = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+	// idPhonePattern matches Indonesian mobile numbers in the 08, 628 or
+	// +628 forms.
+	idPhonePattern = regexp.MustCompile(`^(\+62|62|0)8[1-9][0-9]{6,10}$`)
+)
+
+// RegisterValidators lets binding tags such as required and gt=0 check the
+// amount of domain.Money fields, adds the sku and id_phone tags, and names
+// fields in validation errors by their json, form or uri names.
+func RegisterValidators() error {
+	v := validate()
+	if v == nil {
+		return errors.New("unexpected binding validator engine")
+	}
+
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if m, ok := field.Interface().(domain.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, domain.Money{})
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	if err := v.RegisterValidation("sku", matches(skuPattern)); err != nil {
+		return err
+	}
+	return v.RegisterValidation("id_phone", matches(idPhonePattern))
+}
+
+func validate() *validator.Validate {
+	v, _ := binding.Validator.Engine().(*validator.Validate)
+	return v
+}
+
+func matches(pattern *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return pattern.MatchString(fl.Field().String())
+	}
+}
+
+// uuidParam returns the path parameter name. When it is not a UUID it
+// responds with a validation error and returns false.
+func uuidParam(c *gin.Context, name string) (string, bool) {
+	value := c.Param(name)
+	if err := validate().Var(value, "uuid"); err != nil {
+		response.InvalidParam(c, name, "uuid")
+		return "", false
+	}
+	return value, true
+}
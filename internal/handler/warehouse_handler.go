@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/middleware"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseHandler struct {
+	inventoryService service.InventoryService
+}
+
+func NewWarehouseHandler(inventoryService service.InventoryService) *WarehouseHandler {
+	return &WarehouseHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+func (h *WarehouseHandler) Create(c *gin.Context) {
+	var req dto.WarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	warehouse, err := h.inventoryService.CreateWarehouse(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Warehouse created successfully", warehouse)
+}
+
+func (h *WarehouseHandler) GetAll(c *gin.Context) {
+	warehouses, err := h.inventoryService.ListWarehouses(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Warehouses retrieved successfully", warehouses)
+}
+
+func (h *WarehouseHandler) GetByID(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	warehouse, err := h.inventoryService.GetWarehouse(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Warehouse retrieved successfully", warehouse)
+}
+
+func (h *WarehouseHandler) Update(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.WarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	warehouse, err := h.inventoryService.UpdateWarehouse(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Warehouse updated successfully", warehouse)
+}
+
+func (h *WarehouseHandler) Delete(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.inventoryService.DeleteWarehouse(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Warehouse deleted successfully", nil)
+}
+
+func (h *WarehouseHandler) GetInventory(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var params utils.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		params.Page = 1
+		params.Limit = 10
+	}
+
+	levels, total, err := h.inventoryService.ListWarehouseInventory(c.Request.Context(), id, params.Page, params.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(params.Page, params.Limit, total, levels)
+	response.Success(c, "Warehouse inventory retrieved successfully", paginationResp)
+}
+
+func (h *WarehouseHandler) AdjustStock(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.AdjustInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	levels, err := h.inventoryService.AdjustStock(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Stock updated successfully", levels)
+}
+
+func (h *WarehouseHandler) GetProductInventory(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	levels, err := h.inventoryService.ListProductInventory(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Product inventory retrieved successfully", levels)
+}
+
+func (h *WarehouseHandler) Transfer(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	transfer, err := h.inventoryService.Transfer(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Stock transferred successfully", transfer)
+}
+
+func (h *WarehouseHandler) GetTransfers(c *gin.Context) {
+	var query dto.StockTransferQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		query.Page = 1
+		query.Limit = 10
+	}
+
+	transfers, total, err := h.inventoryService.ListTransfers(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(query.Page, query.Limit, total, transfers)
+	response.Success(c, "Stock transfers retrieved successfully", paginationResp)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dto.WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	endpoint, err := h.webhookService.CreateEndpoint(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Webhook endpoint created successfully", dto.WebhookEndpointSecretResponse{
+		WebhookEndpoint: endpoint,
+		Secret:          endpoint.Secret,
+	})
+}
+
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	endpoints, err := h.webhookService.ListEndpoints(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Webhook endpoints retrieved successfully", endpoints)
+}
+
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhookService.GetEndpoint(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Webhook endpoint retrieved successfully", endpoint)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Request.Context(), id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Webhook endpoint updated successfully", endpoint)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Webhook endpoint deleted successfully", nil)
+}
+
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhookService.RotateSecret(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Webhook secret rotated successfully", dto.WebhookEndpointSecretResponse{
+		WebhookEndpoint: endpoint,
+		Secret:          endpoint.Secret,
+	})
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query dto.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		query.Page = 1
+		query.Limit = 10
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), id, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(query.Page, query.Limit, total, deliveries)
+	response.Success(c, "Webhook deliveries retrieved successfully", paginationResp)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := uuidParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Webhook redelivery queued", delivery)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/middleware"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/pkg/response"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandler struct {
+	wishlistService service.WishlistService
+}
+
+func NewWishlistHandler(wishlistService service.WishlistService) *WishlistHandler {
+	return &WishlistHandler{wishlistService: wishlistService}
+}
+
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var params utils.PaginationParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		params.Page = 1
+		params.Limit = 10
+	}
+
+	items, total, err := h.wishlistService.List(c.Request.Context(), userID, params.Page, params.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	paginationResp := utils.CreatePaginationResponse(params.Page, params.Limit, total, items)
+	response.Success(c, "Wishlist retrieved successfully", paginationResp)
+}
+
+func (h *WishlistHandler) Add(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req dto.WishlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err)
+		return
+	}
+
+	item, err := h.wishlistService.Add(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Created(c, "Product added to wishlist", item)
+}
+
+func (h *WishlistHandler) Remove(c *gin.Context) {
+	productID, ok := uuidParam(c, "product_id")
+	if !ok {
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.wishlistService.Remove(c.Request.Context(), userID, productID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, "Product removed from wishlist", nil)
+}
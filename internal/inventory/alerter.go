@@ -0,0 +1,61 @@
+package inventory
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/notification"
+)
+
+// Alerter is told about products that have dropped to their reorder level.
+type Alerter interface {
+	LowStock(ctx context.Context, product *domain.Product) error
+}
+
+type notifierAlerter struct {
+	notifier   notification.Notifier
+	recipients []notification.Recipient
+}
+
+// NewNotifierAlerter sends low-stock alerts to recipients through the
+// notification channels.
+func NewNotifierAlerter(notifier notification.Notifier, emails []string) Alerter {
+	recipients := make([]notification.Recipient, 0, len(emails))
+	for _, email := range emails {
+		recipients = append(recipients, notification.Recipient{Name: "GoShop Admin", Email: email})
+	}
+	return &notifierAlerter{notifier: notifier, recipients: recipients}
+}
+
+func (a *notifierAlerter) LowStock(ctx context.Context, product *domain.Product) error {
+	for _, recipient := range a.recipients {
+		a.notifier.Notify(ctx, notification.Event{
+			Type:      notification.EventLowStock,
+			Recipient: recipient,
+			Product:   product,
+		})
+	}
+	return nil
+}
+
+type logAlerter struct {
+	logger *slog.Logger
+}
+
+// NewLogAlerter writes low-stock alerts to the log. It is used when no
+// recipients are configured.
+func NewLogAlerter(logger *slog.Logger) Alerter {
+	return &logAlerter{logger: logger}
+}
+
+func (a *logAlerter) LowStock(ctx context.Context, product *domain.Product) error {
+	a.logger.WarnContext(ctx, "Low stock",
+		slog.String("product_id", product.ID),
+		slog.String("sku", product.SKU),
+		slog.Int("stock", product.Stock),
+		slog.Int("reorder_level", product.ReorderLevel),
+		slog.Int("reorder_quantity", product.ReorderQuantity),
+	)
+	return nil
+}
@@ -0,0 +1,221 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
+
+type Strategy string
+
+const (
+	// StrategyPriority fills each line from warehouses in priority order.
+	StrategyPriority Strategy = "priority"
+	// StrategyNearest prefers warehouses in the destination city, then
+	// province, then falls back to priority.
+	StrategyNearest Strategy = "nearest"
+	// StrategyFewestSplits minimises the number of warehouses an order
+	// ships from.
+	StrategyFewestSplits Strategy = "fewest_splits"
+)
+
+// Line is a quantity of a product to allocate.
+type Line struct {
+	ProductID string
+	Quantity  int
+}
+
+// Destination is where the order ships to.
+type Destination struct {
+	City     string
+	Province string
+}
+
+// Allocator decides which warehouses fulfil an order. levels holds the
+// available stock of the ordered products in active warehouses, with
+// Warehouse loaded.
+type Allocator interface {
+	Allocate(lines []Line, levels []domain.InventoryLevel, dest Destination) ([]domain.OrderAllocation, error)
+}
+
+func NewAllocator(strategy Strategy) (Allocator, error) {
+	switch strategy {
+	case StrategyPriority:
+		return greedyAllocator{rank: byPriority}, nil
+	case StrategyNearest:
+		return greedyAllocator{rank: byDistance}, nil
+	case StrategyFewestSplits:
+		return fewestSplitsAllocator{}, nil
+	}
+	return nil, fmt.Errorf("unknown allocation strategy %q", strategy)
+}
+
+// stockTable tracks what is left to allocate while an order is split up.
+type stockTable struct {
+	warehouses []*domain.Warehouse
+	available  map[string]map[string]int // warehouse ID -> product ID -> quantity
+}
+
+func newStockTable(levels []domain.InventoryLevel) *stockTable {
+	t := &stockTable{available: map[string]map[string]int{}}
+	for _, level := range levels {
+		if level.Warehouse == nil {
+			continue
+		}
+		if _, ok := t.available[level.WarehouseID]; !ok {
+			t.available[level.WarehouseID] = map[string]int{}
+			t.warehouses = append(t.warehouses, level.Warehouse)
+		}
+		t.available[level.WarehouseID][level.ProductID] += level.Available()
+	}
+	return t
+}
+
+func (t *stockTable) take(warehouseID, productID string, quantity int) domain.OrderAllocation {
+	t.available[warehouseID][productID] -= quantity
+	return domain.OrderAllocation{ProductID: productID, WarehouseID: warehouseID, Quantity: quantity}
+}
+
+// rankFunc orders warehouses from most to least preferred.
+type rankFunc func(warehouses []*domain.Warehouse, dest Destination)
+
+func byPriority(warehouses []*domain.Warehouse, _ Destination) {
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		if warehouses[i].Priority != warehouses[j].Priority {
+			return warehouses[i].Priority < warehouses[j].Priority
+		}
+		return warehouses[i].Code < warehouses[j].Code
+	})
+}
+
+// byDistance approximates distance from the address, as warehouses and
+// orders carry no coordinates.
+func byDistance(warehouses []*domain.Warehouse, dest Destination) {
+	distance := func(w *domain.Warehouse) int {
+		switch {
+		case dest.City != "" && strings.EqualFold(w.City, dest.City):
+			return 0
+		case dest.Province != "" && strings.EqualFold(w.Province, dest.Province):
+			return 1
+		}
+		return 2
+	}
+
+	byPriority(warehouses, dest)
+	sort.SliceStable(warehouses, func(i, j int) bool {
+		return distance(warehouses[i]) < distance(warehouses[j])
+	})
+}
+
+type greedyAllocator struct {
+	rank rankFunc
+}
+
+func (a greedyAllocator) Allocate(lines []Line, levels []domain.InventoryLevel, dest Destination) ([]domain.OrderAllocation, error) {
+	table := newStockTable(levels)
+	a.rank(table.warehouses, dest)
+
+	var allocations []domain.OrderAllocation
+	for _, line := range lines {
+		split, err := splitLine(table, table.warehouses, line)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, split...)
+	}
+
+	return allocations, nil
+}
+
+type fewestSplitsAllocator struct{}
+
+// Allocate repeatedly ships from the warehouse that can fully cover the
+// most remaining lines. Lines no single warehouse can cover are split
+// across the fullest warehouses.
+func (fewestSplitsAllocator) Allocate(lines []Line, levels []domain.InventoryLevel, dest Destination) ([]domain.OrderAllocation, error) {
+	table := newStockTable(levels)
+	byPriority(table.warehouses, dest)
+
+	var allocations []domain.OrderAllocation
+	remaining := append([]Line(nil), lines...)
+
+	for len(remaining) > 0 {
+		var best *domain.Warehouse
+		bestCovered := 0
+		for _, w := range table.warehouses {
+			if covered := coveredLines(table, w.ID, remaining); covered > bestCovered {
+				best, bestCovered = w, covered
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		var rest []Line
+		for _, line := range remaining {
+			if table.available[best.ID][line.ProductID] >= line.Quantity {
+				allocations = append(allocations, table.take(best.ID, line.ProductID, line.Quantity))
+			} else {
+				rest = append(rest, line)
+			}
+		}
+		remaining = rest
+	}
+
+	for _, line := range remaining {
+		fullest := append([]*domain.Warehouse(nil), table.warehouses...)
+		sort.SliceStable(fullest, func(i, j int) bool {
+			return table.available[fullest[i].ID][line.ProductID] > table.available[fullest[j].ID][line.ProductID]
+		})
+
+		split, err := splitLine(table, fullest, line)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, split...)
+	}
+
+	return allocations, nil
+}
+
+// coveredLines counts the lines warehouseID can ship in full, given that
+// lines for the same product compete for the same stock.
+func coveredLines(table *stockTable, warehouseID string, lines []Line) int {
+	used := map[string]int{}
+	covered := 0
+	for _, line := range lines {
+		if table.available[warehouseID][line.ProductID]-used[line.ProductID] >= line.Quantity {
+			used[line.ProductID] += line.Quantity
+			covered++
+		}
+	}
+	return covered
+}
+
+// splitLine takes line from warehouses in order until it is filled.
+func splitLine(table *stockTable, warehouses []*domain.Warehouse, line Line) ([]domain.OrderAllocation, error) {
+	var allocations []domain.OrderAllocation
+	needed := line.Quantity
+
+	for _, w := range warehouses {
+		if needed == 0 {
+			break
+		}
+		available := table.available[w.ID][line.ProductID]
+		if available <= 0 {
+			continue
+		}
+
+		quantity := min(available, needed)
+		allocations = append(allocations, table.take(w.ID, line.ProductID, quantity))
+		needed -= quantity
+	}
+
+	if needed > 0 {
+		return nil, domain.ErrInsufficientStock
+	}
+
+	return allocations, nil
+}
@@ -0,0 +1,186 @@
+package inventory
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// Manager moves stock between orders and warehouses and keeps
+// Product.Stock and Product.Reserved in sync. Its methods must run inside a
+// transaction.
+type Manager interface {
+	// Reserve allocates the lines from available warehouse stock, choosing
+	// warehouses with the configured strategy, and holds it for the order
+	// until expiresAt.
+	Reserve(ctx context.Context, orderID string, lines []Line, dest Destination, expiresAt time.Time) ([]domain.OrderAllocation, error)
+	// Commit takes the stock held for the order for good.
+	Commit(ctx context.Context, orderID string) ([]domain.StockReservation, error)
+	// Release frees the stock held for the order.
+	Release(ctx context.Context, orderID string) ([]domain.StockReservation, error)
+	// Restock puts committed stock back into the warehouses it was
+	// allocated from.
+	Restock(ctx context.Context, allocations []domain.OrderAllocation) error
+	// Adjust changes the stock of a product in a warehouse, or in the
+	// default warehouse when warehouseID is empty.
+	Adjust(ctx context.Context, productID, warehouseID string, delta int) error
+	// Lock locks the product's stock in every warehouse until the
+	// transaction ends. Lock it before the product row.
+	Lock(ctx context.Context, productID string) error
+	// Sync recomputes Product.Stock and Product.Reserved for the product.
+	Sync(ctx context.Context, productID string) error
+}
+
+type manager struct {
+	inventoryRepo   repository.InventoryRepository
+	warehouseRepo   repository.WarehouseRepository
+	reservationRepo repository.StockReservationRepository
+	allocator       Allocator
+}
+
+func NewManager(inventoryRepo repository.InventoryRepository, warehouseRepo repository.WarehouseRepository, reservationRepo repository.StockReservationRepository, allocator Allocator) Manager {
+	return &manager{inventoryRepo: inventoryRepo, warehouseRepo: warehouseRepo, reservationRepo: reservationRepo, allocator: allocator}
+}
+
+func (m *manager) Reserve(ctx context.Context, orderID string, lines []Line, dest Destination, expiresAt time.Time) ([]domain.OrderAllocation, error) {
+	productIDs := lineProductIDs(lines)
+
+	levels, err := m.inventoryRepo.LockAvailable(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := m.allocator.Allocate(lines, levels, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := make([]domain.StockReservation, 0, len(allocations))
+	for _, allocation := range allocations {
+		if err := m.inventoryRepo.Hold(ctx, allocation.ProductID, allocation.WarehouseID, allocation.Quantity); err != nil {
+			return nil, err
+		}
+
+		reservations = append(reservations, domain.StockReservation{
+			OrderID:     orderID,
+			ProductID:   allocation.ProductID,
+			WarehouseID: allocation.WarehouseID,
+			Quantity:    allocation.Quantity,
+			Status:      domain.ReservationStatusActive,
+			ExpiresAt:   expiresAt,
+		})
+	}
+
+	if err := m.reservationRepo.Create(ctx, reservations); err != nil {
+		return nil, err
+	}
+
+	if err := m.inventoryRepo.SyncProductStock(ctx, productIDs); err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+func (m *manager) Commit(ctx context.Context, orderID string) ([]domain.StockReservation, error) {
+	return m.settle(ctx, orderID, domain.ReservationStatusCommitted)
+}
+
+func (m *manager) Release(ctx context.Context, orderID string) ([]domain.StockReservation, error) {
+	return m.settle(ctx, orderID, domain.ReservationStatusReleased)
+}
+
+// settle ends the order's active reservations. Committing also removes the
+// held stock from the warehouses.
+func (m *manager) settle(ctx context.Context, orderID string, status domain.ReservationStatus) ([]domain.StockReservation, error) {
+	reservations, err := m.reservationRepo.LockActiveByOrder(ctx, orderID)
+	if err != nil || len(reservations) == 0 {
+		return nil, err
+	}
+
+	var ids, productIDs []string
+	seen := map[string]bool{}
+
+	for _, reservation := range reservations {
+		if err := m.inventoryRepo.Hold(ctx, reservation.ProductID, reservation.WarehouseID, -reservation.Quantity); err != nil {
+			return nil, err
+		}
+
+		if status == domain.ReservationStatusCommitted {
+			if err := m.inventoryRepo.Adjust(ctx, reservation.ProductID, reservation.WarehouseID, -reservation.Quantity); err != nil {
+				return nil, err
+			}
+		}
+
+		ids = append(ids, reservation.ID)
+		if !seen[reservation.ProductID] {
+			seen[reservation.ProductID] = true
+			productIDs = append(productIDs, reservation.ProductID)
+		}
+	}
+
+	if err := m.reservationRepo.UpdateStatus(ctx, ids, status); err != nil {
+		return nil, err
+	}
+
+	if err := m.inventoryRepo.SyncProductStock(ctx, productIDs); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (m *manager) Restock(ctx context.Context, allocations []domain.OrderAllocation) error {
+	var productIDs []string
+	seen := map[string]bool{}
+
+	for _, allocation := range allocations {
+		if err := m.inventoryRepo.Adjust(ctx, allocation.ProductID, allocation.WarehouseID, allocation.Quantity); err != nil {
+			return err
+		}
+		if !seen[allocation.ProductID] {
+			seen[allocation.ProductID] = true
+			productIDs = append(productIDs, allocation.ProductID)
+		}
+	}
+
+	return m.inventoryRepo.SyncProductStock(ctx, productIDs)
+}
+
+func (m *manager) Adjust(ctx context.Context, productID, warehouseID string, delta int) error {
+	if warehouseID == "" {
+		warehouse, err := m.warehouseRepo.GetDefault(ctx)
+		if err != nil {
+			return err
+		}
+		warehouseID = warehouse.ID
+	}
+
+	if err := m.inventoryRepo.Adjust(ctx, productID, warehouseID, delta); err != nil {
+		return err
+	}
+
+	return m.inventoryRepo.SyncProductStock(ctx, []string{productID})
+}
+
+func (m *manager) Lock(ctx context.Context, productID string) error {
+	return m.inventoryRepo.LockByProduct(ctx, productID)
+}
+
+func (m *manager) Sync(ctx context.Context, productID string) error {
+	return m.inventoryRepo.SyncProductStock(ctx, []string{productID})
+}
+
+func lineProductIDs(lines []Line) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, line := range lines {
+		if !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			ids = append(ids, line.ProductID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,107 @@
+package inventory
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
+)
+
+type MonitorConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// LowStockMonitor periodically alerts on products that have crossed their
+// reorder level. Each product is alerted once per crossing: the alert is
+// re-armed when it is restocked above the level.
+type LowStockMonitor struct {
+	transactor  repository.Transactor
+	productRepo repository.ProductRepository
+	alerter     Alerter
+	cfg         MonitorConfig
+	logger      *slog.Logger
+}
+
+func NewLowStockMonitor(transactor repository.Transactor, productRepo repository.ProductRepository, alerter Alerter, cfg MonitorConfig, logger *slog.Logger) *LowStockMonitor {
+	return &LowStockMonitor{
+		transactor:  transactor,
+		productRepo: productRepo,
+		alerter:     alerter,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+// Run checks stock levels every interval until ctx is cancelled.
+func (m *LowStockMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Check(ctx); err != nil && ctx.Err() == nil {
+			m.logger.ErrorContext(ctx, "Low stock check failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check re-arms restocked products and alerts on newly low ones.
+func (m *LowStockMonitor) Check(ctx context.Context) error {
+	if _, err := m.productRepo.ResetLowStockAlerts(ctx); err != nil {
+		return err
+	}
+
+	for {
+		products, err := m.claim(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range products {
+			if err := m.alerter.LowStock(ctx, &products[i]); err != nil {
+				m.logger.ErrorContext(ctx, "Failed to send low stock alert",
+					slog.String("product_id", products[i].ID),
+					slog.Any("error", err),
+				)
+				continue
+			}
+			metrics.LowStockAlertsTotal.Inc()
+		}
+
+		if len(products) < m.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+// claim marks a batch of low-stock products as alerted, so concurrent
+// instances never alert on the same crossing twice.
+func (m *LowStockMonitor) claim(ctx context.Context) ([]domain.Product, error) {
+	var products []domain.Product
+
+	err := m.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		products, err = m.productRepo.FetchLowStockToAlert(ctx, m.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+
+		return m.productRepo.MarkLowStockAlerted(ctx, ids, time.Now())
+	})
+
+	return products, err
+}
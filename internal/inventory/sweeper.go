@@ -0,0 +1,78 @@
+package inventory
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
+)
+
+type SweeperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// OrderExpirer releases the stock held for an unpaid order and cancels it.
+type OrderExpirer interface {
+	ExpireOrder(ctx context.Context, orderID string) error
+}
+
+// ReservationSweeper periodically expires orders whose stock reservations
+// have run out.
+type ReservationSweeper struct {
+	reservationRepo repository.StockReservationRepository
+	expirer         OrderExpirer
+	cfg             SweeperConfig
+	logger          *slog.Logger
+}
+
+func NewReservationSweeper(reservationRepo repository.StockReservationRepository, expirer OrderExpirer, cfg SweeperConfig, logger *slog.Logger) *ReservationSweeper {
+	return &ReservationSweeper{
+		reservationRepo: reservationRepo,
+		expirer:         expirer,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+// Run sweeps expired reservations every interval until ctx is cancelled.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Reservation sweep failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep expires one batch of orders. An order that fails is retried on the
+// next sweep.
+func (s *ReservationSweeper) Sweep(ctx context.Context) error {
+	orderIDs, err := s.reservationRepo.ListExpiredOrderIDs(ctx, time.Now(), s.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, orderID := range orderIDs {
+		if err := s.expirer.ExpireOrder(ctx, orderID); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to expire order",
+				slog.String("order_id", orderID),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		metrics.ReservationsExpiredTotal.Inc()
+	}
+
+	return nil
+}
@@ -1,25 +1,83 @@
 package middleware
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/pkg/response"
 	"github.com/gin-gonic/gin"
 )
 
+var statusByKind = map[domain.ErrorKind]int{
+	domain.KindInvalid:      http.StatusBadRequest,
+	domain.KindUnauthorized: http.StatusUnauthorized,
+	domain.KindForbidden:    http.StatusForbidden,
+	domain.KindNotFound:     http.StatusNotFound,
+	domain.KindConflict:     http.StatusConflict,
+	domain.KindRateLimited:  http.StatusTooManyRequests,
+}
+
+// ErrorHandler responds to requests whose handler added an error with
+// c.Error and did not respond itself. Domain errors get the status of their
+// kind and their code; anything else is a 500 whose cause is only logged.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := recover(); err != nil {
-			log.Printf("PANIC: %v", err)
+		c.Next()
 
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"message": "Internal server error",
-				"error":   "Unexpected Error",
-			})
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
 
-			c.Abort()
+		err := c.Errors.Last().Err
+
+		var domainErr *domain.Error
+		status, ok := 0, false
+		if errors.As(err, &domainErr) {
+			status, ok = statusByKind[domainErr.Kind]
+		}
+		if !ok {
+			response.Fail(c, http.StatusInternalServerError, "internal_error", "Internal server error", "")
+			return
+		}
+
+		// Only wrapped errors say more than the message for the code.
+		detail := ""
+		if err.Error() != domainErr.Message {
+			detail = err.Error()
 		}
+		response.Fail(c, status, domainErr.Code, "error."+domainErr.Code, detail)
+	}
+}
+
+// Recovery responds 500 to requests whose handler panicked and logs the
+// panic with its stack trace.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.Any("panic", rec),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("stack", string(debug.Stack())),
+			)
+
+			if !c.Writer.Written() {
+				response.Fail(c, http.StatusInternalServerError, "internal_error", "Internal server error", "")
+			}
+			c.Abort()
+		}()
+
 		c.Next()
 	}
 }
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type IdempotencyPolicy struct {
+	Name        string        // scopes keys, e.g. "create_order"
+	TTL         time.Duration // how long a response is replayed
+	LockTimeout time.Duration // how long an unfinished request holds its key
+}
+
+// IdempotencyMiddleware lets clients retry a request safely by sending the
+// same Idempotency-Key header. The first successful response for a key is
+// stored and replayed to identical retries; reusing the key with a different
+// request is a conflict. Failed requests are not stored, so they can be
+// retried with the same key.
+//
+// Keys are scoped to the caller, so it must run after AuthMiddleware where
+// there is one. Guests are told apart by client IP, so one guest can't have
+// another's response, and the order access token in it, replayed to them.
+// If the store fails the request goes through unprotected, as the rate
+// limiter does.
+func IdempotencyMiddleware(store idempotency.Store, policy IdempotencyPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(IdempotencyKeyHeader)
+		if store == nil || header == "" {
+			c.Next()
+			return
+		}
+
+		if !validIdempotencyKey(header) {
+			c.Error(domain.ErrInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := policy.Name + ":" + idempotencyCaller(c) + ":" + header
+
+		record, claimed, err := store.Claim(ctx, key, fingerprint, policy.LockTimeout)
+		if err != nil {
+			slog.WarnContext(ctx, "Idempotency store unavailable",
+				slog.String("policy", policy.Name),
+				slog.Any("error", err),
+			)
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.Error(domain.ErrIdempotencyKeyReused)
+			case !record.Done:
+				c.Header("Retry-After", "1")
+				c.Error(domain.ErrIdempotencyKeyInUse)
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(record.Status, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Deferred so the key is also released when the handler panics.
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+				slog.WarnContext(ctx, "Failed to release idempotency key",
+					slog.String("policy", policy.Name),
+					slog.Any("error", err),
+				)
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if !recorder.Written() || status < 200 || status >= 300 {
+			return
+		}
+
+		err = store.Save(context.WithoutCancel(ctx), key, idempotency.Record{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}, policy.TTL)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to save idempotent response",
+				slog.String("policy", policy.Name),
+				slog.Any("error", err),
+			)
+			return
+		}
+		saved = true
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func idempotencyCaller(c *gin.Context) string {
+	if userID, err := GetUserID(c); err == nil {
+		return "user:" + userID
+	}
+	return "guest:" + c.ClientIP()
+}
+
+// requestFingerprint hashes the method, URL and body. The body is restored
+// so the handler can still bind it.
+func requestFingerprint(c *gin.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	io.WriteString(h, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// bodyRecorder keeps a copy of the response body as it is written.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/pkg/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+func newGuestCheckout(t *testing.T) (*gin.Engine, *int) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	orders := 0
+	policy := IdempotencyPolicy{Name: "create_order", TTL: time.Hour, LockTimeout: time.Minute}
+	router.POST("/orders", IdempotencyMiddleware(idempotency.NewMemoryStore(), policy), func(c *gin.Context) {
+		orders++
+		c.JSON(http.StatusCreated, gin.H{"order": orders})
+	})
+
+	return router, &orders
+}
+
+func guestOrder(router *gin.Engine, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"email":"guest@example.com"}`))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(IdempotencyKeyHeader, "checkout-1")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysGuestRetry(t *testing.T) {
+	router, orders := newGuestCheckout(t)
+
+	first := guestOrder(router, "192.0.2.1:1234")
+	retry := guestOrder(router, "192.0.2.1:5678")
+
+	if *orders != 1 {
+		t.Errorf("handler ran %d times, want 1", *orders)
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want the first response replayed", retry.Code, retry.Body)
+	}
+}
+
+func TestIdempotencyScopesGuestsByClient(t *testing.T) {
+	router, orders := newGuestCheckout(t)
+
+	guestOrder(router, "192.0.2.1:1234")
+	other := guestOrder(router, "198.51.100.7:1234")
+
+	if *orders != 2 {
+		t.Errorf("handler ran %d times, want once per guest", *orders)
+	}
+	if other.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("another guest's response was replayed")
+	}
+}
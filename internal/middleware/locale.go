@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/affandisy/goshop/pkg/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware resolves the locale from the lang query parameter or the
+// Accept-Language header, falling back to defaultLocale, and stores it on
+// the request context.
+func LocaleMiddleware(defaultLocale string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Resolve(c.Query("lang"), c.GetHeader("Accept-Language"), defaultLocale)
+
+		c.Set("locale", locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+
+		c.Next()
+	}
+}
+
+func GetLocale(c *gin.Context) string {
+	return c.GetString("locale")
+}
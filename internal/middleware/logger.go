@@ -1,31 +1,41 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func LoggerMiddleware() gin.HandlerFunc {
+func LoggerMiddleware(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		startTime := time.Now()
 
 		c.Next()
 
-		latency := time.Since(startTime)
-
 		statusCode := c.Writer.Status()
-		method := c.Request.Method
-		path := c.Request.URL.Path
-		clientIP := c.ClientIP()
-
-		log.Printf("[%s] %s | %d | %v | %s",
-			method,
-			path,
-			statusCode,
-			latency,
-			clientIP,
-		)
+
+		level := slog.LevelInfo
+		switch {
+		case statusCode >= 500:
+			level = slog.LevelError
+		case statusCode >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", statusCode),
+			slog.Duration("latency", time.Since(startTime)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Err.Error()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "http request", attrs...)
 	}
 }
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/affandisy/goshop/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		// Use the route template (/products/:id) rather than the raw path to
+		// keep label cardinality bounded.
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+		method := c.Request.Method
+
+		metrics.HTTPRequestsTotal.WithLabelValues(method, route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(startTime).Seconds())
+	}
+}
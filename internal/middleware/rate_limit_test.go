@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+func newRateLimitedRouter(t *testing.T, key RateLimitKeyFunc) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	policy := RateLimitPolicy{Name: "login", Limit: 2, Window: time.Minute, Key: key}
+	router.POST("/login", RateLimitMiddleware(ratelimit.NewMemoryLimiter(), policy), func(c *gin.Context) {
+		var req struct {
+			Email string `json:"email"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.String(http.StatusOK, req.Email)
+	})
+
+	return router
+}
+
+func login(router *gin.Engine, remoteAddr, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      RateLimitKeyFunc
+		requests []struct{ remoteAddr, body string }
+		want     []int
+	}{
+		{
+			name: "rejects a client over the limit",
+			key:  RateLimitByIP,
+			requests: []struct{ remoteAddr, body string }{
+				{"192.0.2.1:1234", `{"email":"a@example.com"}`},
+				{"192.0.2.1:1234", `{"email":"b@example.com"}`},
+				{"192.0.2.1:1234", `{"email":"c@example.com"}`},
+				{"198.51.100.7:1234", `{"email":"a@example.com"}`},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "counts an email across clients and letter case",
+			key:  RateLimitByEmail,
+			requests: []struct{ remoteAddr, body string }{
+				{"192.0.2.1:1234", `{"email":"a@example.com"}`},
+				{"198.51.100.7:1234", `{"email":" A@Example.com "}`},
+				{"203.0.113.9:1234", `{"email":"a@example.com"}`},
+				{"203.0.113.9:1234", `{"email":"b@example.com"}`},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRateLimitedRouter(t, tt.key)
+
+			for i, r := range tt.requests {
+				w := login(router, r.remoteAddr, r.body)
+				if w.Code != tt.want[i] {
+					t.Fatalf("request %d = %d, want %d", i, w.Code, tt.want[i])
+				}
+
+				if w.Code == http.StatusTooManyRequests {
+					if got := w.Header().Get("Retry-After"); got != "60" {
+						t.Errorf("Retry-After = %q, want 60", got)
+					}
+					if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+						t.Errorf("X-RateLimit-Remaining = %q, want 0", got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitByEmailRestoresBody(t *testing.T) {
+	router := newRateLimitedRouter(t, RateLimitByEmail)
+
+	w := login(router, "192.0.2.1:1234", `{"email":"a@example.com"}`)
+	if w.Code != http.StatusOK || w.Body.String() != "a@example.com" {
+		t.Errorf("handler got %d %q, want the request body", w.Code, w.Body)
+	}
+}
+
+func TestRateLimitByEmailCapsBody(t *testing.T) {
+	body := `{"email":"a@example.com","padding":"` + strings.Repeat("x", maxRateLimitBody) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+
+	if key := RateLimitByEmail(c); key != "" {
+		t.Errorf("key = %q for an oversized body, want none", key)
+	}
+}
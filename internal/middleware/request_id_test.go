@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/affandisy/goshop/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDReachesServiceLogs(t *testing.T) {
+	var logs bytes.Buffer
+	log := logger.NewWithWriter(&logs, "production")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/orders", func(c *gin.Context) {
+		log.WarnContext(c.Request.Context(), "Failed to convert order total")
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   func(w *httptest.ResponseRecorder) string
+	}{
+		{
+			name:   "caller supplied",
+			header: "req-123",
+			want:   func(*httptest.ResponseRecorder) string { return "req-123" },
+		},
+		{
+			name:   "generated",
+			header: "bad id\n",
+			want:   func(w *httptest.ResponseRecorder) string { return w.Header().Get(RequestIDHeader) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			req.Header.Set(RequestIDHeader, tt.header)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			want := tt.want(w)
+			if want == "" || w.Header().Get(RequestIDHeader) != want {
+				t.Fatalf("%s = %q, want %q", RequestIDHeader, w.Header().Get(RequestIDHeader), want)
+			}
+			if !strings.Contains(logs.String(), `"request_id":"`+want+`"`) {
+				t.Errorf("log %s does not carry request_id %q", strings.TrimSpace(logs.String()), want)
+			}
+		})
+	}
+}
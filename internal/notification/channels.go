@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/affandisy/goshop/internal/webhook"
+	"github.com/affandisy/goshop/pkg/mailer"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+// errPermanent marks failures that retrying cannot fix.
+var errPermanent = errors.New("permanent delivery failure")
+
+type emailChannel struct {
+	mailer mailer.Mailer
+}
+
+func NewEmailChannel(m mailer.Mailer) Channel {
+	return &emailChannel{mailer: m}
+}
+
+func (c *emailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (c *emailChannel) Send(ctx context.Context, msg Message) error {
+	if msg.Recipient.Email == "" {
+		return fmt.Errorf("%w: recipient has no email", errPermanent)
+	}
+
+	return c.mailer.Send(ctx, mailer.Message{
+		To:       msg.Recipient.Email,
+		Subject:  msg.Subject,
+		TextBody: msg.Text,
+		HTMLBody: msg.HTML,
+	})
+}
+
+// webhookChannel POSTs the rendered message as JSON, e.g. to a WhatsApp or
+// SMS gateway that takes care of the last mile. With a secret configured
+// the body is signed the same way as merchant webhooks.
+type webhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookChannel(url, secret string, timeout time.Duration) Channel {
+	return &webhookChannel{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (c *webhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+func (c *webhookChannel) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.secret != "" {
+		webhook.SetSignatureHeaders(req, c.secret, body, time.Now())
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: webhook responded with status %d", errPermanent, resp.StatusCode)
+	default:
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
+
+type logChannel struct {
+	logger *slog.Logger
+}
+
+func NewLogChannel(logger *slog.Logger) Channel {
+	return &logChannel{logger: logger}
+}
+
+func (c *logChannel) Name() string {
+	return ChannelLog
+}
+
+func (c *logChannel) Send(ctx context.Context, msg Message) error {
+	c.logger.InfoContext(ctx, "Notification",
+		slog.String("event", string(msg.Event)),
+		slog.String("locale", msg.Locale),
+		subjectAttr(msg.OrderID, msg.ProductID),
+		slog.String("to", msg.Recipient.Email),
+		slog.String("subject", msg.Subject),
+	)
+	return nil
+}
@@ -0,0 +1,198 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+type DispatcherConfig struct {
+	QueueSize      int
+	Workers        int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+type job struct {
+	ctx   context.Context
+	event Event
+}
+
+// Dispatcher queues events in memory and delivers them to every channel from
+// a pool of workers, retrying failed deliveries with exponential backoff.
+type Dispatcher struct {
+	renderer  *Renderer
+	channels  []Channel
+	processed repository.ProcessedEventRepository
+	cfg       DispatcherConfig
+	queue     chan job
+	logger    *slog.Logger
+}
+
+func NewDispatcher(renderer *Renderer, channels []Channel, processed repository.ProcessedEventRepository, cfg DispatcherConfig, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		renderer:  renderer,
+		channels:  channels,
+		processed: processed,
+		cfg:       cfg,
+		queue:     make(chan job, cfg.QueueSize),
+		logger:    logger,
+	}
+}
+
+// Notify enqueues event without blocking. When the queue is full the event
+// is dropped and logged rather than slowing down the request.
+func (d *Dispatcher) Notify(ctx context.Context, event Event) {
+	// Keep request scoped values such as the request ID, but not the
+	// request's cancellation: delivery outlives the request.
+	j := job{ctx: context.WithoutCancel(ctx), event: event}
+
+	select {
+	case d.queue <- j:
+	default:
+		d.logger.ErrorContext(ctx, "Notification queue full, dropping event",
+			slog.String("event", string(event.Type)),
+			event.subjectAttr(),
+		)
+	}
+}
+
+// Deliver renders event and sends it to every channel once. It returns the
+// failures a retry could fix; permanent ones are only logged. When the
+// event has an EventID, channels that already sent it are skipped, so a
+// retry only reaches the channels that failed.
+func (d *Dispatcher) Deliver(ctx context.Context, event Event) error {
+	msg, err := d.renderer.Render(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, ch := range d.channels {
+		consumer := event.consumer(ch.Name())
+		if consumer != "" {
+			sent, err := d.processed.Exists(ctx, consumer, event.EventID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if sent {
+				continue
+			}
+		}
+
+		switch err := ch.Send(ctx, msg); {
+		case errors.Is(err, errPermanent):
+			d.logger.ErrorContext(ctx, "Notification delivery failed",
+				slog.String("channel", ch.Name()),
+				slog.String("event", string(msg.Event)),
+				subjectAttr(msg.OrderID, msg.ProductID),
+				slog.Any("error", err),
+			)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+			continue
+		}
+
+		// A permanent failure is recorded too, as retrying can't help.
+		if consumer != "" {
+			if err := d.processed.Create(ctx, &domain.ProcessedEvent{
+				Consumer:    consumer,
+				EventID:     event.EventID,
+				ProcessedAt: time.Now(),
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run processes the queue until ctx is cancelled, then delivers what is
+// still queued without retrying before it returns. Stop whatever calls
+// Notify first, or events queued after that are lost.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					d.drain(ctx)
+					return
+				case j := <-d.queue:
+					d.process(ctx, j)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// drain processes queued jobs until the queue is empty.
+func (d *Dispatcher) drain(ctx context.Context) {
+	for {
+		select {
+		case j := <-d.queue:
+			d.process(ctx, j)
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) process(runCtx context.Context, j job) {
+	msg, err := d.renderer.Render(j.event)
+	if err != nil {
+		d.logger.ErrorContext(j.ctx, "Failed to render notification",
+			slog.String("event", string(j.event.Type)),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, ch := range d.channels {
+		d.deliver(runCtx, j.ctx, ch, msg)
+	}
+}
+
+func (d *Dispatcher) deliver(runCtx, ctx context.Context, ch Channel, msg Message) {
+	for attempt := 1; ; attempt++ {
+		err := ch.Send(ctx, msg)
+		if err == nil {
+			return
+		}
+
+		attrs := []any{
+			slog.String("channel", ch.Name()),
+			slog.String("event", string(msg.Event)),
+			subjectAttr(msg.OrderID, msg.ProductID),
+			slog.Int("attempt", attempt),
+			slog.Any("error", err),
+		}
+
+		if errors.Is(err, errPermanent) || attempt >= d.cfg.MaxAttempts {
+			d.logger.ErrorContext(ctx, "Notification delivery failed", attrs...)
+			return
+		}
+
+		d.logger.WarnContext(ctx, "Notification delivery failed, retrying", attrs...)
+
+		select {
+		case <-runCtx.Done():
+			return
+		case <-time.After(utils.Backoff(attempt, d.cfg.RetryBaseDelay, d.cfg.RetryMaxDelay)):
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// recordingChannel records what it is sent and fails with err.
+type recordingChannel struct {
+	name string
+	err  error
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Send(_ context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, msg)
+	return c.err
+}
+
+func (c *recordingChannel) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sent)
+}
+
+// memoryProcessed keeps processed events in memory.
+type memoryProcessed struct {
+	repository.ProcessedEventRepository
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func (r *memoryProcessed) Exists(_ context.Context, consumer, eventID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.done[consumer+"/"+eventID], nil
+}
+
+func (r *memoryProcessed) Create(_ context.Context, processed *domain.ProcessedEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done == nil {
+		r.done = map[string]bool{}
+	}
+	r.done[processed.Consumer+"/"+processed.EventID] = true
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, cfg DispatcherConfig, channels ...Channel) *Dispatcher {
+	t.Helper()
+
+	renderer, err := NewRenderer(LocaleEN, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDispatcher(renderer, channels, &memoryProcessed{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func testEvent() Event {
+	order := &domain.Order{OrderNumber: "ORD-20261018-0001234", CustomerName: "Customer", CustomerEmail: "customer@example.com"}
+	order.ID = "order-1"
+	return OrderEvent(EventOrderPaid, order)
+}
+
+func TestDeliverSendsToEveryChannel(t *testing.T) {
+	email := &recordingChannel{name: ChannelEmail}
+	log := &recordingChannel{name: ChannelLog}
+	d := newTestDispatcher(t, DispatcherConfig{}, email, log)
+
+	if err := d.Deliver(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if email.count() != 1 || log.count() != 1 {
+		t.Errorf("sent %d emails and %d logs, want 1 each", email.count(), log.count())
+	}
+	if got := email.sent[0].OrderID; got != "order-1" {
+		t.Errorf("message order ID = %q, want order-1", got)
+	}
+}
+
+func TestDeliverReturnsRetryableFailures(t *testing.T) {
+	unavailable := errors.New("smtp unavailable")
+	email := &recordingChannel{name: ChannelEmail, err: unavailable}
+	webhook := &recordingChannel{name: ChannelWebhook, err: errPermanent}
+	log := &recordingChannel{name: ChannelLog}
+	d := newTestDispatcher(t, DispatcherConfig{}, email, webhook, log)
+
+	err := d.Deliver(context.Background(), testEvent())
+	if !errors.Is(err, unavailable) {
+		t.Errorf("Deliver = %v, want %v", err, unavailable)
+	}
+	if errors.Is(err, errPermanent) {
+		t.Errorf("Deliver = %v, want permanent failures left out", err)
+	}
+	if log.count() != 1 {
+		t.Errorf("log channel sent %d messages, want 1 despite the other failures", log.count())
+	}
+}
+
+func TestDeliverRetrySkipsChannelsThatSent(t *testing.T) {
+	email := &recordingChannel{name: ChannelEmail, err: errors.New("smtp unavailable")}
+	webhook := &recordingChannel{name: ChannelWebhook, err: errPermanent}
+	log := &recordingChannel{name: ChannelLog}
+	d := newTestDispatcher(t, DispatcherConfig{}, email, webhook, log)
+
+	e := testEvent()
+	e.EventID = "event-1"
+	if err := d.Deliver(context.Background(), e); err == nil {
+		t.Fatal("Deliver succeeded although the email channel failed")
+	}
+
+	email.err = nil
+	if err := d.Deliver(context.Background(), e); err != nil {
+		t.Fatalf("Deliver retry: %v", err)
+	}
+	if email.count() != 2 || webhook.count() != 1 || log.count() != 1 {
+		t.Errorf("sent %d emails, %d webhooks and %d logs; want the retry to reach only email", email.count(), webhook.count(), log.count())
+	}
+
+	// Another notification for the same event is tracked apart.
+	e.Scope = "item-1"
+	if err := d.Deliver(context.Background(), e); err != nil {
+		t.Fatalf("Deliver scoped: %v", err)
+	}
+	if log.count() != 2 {
+		t.Errorf("sent %d logs, want the scoped notification sent", log.count())
+	}
+}
+
+func TestRunDrainsQueueOnShutdown(t *testing.T) {
+	ch := &recordingChannel{name: ChannelLog}
+	d := newTestDispatcher(t, DispatcherConfig{QueueSize: 10, Workers: 2, MaxAttempts: 3, RetryBaseDelay: time.Millisecond, RetryMaxDelay: time.Millisecond}, ch)
+
+	for i := 0; i < 5; i++ {
+		d.Notify(context.Background(), testEvent())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after ctx was cancelled")
+	}
+	if ch.count() != 5 {
+		t.Errorf("sent %d of 5 queued notifications", ch.count())
+	}
+}
@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
+
+type EventType string
+
+const (
+	EventOrderCreated   EventType = "order_created"
+	EventOrderPaid      EventType = "order_paid"
+	EventOrderShipped   EventType = "order_shipped"
+	EventOrderDelivered EventType = "order_delivered"
+	EventOrderCancelled EventType = "order_cancelled"
+	EventPaymentFailed  EventType = "payment_failed"
+
+	EventWishlistBackInStock EventType = "wishlist_back_in_stock"
+	EventWishlistPriceDrop   EventType = "wishlist_price_drop"
+
+	EventLowStock EventType = "low_stock" // sent to staff, not customers
+)
+
+const (
+	LocaleID = "id"
+	LocaleEN = "en"
+)
+
+type Recipient struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Event is something a customer should hear about. Either Order or Product
+// is set; Payment is set for payment events and PreviousPrice for price
+// drops.
+type Event struct {
+	Type          EventType
+	Locale        string
+	Recipient     Recipient
+	Order         *domain.Order
+	Payment       *domain.Payment
+	Product       *domain.Product
+	PreviousPrice domain.Money
+
+	// EventID is the domain event the notification is sent for. Deliver
+	// remembers which channels have sent it, so a retry skips them. Scope
+	// tells apart several notifications for one event, e.g. one per
+	// wishlist item.
+	EventID string
+	Scope   string
+}
+
+// consumer names channel's delivery of the event in the processed events,
+// or is empty when the event has no EventID.
+func (e Event) consumer(channel string) string {
+	if e.EventID == "" {
+		return ""
+	}
+	if e.Scope == "" {
+		return "notification." + channel
+	}
+	return "notification." + channel + ":" + e.Scope
+}
+
+// Message is an Event rendered for delivery.
+type Message struct {
+	Event     EventType `json:"event"`
+	Locale    string    `json:"locale"`
+	Recipient Recipient `json:"recipient"`
+	OrderID   string    `json:"order_id,omitempty"`
+	ProductID string    `json:"product_id,omitempty"`
+	Subject   string    `json:"subject"`
+	Text      string    `json:"text"`
+	HTML      string    `json:"html"`
+}
+
+// Notifier accepts events from the services. Notify queues the event and
+// must not block the caller on delivery. Deliver sends it before returning,
+// for callers that retry on failure, such as outbox subscribers.
+type Notifier interface {
+	Notify(ctx context.Context, event Event)
+	Deliver(ctx context.Context, event Event) error
+}
+
+// Channel delivers a rendered message over one medium.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// OrderEvent builds an event for order, addressed to the order's customer.
+func OrderEvent(eventType EventType, order *domain.Order) Event {
+	contact := order.Contact()
+	return Event{
+		Type:      eventType,
+		Order:     order,
+		Recipient: Recipient{Name: contact.Name, Email: contact.Email, Phone: contact.Phone},
+	}
+}
+
+// ProductEvent builds an event about product, addressed to user.
+func ProductEvent(eventType EventType, product *domain.Product, user *domain.User) Event {
+	return Event{
+		Type:      eventType,
+		Product:   product,
+		Recipient: Recipient{Name: user.Name, Email: user.Email, Phone: user.Phone},
+	}
+}
+
+func (e Event) subjectAttr() slog.Attr {
+	if e.Order != nil {
+		return subjectAttr(e.Order.ID, "")
+	}
+	return subjectAttr("", e.Product.ID)
+}
+
+// subjectAttr identifies what the notification is about in logs.
+func subjectAttr(orderID, productID string) slog.Attr {
+	if orderID == "" {
+		return slog.String("product_id", productID)
+	}
+	return slog.String("order_id", orderID)
+}
+
+// EventForStatus maps an order status to the event customers are told
+// about, if any.
+func EventForStatus(status domain.OrderStatus) (EventType, bool) {
+	switch status {
+	case domain.OrderStatusPaid:
+		return EventOrderPaid, true
+	case domain.OrderStatusShipped:
+		return EventOrderShipped, true
+	case domain.OrderStatusDelivered:
+		return EventOrderDelivered, true
+	case domain.OrderStatusCancelled:
+		return EventOrderCancelled, true
+	}
+	return "", false
+}
@@ -0,0 +1,190 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/affandisy/goshop/internal/domain"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var subjects = map[string]map[EventType]string{
+	LocaleEN: {
+		EventOrderCreated:   "Order %s received",
+		EventOrderPaid:      "Payment received for order %s",
+		EventOrderShipped:   "Order %s has been shipped",
+		EventOrderDelivered: "Order %s has been delivered",
+		EventOrderCancelled: "Order %s has been cancelled",
+		EventPaymentFailed:  "Payment for order %s was not completed",
+
+		EventWishlistBackInStock: "%s is back in stock",
+		EventWishlistPriceDrop:   "Price drop on %s",
+
+		EventLowStock: "Low stock: %s",
+	},
+	LocaleID: {
+		EventOrderCreated:   "Pesanan %s diterima",
+		EventOrderPaid:      "Pembayaran pesanan %s diterima",
+		EventOrderShipped:   "Pesanan %s telah dikirim",
+		EventOrderDelivered: "Pesanan %s telah sampai",
+		EventOrderCancelled: "Pesanan %s dibatalkan",
+		EventPaymentFailed:  "Pembayaran pesanan %s tidak berhasil",
+
+		EventWishlistBackInStock: "%s tersedia kembali",
+		EventWishlistPriceDrop:   "Harga %s turun",
+
+		EventLowStock: "Stok menipis: %s",
+	},
+}
+
+type templateSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Renderer turns events into localized plain text and HTML messages.
+type Renderer struct {
+	defaultLocale string
+	orderURL      func(*domain.Order) string
+	sets          map[string]templateSet
+}
+
+// NewRenderer loads the templates. orderURL, if not nil, builds the "view
+// your order" link included in some messages.
+func NewRenderer(defaultLocale string, orderURL func(*domain.Order) string) (*Renderer, error) {
+	r := &Renderer{defaultLocale: defaultLocale, orderURL: orderURL, sets: map[string]templateSet{}}
+
+	for locale := range subjects {
+		text, err := texttemplate.ParseFS(templateFS, fmt.Sprintf("templates/%s.txt.tmpl", locale))
+		if err != nil {
+			return nil, err
+		}
+		html, err := htmltemplate.ParseFS(templateFS, fmt.Sprintf("templates/%s.html.tmpl", locale))
+		if err != nil {
+			return nil, err
+		}
+		r.sets[locale] = templateSet{text: text, html: html}
+	}
+
+	if _, ok := r.sets[defaultLocale]; !ok {
+		return nil, fmt.Errorf("unsupported default locale %q", defaultLocale)
+	}
+
+	return r, nil
+}
+
+type itemData struct {
+	Name     string
+	Quantity int
+	Price    string
+}
+
+type messageData struct {
+	Name          string
+	OrderNumber   string
+	Total         string
+	PaymentStatus string
+	OrderURL      string
+	Items         []itemData
+	ProductName   string
+	SKU           string
+	Price         string
+	PreviousPrice string
+	Stock         int
+	ReorderLevel  int
+	ReorderQty    int
+}
+
+func (r *Renderer) Render(event Event) (Message, error) {
+	locale := event.Locale
+	if _, ok := r.sets[locale]; !ok {
+		locale = r.defaultLocale
+	}
+	set := r.sets[locale]
+
+	data := messageData{Name: event.Recipient.Name}
+	msg := Message{Event: event.Type, Locale: locale, Recipient: event.Recipient}
+	var subject string
+
+	if order := event.Order; order != nil {
+		msg.OrderID = order.ID
+		subject = order.OrderNumber
+		data.OrderNumber = order.OrderNumber
+		data.Total = FormatRupiah(order.TotalAmount)
+		if r.orderURL != nil {
+			data.OrderURL = r.orderURL(order)
+		}
+		for _, item := range order.OrderItems {
+			name := item.ProductID
+			if item.Product != nil {
+				name = item.Product.Name
+			}
+			data.Items = append(data.Items, itemData{
+				Name:     name,
+				Quantity: item.Quantity,
+				Price:    FormatRupiah(item.Price),
+			})
+		}
+	}
+	if event.Payment != nil {
+		data.PaymentStatus = string(event.Payment.Status)
+	}
+	if product := event.Product; product != nil {
+		msg.ProductID = product.ID
+		subject = product.Name
+		data.ProductName = product.Name
+		data.SKU = product.SKU
+		data.Price = FormatRupiah(product.Price)
+		data.Stock = product.Stock
+		data.ReorderLevel = product.ReorderLevel
+		data.ReorderQty = product.ReorderQuantity
+		if event.PreviousPrice.IsPositive() {
+			data.PreviousPrice = FormatRupiah(event.PreviousPrice)
+		}
+	}
+
+	var text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&text, string(event.Type), data); err != nil {
+		return Message{}, err
+	}
+	if err := set.html.ExecuteTemplate(&html, string(event.Type), data); err != nil {
+		return Message{}, err
+	}
+
+	msg.Subject = fmt.Sprintf(subjects[locale][event.Type], subject)
+	msg.Text = strings.TrimSpace(text.String()) + "\n"
+	msg.HTML = strings.TrimSpace(html.String())
+
+	return msg, nil
+}
+
+// FormatRupiah formats an amount the Indonesian way, e.g. Rp 1.250.000.
+func FormatRupiah(amount domain.Money) string {
+	s, frac, _ := strings.Cut(amount.Decimal(), ".")
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+
+	if frac != "" {
+		b.WriteString("," + frac)
+	}
+
+	if negative {
+		return "-Rp " + b.String()
+	}
+	return "Rp " + b.String()
+}
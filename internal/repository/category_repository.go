@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"gorm.io/gorm"
 )
@@ -13,13 +15,13 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) Create(category *domain.Category) error {
-	return r.db.Create(category).Error
+func (r *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	return dbFrom(ctx, r.db).Create(category).Error
 }
 
-func (r *categoryRepository) GetByID(id string) (*domain.Category, error) {
+func (r *categoryRepository) GetByID(ctx context.Context, id string) (*domain.Category, error) {
 	var category domain.Category
-	err := r.db.Where("id = ?", id).First(&category).Error
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&category).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrCategoryNotFound
@@ -29,9 +31,9 @@ func (r *categoryRepository) GetByID(id string) (*domain.Category, error) {
 	return &category, nil
 }
 
-func (r *categoryRepository) GetAll() ([]domain.Category, error) {
+func (r *categoryRepository) GetAll(ctx context.Context) ([]domain.Category, error) {
 	var categories []domain.Category
-	err := r.db.Where("is_active = ?", true).Find(&categories).Error
+	err := dbFrom(ctx, r.db).Where("is_active = ?", true).Find(&categories).Error
 	if err != nil {
 		return nil, err
 	}
@@ -39,10 +41,10 @@ func (r *categoryRepository) GetAll() ([]domain.Category, error) {
 	return categories, nil
 }
 
-func (r *categoryRepository) Update(category *domain.Category) error {
-	return r.db.Save(category).Error
+func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	return dbFrom(ctx, r.db).Save(category).Error
 }
 
-func (r *categoryRepository) Delete(id string) error {
-	return r.db.Delete(&domain.Category{}, "id = ?", id).Error
+func (r *categoryRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.Category{}, "id = ?", id).Error
 }
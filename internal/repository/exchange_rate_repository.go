@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"gorm.io/gorm"
+)
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) Create(ctx context.Context, rate *domain.ExchangeRate) error {
+	return dbFrom(ctx, r.db).Create(rate).Error
+}
+
+func (r *exchangeRateRepository) GetByID(ctx context.Context, id string) (*domain.ExchangeRate, error) {
+	var rate domain.ExchangeRate
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&rate).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+func (r *exchangeRateRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.ExchangeRate{}, "id = ?", id).Error
+}
+
+func (r *exchangeRateRepository) List(ctx context.Context, query dto.ExchangeRateQuery) ([]domain.ExchangeRate, int64, error) {
+	var rates []domain.ExchangeRate
+	var total int64
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	db := dbFrom(ctx, r.db).Model(&domain.ExchangeRate{})
+	if query.Currency != "" {
+		db = db.Where("currency = ?", query.Currency)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := db.Order("effective_from DESC").Order("created_at DESC").
+		Offset(offset).Limit(query.Limit).Find(&rates).Error
+
+	return rates, total, err
+}
+
+func (r *exchangeRateRepository) GetEffective(ctx context.Context, currency domain.Currency, at time.Time) (*domain.ExchangeRate, error) {
+	var rate domain.ExchangeRate
+	err := dbFrom(ctx, r.db).
+		Where("currency = ? AND effective_from <= ?", currency, at).
+		Order("effective_from DESC").Order("created_at DESC").
+		First(&rate).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
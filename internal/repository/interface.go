@@ -1,52 +1,270 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 )
 
 type UserRepository interface {
-	Create(user *domain.User) error
-	GetByID(id string) (*domain.User, error)
-	GetByEmail(email string) (*domain.User, error)
-	Update(user *domain.User) error
-	Delete(id string) error
-	List(page, limit int) ([]domain.User, int64, error)
+	Create(ctx context.Context, user *domain.User) error
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	// RegisterFailedLogin atomically counts a failed login and locks the
+	// account once policy allows no more, returning the updated user. It
+	// fails with ErrAccountLocked when the account is already locked.
+	RegisterFailedLogin(ctx context.Context, id string, policy domain.LockoutPolicy, now time.Time) (*domain.User, error)
+	// ResetLoginFailures clears the failure counters and any lockout
+	// without touching the rest of the user.
+	ResetLoginFailures(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, page, limit int) ([]domain.User, int64, error)
+}
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *domain.LoginAttempt) error
+	List(ctx context.Context, query dto.LoginAttemptQuery) ([]domain.LoginAttempt, int64, error)
+	// CountFailedByIP counts the attempts from ip with invalid credentials.
+	// Rejected attempts are not counted, so retrying doesn't extend a block.
+	CountFailedByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *domain.UserToken) error
+	GetByHash(ctx context.Context, tokenHash string, purpose domain.UserTokenPurpose) (*domain.UserToken, error)
+	MarkUsed(ctx context.Context, id string) error
+	InvalidateForUser(ctx context.Context, userID string, purpose domain.UserTokenPurpose) error
 }
 
 type CategoryRepository interface {
-	Create(category *domain.Category) error
-	GetByID(id string) (*domain.Category, error)
-	GetAll() ([]domain.Category, error)
-	Update(category *domain.Category) error
-	Delete(id string) error
+	Create(ctx context.Context, category *domain.Category) error
+	GetByID(ctx context.Context, id string) (*domain.Category, error)
+	GetAll(ctx context.Context) ([]domain.Category, error)
+	Update(ctx context.Context, category *domain.Category) error
+	Delete(ctx context.Context, id string) error
 }
 
 type ProductRepository interface {
-	Create(product *domain.Product) error
-	GetByID(id string) (*domain.Product, error)
-	GetBySKU(sku string) (*domain.Product, error)
-	List(query dto.ProductQuery) ([]domain.Product, int64, error)
-	Update(product *domain.Product) error
-	Delete(id string) error
-	UpdateStock(id string, quantity int) error
+	Create(ctx context.Context, product *domain.Product) error
+	GetByID(ctx context.Context, id string) (*domain.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	// LockByID loads the product and locks its row until the transaction
+	// ends. It must be called inside a transaction, after the product's
+	// inventory levels.
+	LockByID(ctx context.Context, id string) (*domain.Product, error)
+	List(ctx context.Context, query dto.ProductQuery) ([]domain.Product, int64, error)
+	// Update saves the fields an admin edits. Stock totals, ratings and the
+	// low-stock alert are maintained elsewhere and never written here.
+	Update(ctx context.Context, product *domain.Product) error
+	Delete(ctx context.Context, id string) error
+	ListLowStock(ctx context.Context, page, limit int) ([]domain.Product, int64, error)
+	// FetchLowStockToAlert locks up to limit low-stock products that have
+	// not been alerted yet. It must be called inside a transaction.
+	FetchLowStockToAlert(ctx context.Context, limit int) ([]domain.Product, error)
+	MarkLowStockAlerted(ctx context.Context, ids []string, at time.Time) error
+	// ResetLowStockAlerts re-arms the alert for products that have been
+	// restocked above their reorder level.
+	ResetLowStockAlerts(ctx context.Context) (int64, error)
 }
 
 type OrderRepository interface {
-	Create(order *domain.Order) error
-	GetByID(id string) (*domain.Order, error)
-	GetByOrderNumber(orderNumber string) (*domain.Order, error)
-	GetByUserID(userID string, page, limit int) ([]domain.Order, int64, error)
-	GetAll(page, limit int) ([]domain.Order, int64, error)
-	Update(order *domain.Order) error
-	UpdateStatus(id string, status domain.OrderStatus) error
+	Create(ctx context.Context, order *domain.Order) error
+	GetByID(ctx context.Context, id string) (*domain.Order, error)
+	GetByOrderNumber(ctx context.Context, orderNumber string) (*domain.Order, error)
+	// LockByID loads the order and locks its row until the transaction
+	// ends. It must be called inside a transaction.
+	LockByID(ctx context.Context, id string) (*domain.Order, error)
+	GetByUserID(ctx context.Context, userID string, page, limit int) ([]domain.Order, int64, error)
+	GetAll(ctx context.Context, page, limit int) ([]domain.Order, int64, error)
+	Update(ctx context.Context, order *domain.Order) error
+	UpdateStatus(ctx context.Context, id string, status domain.OrderStatus) error
+	// ClaimGuestOrders assigns guest orders placed with email to userID and
+	// returns how many were claimed.
+	ClaimGuestOrders(ctx context.Context, email, userID string) (int64, error)
+	// GetDeliveredItem returns the user's most recent delivered order item
+	// for the product, or ErrOrderNotFound if they never received it.
+	GetDeliveredItem(ctx context.Context, userID, productID string) (*domain.OrderItem, error)
+}
+
+type OrderSequenceRepository interface {
+	// Next returns the next number of the order number sequence.
+	Next(ctx context.Context) (int64, error)
 }
 
 type PaymentRepository interface {
-	Create(payment *domain.Payment) error
-	GetByID(id string) (*domain.Payment, error)
-	GetByOrderID(orderID string) (*domain.Payment, error)
-	GetByMidtransOrderID(midtransOrderID string) (*domain.Payment, error)
-	Update(payment *domain.Payment) error
-	List(page, limit int) ([]domain.Payment, int64, error)
+	Create(ctx context.Context, payment *domain.Payment) error
+	GetByID(ctx context.Context, id string) (*domain.Payment, error)
+	GetByOrderID(ctx context.Context, orderID string) (*domain.Payment, error)
+	GetByMidtransOrderID(ctx context.Context, midtransOrderID string) (*domain.Payment, error)
+	// LockByMidtransOrderID loads the payment without its order and locks
+	// its row until the transaction ends. It must be called inside a
+	// transaction.
+	LockByMidtransOrderID(ctx context.Context, midtransOrderID string) (*domain.Payment, error)
+	Update(ctx context.Context, payment *domain.Payment) error
+	List(ctx context.Context, page, limit int) ([]domain.Payment, int64, error)
+}
+
+type PriceRuleRepository interface {
+	Create(ctx context.Context, rule *domain.PriceRule) error
+	GetByID(ctx context.Context, id string) (*domain.PriceRule, error)
+	Delete(ctx context.Context, id string) error
+	// ListByProduct returns the product's running and scheduled rules.
+	ListByProduct(ctx context.Context, productID string, at time.Time) ([]domain.PriceRule, error)
+	// ListActive returns the rules of the products running at at, for any
+	// quantity.
+	ListActive(ctx context.Context, productIDs []string, at time.Time) ([]domain.PriceRule, error)
+}
+
+type PriceHistoryRepository interface {
+	Create(ctx context.Context, history *domain.PriceHistory) error
+	ListByProduct(ctx context.Context, productID string, page, limit int) ([]domain.PriceHistory, int64, error)
+}
+
+type ProductTranslationRepository interface {
+	// Upsert creates or replaces the product's translation for its locale.
+	Upsert(ctx context.Context, translation *domain.ProductTranslation) error
+	ListByProduct(ctx context.Context, productID string) ([]domain.ProductTranslation, error)
+	// ListByLocale returns the translations of the products into locale.
+	ListByLocale(ctx context.Context, productIDs []string, locale string) ([]domain.ProductTranslation, error)
+	Delete(ctx context.Context, productID, locale string) error
+}
+
+type CategoryTranslationRepository interface {
+	// Upsert creates or replaces the category's translation for its locale.
+	Upsert(ctx context.Context, translation *domain.CategoryTranslation) error
+	ListByCategory(ctx context.Context, categoryID string) ([]domain.CategoryTranslation, error)
+	// ListByLocale returns the translations of the categories into locale.
+	ListByLocale(ctx context.Context, categoryIDs []string, locale string) ([]domain.CategoryTranslation, error)
+	Delete(ctx context.Context, categoryID, locale string) error
+}
+
+type ExchangeRateRepository interface {
+	Create(ctx context.Context, rate *domain.ExchangeRate) error
+	GetByID(ctx context.Context, id string) (*domain.ExchangeRate, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, query dto.ExchangeRateQuery) ([]domain.ExchangeRate, int64, error)
+	// GetEffective returns the currency's rate in effect at at.
+	GetEffective(ctx context.Context, currency domain.Currency, at time.Time) (*domain.ExchangeRate, error)
+}
+
+type WarehouseRepository interface {
+	Create(ctx context.Context, warehouse *domain.Warehouse) error
+	GetByID(ctx context.Context, id string) (*domain.Warehouse, error)
+	GetByCode(ctx context.Context, code string) (*domain.Warehouse, error)
+	// GetDefault returns the active warehouse with the highest priority.
+	GetDefault(ctx context.Context) (*domain.Warehouse, error)
+	List(ctx context.Context) ([]domain.Warehouse, error)
+	Update(ctx context.Context, warehouse *domain.Warehouse) error
+	Delete(ctx context.Context, id string) error
+}
+
+type InventoryRepository interface {
+	ListByProduct(ctx context.Context, productID string) ([]domain.InventoryLevel, error)
+	ListByWarehouse(ctx context.Context, warehouseID string, page, limit int) ([]domain.InventoryLevel, int64, error)
+	// LockAvailable locks the levels of the products with available stock
+	// in active warehouses. It must be called inside a transaction. Levels
+	// are locked in product, then warehouse order, like reservations.
+	LockAvailable(ctx context.Context, productIDs []string) ([]domain.InventoryLevel, error)
+	// LockByProduct locks every level of the product, in warehouse order.
+	// It must be called inside a transaction.
+	LockByProduct(ctx context.Context, productID string) error
+	// Adjust changes the stock of a product in a warehouse, creating the
+	// level if needed. It fails with ErrInsufficientStock, leaving the
+	// transaction to roll back, if less than the reserved stock would be left.
+	Adjust(ctx context.Context, productID, warehouseID string, delta int) error
+	// Hold changes the reserved stock of a product in a warehouse. It fails
+	// with ErrInsufficientStock if more than the stock would be reserved.
+	Hold(ctx context.Context, productID, warehouseID string, delta int) error
+	CountStock(ctx context.Context, warehouseID string) (int64, error)
+	// SyncProductStock recomputes Product.Stock and Product.Reserved from
+	// active warehouses.
+	SyncProductStock(ctx context.Context, productIDs []string) error
+	// SyncWarehouseProducts recomputes Product.Stock and Product.Reserved
+	// for every product stocked in the warehouse.
+	SyncWarehouseProducts(ctx context.Context, warehouseID string) error
+}
+
+type StockReservationRepository interface {
+	Create(ctx context.Context, reservations []domain.StockReservation) error
+	// LockActiveByOrder locks the order's active reservations. It must be
+	// called inside a transaction.
+	LockActiveByOrder(ctx context.Context, orderID string) ([]domain.StockReservation, error)
+	UpdateStatus(ctx context.Context, ids []string, status domain.ReservationStatus) error
+	// ListExpiredOrderIDs returns orders with active reservations that
+	// expired before now.
+	ListExpiredOrderIDs(ctx context.Context, now time.Time, limit int) ([]string, error)
+}
+
+type StockTransferRepository interface {
+	Create(ctx context.Context, transfer *domain.StockTransfer) error
+	List(ctx context.Context, query dto.StockTransferQuery) ([]domain.StockTransfer, int64, error)
+}
+
+type WishlistRepository interface {
+	Create(ctx context.Context, item *domain.WishlistItem) error
+	GetByUserAndProduct(ctx context.Context, userID, productID string) (*domain.WishlistItem, error)
+	ListByUser(ctx context.Context, userID string, page, limit int) ([]domain.WishlistItem, int64, error)
+	// ListByProduct returns every wishlist entry for the product with its user.
+	ListByProduct(ctx context.Context, productID string) ([]domain.WishlistItem, error)
+	Delete(ctx context.Context, userID, productID string) error
+	// MarkBackInStockAlerted and MarkPriceAlerted record a sent alert and
+	// clear it if it was deferred.
+	MarkBackInStockAlerted(ctx context.Context, id string, at time.Time) error
+	MarkPriceAlerted(ctx context.Context, id string, price domain.Money) error
+	// ScheduleAlert defers an alert until dueAt; nil cancels it.
+	ScheduleAlert(ctx context.Context, id string, alert domain.WishlistAlert, dueAt *time.Time) error
+	// ListAlertsDue returns up to limit entries with a deferred alert due by
+	// now, with their user and product.
+	ListAlertsDue(ctx context.Context, now time.Time, limit int) ([]domain.WishlistItem, error)
+}
+
+type ReviewRepository interface {
+	Create(ctx context.Context, review *domain.Review) error
+	GetByID(ctx context.Context, id string) (*domain.Review, error)
+	GetByProductAndUser(ctx context.Context, productID, userID string) (*domain.Review, error)
+	List(ctx context.Context, query dto.ReviewQuery) ([]domain.Review, int64, error)
+	Update(ctx context.Context, review *domain.Review) error
+	// RefreshProductRating recomputes the product's rating average and count
+	// from its approved reviews.
+	RefreshProductRating(ctx context.Context, productID string) error
+}
+
+type OutboxRepository interface {
+	Create(ctx context.Context, event *domain.OutboxEvent) error
+	// FetchDue locks up to limit pending events whose next attempt is due.
+	// It must be called inside a transaction.
+	FetchDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error)
+	Update(ctx context.Context, event *domain.OutboxEvent) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ProcessedEventRepository interface {
+	Exists(ctx context.Context, consumer, eventID string) (bool, error)
+	Create(ctx context.Context, processed *domain.ProcessedEvent) error
+}
+
+type WebhookEndpointRepository interface {
+	Create(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	List(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	ListActive(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	Update(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	Delete(ctx context.Context, id string) error
+	RecordSuccess(ctx context.Context, id string) error
+	// RecordFailure bumps the failure streak and disables the endpoint once
+	// it reaches disableAfter. It reports whether this call disabled it.
+	RecordFailure(ctx context.Context, id string, disableAfter int) (bool, error)
+}
+
+type WebhookDeliveryRepository interface {
+	CreateBatch(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	ListByEndpoint(ctx context.Context, endpointID string, query dto.WebhookDeliveryQuery) ([]domain.WebhookDelivery, int64, error)
+	// FetchDue locks up to limit pending deliveries of active endpoints
+	// whose next attempt is due. It must be called inside a transaction.
+	FetchDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *domain.WebhookDelivery) error
 }
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeSumSQL sums an inventory_levels column for a product over active
+// warehouses. It is correlated on products.id.
+const activeSumSQL = `(SELECT COALESCE(SUM(inventory_levels.%s), 0) FROM inventory_levels
+	JOIN warehouses ON warehouses.id = inventory_levels.warehouse_id AND warehouses.is_active AND warehouses.deleted_at IS NULL
+	WHERE inventory_levels.product_id = products.id AND inventory_levels.deleted_at IS NULL)`
+
+// activeStock recomputes Product.Stock and Product.Reserved.
+func activeStock() map[string]interface{} {
+	return map[string]interface{}{
+		"stock":    gorm.Expr(fmt.Sprintf(activeSumSQL, "quantity")),
+		"reserved": gorm.Expr(fmt.Sprintf(activeSumSQL, "reserved")),
+	}
+}
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+func (r *inventoryRepository) ListByProduct(ctx context.Context, productID string) ([]domain.InventoryLevel, error) {
+	var levels []domain.InventoryLevel
+	err := dbFrom(ctx, r.db).Preload("Warehouse").
+		Joins("JOIN warehouses ON warehouses.id = inventory_levels.warehouse_id AND warehouses.deleted_at IS NULL").
+		Where("inventory_levels.product_id = ?", productID).
+		Order("warehouses.priority ASC").
+		Find(&levels).Error
+	return levels, err
+}
+
+func (r *inventoryRepository) ListByWarehouse(ctx context.Context, warehouseID string, page, limit int) ([]domain.InventoryLevel, int64, error) {
+	var levels []domain.InventoryLevel
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.InventoryLevel{}).Where("warehouse_id = ?", warehouseID)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	err := db.Preload("Product").Order("quantity ASC").Offset(offset).Limit(limit).Find(&levels).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return levels, total, nil
+}
+
+func (r *inventoryRepository) LockAvailable(ctx context.Context, productIDs []string) ([]domain.InventoryLevel, error) {
+	var levels []domain.InventoryLevel
+	err := dbFrom(ctx, r.db).Preload("Warehouse").
+		Joins("JOIN warehouses ON warehouses.id = inventory_levels.warehouse_id AND warehouses.is_active AND warehouses.deleted_at IS NULL").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "inventory_levels"}}).
+		Where("inventory_levels.product_id IN ? AND inventory_levels.quantity > inventory_levels.reserved", productIDs).
+		Order("inventory_levels.product_id").Order("inventory_levels.warehouse_id").
+		Find(&levels).Error
+	return levels, err
+}
+
+func (r *inventoryRepository) LockByProduct(ctx context.Context, productID string) error {
+	var levels []domain.InventoryLevel
+	return dbFrom(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).
+		Order("warehouse_id").
+		Find(&levels).Error
+}
+
+func (r *inventoryRepository) Adjust(ctx context.Context, productID, warehouseID string, delta int) error {
+	db := dbFrom(ctx, r.db)
+
+	level := domain.InventoryLevel{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    delta,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "warehouse_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"quantity":   gorm.Expr("inventory_levels.quantity + EXCLUDED.quantity"),
+			"updated_at": time.Now(),
+			"deleted_at": nil,
+		}),
+	}).Create(&level).Error
+	if err != nil {
+		return err
+	}
+
+	return r.checkLevel(ctx, productID, warehouseID)
+}
+
+func (r *inventoryRepository) Hold(ctx context.Context, productID, warehouseID string, delta int) error {
+	err := dbFrom(ctx, r.db).Model(&domain.InventoryLevel{}).
+		Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).
+		UpdateColumns(map[string]interface{}{
+			"reserved":   gorm.Expr("reserved + ?", delta),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return r.checkLevel(ctx, productID, warehouseID)
+}
+
+// checkLevel fails with ErrInsufficientStock when a level holds less stock
+// than it has reserved.
+func (r *inventoryRepository) checkLevel(ctx context.Context, productID, warehouseID string) error {
+	var level domain.InventoryLevel
+	err := dbFrom(ctx, r.db).Select("quantity", "reserved").
+		Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).
+		Take(&level).Error
+	if err != nil {
+		return err
+	}
+	if level.Reserved < 0 || level.Available() < 0 {
+		return domain.ErrInsufficientStock
+	}
+
+	return nil
+}
+
+func (r *inventoryRepository) CountStock(ctx context.Context, warehouseID string) (int64, error) {
+	var total int64
+	err := dbFrom(ctx, r.db).Model(&domain.InventoryLevel{}).
+		Where("warehouse_id = ?", warehouseID).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *inventoryRepository) SyncProductStock(ctx context.Context, productIDs []string) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&domain.Product{}).
+		Where("id IN ?", productIDs).
+		UpdateColumns(activeStock()).Error
+}
+
+func (r *inventoryRepository) SyncWarehouseProducts(ctx context.Context, warehouseID string) error {
+	stocked := dbFrom(ctx, r.db).Model(&domain.InventoryLevel{}).
+		Select("product_id").
+		Where("warehouse_id = ?", warehouseID)
+
+	return dbFrom(ctx, r.db).Model(&domain.Product{}).
+		Where("id IN (?)", stocked).
+		UpdateColumns(activeStock()).Error
+}
+
+type stockTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewStockTransferRepository(db *gorm.DB) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+func (r *stockTransferRepository) Create(ctx context.Context, transfer *domain.StockTransfer) error {
+	return dbFrom(ctx, r.db).Create(transfer).Error
+}
+
+func (r *stockTransferRepository) List(ctx context.Context, query dto.StockTransferQuery) ([]domain.StockTransfer, int64, error) {
+	var transfers []domain.StockTransfer
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.StockTransfer{})
+
+	if query.ProductID != "" {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+
+	if query.WarehouseID != "" {
+		db = db.Where("from_warehouse_id = ? OR to_warehouse_id = ?", query.WarehouseID, query.WarehouseID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := db.Preload("Product").Preload("FromWarehouse").Preload("ToWarehouse").
+		Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&transfers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+type stockReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository(db *gorm.DB) StockReservationRepository {
+	return &stockReservationRepository{db: db}
+}
+
+func (r *stockReservationRepository) Create(ctx context.Context, reservations []domain.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Create(&reservations).Error
+}
+
+func (r *stockReservationRepository) LockActiveByOrder(ctx context.Context, orderID string) ([]domain.StockReservation, error) {
+	var reservations []domain.StockReservation
+	err := dbFrom(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status = ?", orderID, domain.ReservationStatusActive).
+		Order("product_id").Order("warehouse_id").
+		Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *stockReservationRepository) UpdateStatus(ctx context.Context, ids []string, status domain.ReservationStatus) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&domain.StockReservation{}).
+		Where("id IN ?", ids).
+		Update("status", status).Error
+}
+
+func (r *stockReservationRepository) ListExpiredOrderIDs(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var orderIDs []string
+	err := dbFrom(ctx, r.db).Model(&domain.StockReservation{}).
+		Distinct("order_id").
+		Where("status = ? AND expires_at <= ?", domain.ReservationStatusActive, now).
+		Limit(limit).
+		Pluck("order_id", &orderIDs).Error
+	return orderIDs, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"gorm.io/gorm"
+)
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	return dbFrom(ctx, r.db).Create(attempt).Error
+}
+
+func (r *loginAttemptRepository) List(ctx context.Context, query dto.LoginAttemptQuery) ([]domain.LoginAttempt, int64, error) {
+	var attempts []domain.LoginAttempt
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.LoginAttempt{})
+
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+
+	if query.Email != "" {
+		db = db.Where("email = ?", query.Email)
+	}
+
+	if query.IP != "" {
+		db = db.Where("ip_address = ?", query.IP)
+	}
+
+	if query.Result != "" {
+		db = db.Where("result = ?", query.Result)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := db.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&attempts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return attempts, total, nil
+}
+
+func (r *loginAttemptRepository) CountFailedByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&domain.LoginAttempt{}).
+		Where("ip_address = ? AND result = ? AND created_at >= ?", ip, domain.LoginAttemptInvalidCredentials, since).
+		Count(&count).Error
+	return count, err
+}
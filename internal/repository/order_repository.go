@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type orderRepository struct {
@@ -13,13 +16,28 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(order *domain.Order) error {
-	return r.db.Create(order).Error
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error {
+	return dbFrom(ctx, r.db).Create(order).Error
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id string) (*domain.Order, error) {
+	var order domain.Order
+	err := dbFrom(ctx, r.db).Preload("User").Preload("OrderItems.Product.Category").Preload("Allocations.Warehouse").Where("id = ?", id).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &order, nil
 }
 
-func (r *orderRepository) GetByID(id string) (*domain.Order, error) {
+func (r *orderRepository) LockByID(ctx context.Context, id string) (*domain.Order, error) {
 	var order domain.Order
-	err := r.db.Preload("User").Preload("OrderItems.Product.Category").Where("id = ?", id).First(&order).Error
+	err := dbFrom(ctx, r.db).Preload("User").Preload("OrderItems.Product.Category").Preload("Allocations.Warehouse").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "orders"}}).
+		Where("id = ?", id).First(&order).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrOrderNotFound
@@ -30,9 +48,9 @@ func (r *orderRepository) GetByID(id string) (*domain.Order, error) {
 	return &order, nil
 }
 
-func (r *orderRepository) GetByOrderNumber(orderNumber string) (*domain.Order, error) {
+func (r *orderRepository) GetByOrderNumber(ctx context.Context, orderNumber string) (*domain.Order, error) {
 	var order domain.Order
-	err := r.db.Preload("User").Preload("OrderItems.Product.Category").Where("order_number = ?", orderNumber).First(&order).Error
+	err := dbFrom(ctx, r.db).Preload("User").Preload("OrderItems.Product.Category").Preload("Allocations.Warehouse").Where("order_number = ?", orderNumber).First(&order).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrOrderNotFound
@@ -43,16 +61,16 @@ func (r *orderRepository) GetByOrderNumber(orderNumber string) (*domain.Order, e
 	return &order, nil
 }
 
-func (r *orderRepository) GetByUserID(userID string, page, limit int) ([]domain.Order, int64, error) {
+func (r *orderRepository) GetByUserID(ctx context.Context, userID string, page, limit int) ([]domain.Order, int64, error) {
 	var orders []domain.Order
 	var total int64
 
-	if err := r.db.Model(&domain.Order{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Model(&domain.Order{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	err := r.db.Preload("OrderItems.Product").Where("user_id = ?", userID).Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error
+	err := dbFrom(ctx, r.db).Preload("OrderItems.Product").Where("user_id = ?", userID).Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -60,16 +78,16 @@ func (r *orderRepository) GetByUserID(userID string, page, limit int) ([]domain.
 	return orders, total, nil
 }
 
-func (r *orderRepository) GetAll(page, limit int) ([]domain.Order, int64, error) {
+func (r *orderRepository) GetAll(ctx context.Context, page, limit int) ([]domain.Order, int64, error) {
 	var orders []domain.Order
 	var total int64
 
-	if err := r.db.Model(&domain.Order{}).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Model(&domain.Order{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	err := r.db.Preload("User").Preload("OrderItems.Product").Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error
+	err := dbFrom(ctx, r.db).Preload("User").Preload("OrderItems.Product").Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -77,10 +95,35 @@ func (r *orderRepository) GetAll(page, limit int) ([]domain.Order, int64, error)
 	return orders, total, nil
 }
 
-func (r *orderRepository) Update(order *domain.Order) error {
-	return r.db.Save(order).Error
+func (r *orderRepository) Update(ctx context.Context, order *domain.Order) error {
+	return dbFrom(ctx, r.db).Save(order).Error
 }
 
-func (r *orderRepository) UpdateStatus(id string, status domain.OrderStatus) error {
-	return r.db.Model(&domain.Order{}).Where("id = ?", id).Update("status", status).Error
+func (r *orderRepository) UpdateStatus(ctx context.Context, id string, status domain.OrderStatus) error {
+	return dbFrom(ctx, r.db).Model(&domain.Order{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *orderRepository) ClaimGuestOrders(ctx context.Context, email, userID string) (int64, error) {
+	result := dbFrom(ctx, r.db).Model(&domain.Order{}).
+		Where("user_id IS NULL AND LOWER(customer_email) = LOWER(?)", email).
+		Update("user_id", userID)
+
+	return result.RowsAffected, result.Error
+}
+
+func (r *orderRepository) GetDeliveredItem(ctx context.Context, userID, productID string) (*domain.OrderItem, error) {
+	var item domain.OrderItem
+	err := dbFrom(ctx, r.db).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, domain.OrderStatusDelivered, productID).
+		Order("orders.created_at DESC").
+		First(&item).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+)
+
+type orderSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderSequenceRepository(db *gorm.DB) OrderSequenceRepository {
+	return &orderSequenceRepository{db: db}
+}
+
+func (r *orderSequenceRepository) Next(ctx context.Context) (int64, error) {
+	var next int64
+	err := dbFrom(ctx, r.db).Raw("SELECT nextval(?)", domain.OrderNumberSequence).Scan(&next).Error
+	return next, err
+}
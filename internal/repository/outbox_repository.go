@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, event *domain.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Create(event).Error
+}
+
+// FetchDue uses SKIP LOCKED so several relays can run side by side without
+// delivering the same event twice at once.
+func (r *outboxRepository) FetchDue(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	err := dbFrom(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", domain.OutboxStatusPending, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+func (r *outboxRepository) Update(ctx context.Context, event *domain.OutboxEvent) error {
+	return dbFrom(ctx, r.db).Save(event).Error
+}
+
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFrom(ctx, r.db).Unscoped().
+		Where("status = ? AND published_at < ?", domain.OutboxStatusPublished, before).
+		Delete(&domain.OutboxEvent{})
+
+	return result.RowsAffected, result.Error
+}
+
+type processedEventRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessedEventRepository(db *gorm.DB) ProcessedEventRepository {
+	return &processedEventRepository{db: db}
+}
+
+func (r *processedEventRepository) Exists(ctx context.Context, consumer, eventID string) (bool, error) {
+	var count int64
+	err := dbFrom(ctx, r.db).Model(&domain.ProcessedEvent{}).
+		Where("consumer = ? AND event_id = ?", consumer, eventID).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (r *processedEventRepository) Create(ctx context.Context, processed *domain.ProcessedEvent) error {
+	return dbFrom(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(processed).Error
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type paymentRepository struct {
@@ -13,25 +16,38 @@ func NewPaymentRepository(db *gorm.DB) PaymentRepository {
 	return &paymentRepository{db: db}
 }
 
-func (r *paymentRepository) Create(payment *domain.Payment) error {
-	return r.db.Create(payment).Error
+func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
+	return dbFrom(ctx, r.db).Create(payment).Error
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id string) (*domain.Payment, error) {
+	var payment domain.Payment
+	err := dbFrom(ctx, r.db).Preload("Order.User").Where("id = ?", id).First(&payment).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
 }
 
-func (r *paymentRepository) GetByID(id string) (*domain.Payment, error) {
+func (r *paymentRepository) GetByOrderID(ctx context.Context, orderID string) (*domain.Payment, error) {
 	var payment domain.Payment
-	err := r.db.Preload("Order.User").Where("id = ?", id).First(&payment).Error
+	err := dbFrom(ctx, r.db).Preload("Order.User").Where("order_id = ?", orderID).First(&payment).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrPaymentNotFound
 		}
 		return nil, err
 	}
+
 	return &payment, nil
 }
 
-func (r *paymentRepository) GetByOrderID(orderID string) (*domain.Payment, error) {
+func (r *paymentRepository) GetByMidtransOrderID(ctx context.Context, midtransOrderID string) (*domain.Payment, error) {
 	var payment domain.Payment
-	err := r.db.Preload("Order.User").Where("order_id = ?", orderID).First(&payment).Error
+	err := dbFrom(ctx, r.db).Preload("Order.User").Where("midtrans_order_id = ?", midtransOrderID).First(&payment).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrPaymentNotFound
@@ -42,9 +58,11 @@ func (r *paymentRepository) GetByOrderID(orderID string) (*domain.Payment, error
 	return &payment, nil
 }
 
-func (r *paymentRepository) GetByMidtransOrderID(midtransOrderID string) (*domain.Payment, error) {
+func (r *paymentRepository) LockByMidtransOrderID(ctx context.Context, midtransOrderID string) (*domain.Payment, error) {
 	var payment domain.Payment
-	err := r.db.Preload("Order.User").Where("midtrans_order_id = ?", midtransOrderID).First(&payment).Error
+	err := dbFrom(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "payments"}}).
+		Where("midtrans_order_id = ?", midtransOrderID).First(&payment).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrPaymentNotFound
@@ -55,20 +73,20 @@ func (r *paymentRepository) GetByMidtransOrderID(midtransOrderID string) (*domai
 	return &payment, nil
 }
 
-func (r *paymentRepository) Update(payment *domain.Payment) error {
-	return r.db.Save(payment).Error
+func (r *paymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
+	return dbFrom(ctx, r.db).Save(payment).Error
 }
 
-func (r *paymentRepository) List(page, limit int) ([]domain.Payment, int64, error) {
+func (r *paymentRepository) List(ctx context.Context, page, limit int) ([]domain.Payment, int64, error) {
 	var payments []domain.Payment
 	var total int64
 
-	if err := r.db.Model(&domain.Payment{}).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.db).Model(&domain.Payment{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	err := r.db.Preload("Order.User").Order("created_at DESC").Offset(offset).Limit(limit).Find(&payments).Error
+	err := dbFrom(ctx, r.db).Preload("Order.User").Order("created_at DESC").Offset(offset).Limit(limit).Find(&payments).Error
 	if err != nil {
 		return nil, 0, err
 	}
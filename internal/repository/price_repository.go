@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+)
+
+type priceRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceRuleRepository(db *gorm.DB) PriceRuleRepository {
+	return &priceRuleRepository{db: db}
+}
+
+func (r *priceRuleRepository) Create(ctx context.Context, rule *domain.PriceRule) error {
+	return dbFrom(ctx, r.db).Create(rule).Error
+}
+
+func (r *priceRuleRepository) GetByID(ctx context.Context, id string) (*domain.PriceRule, error) {
+	var rule domain.PriceRule
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&rule).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrPriceRuleNotFound
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *priceRuleRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.PriceRule{}, "id = ?", id).Error
+}
+
+// notEnded matches rules that are running or scheduled at t.
+func notEnded(t time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("ends_at IS NULL OR ends_at > ?", t)
+	}
+}
+
+func (r *priceRuleRepository) ListByProduct(ctx context.Context, productID string, at time.Time) ([]domain.PriceRule, error) {
+	var rules []domain.PriceRule
+	err := dbFrom(ctx, r.db).
+		Scopes(notEnded(at)).
+		Where("product_id = ?", productID).
+		Order("starts_at ASC NULLS FIRST").Order("min_quantity ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *priceRuleRepository) ListActive(ctx context.Context, productIDs []string, at time.Time) ([]domain.PriceRule, error) {
+	var rules []domain.PriceRule
+	err := dbFrom(ctx, r.db).
+		Scopes(notEnded(at)).
+		Where("product_id IN ? AND (starts_at IS NULL OR starts_at <= ?)", productIDs, at).
+		Find(&rules).Error
+	return rules, err
+}
+
+type priceHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPriceHistoryRepository(db *gorm.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+func (r *priceHistoryRepository) Create(ctx context.Context, history *domain.PriceHistory) error {
+	return dbFrom(ctx, r.db).Create(history).Error
+}
+
+func (r *priceHistoryRepository) ListByProduct(ctx context.Context, productID string, page, limit int) ([]domain.PriceHistory, int64, error) {
+	var histories []domain.PriceHistory
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.PriceHistory{}).Where("product_id = ?", productID)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	err := db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&histories).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return histories, total, nil
+}
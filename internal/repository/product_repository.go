@@ -1,9 +1,13 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type productRepository struct {
@@ -14,13 +18,13 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
-func (r *productRepository) Create(product *domain.Product) error {
-	return r.db.Create(product).Error
+func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+	return dbFrom(ctx, r.db).Create(product).Error
 }
 
-func (r *productRepository) GetByID(id string) (*domain.Product, error) {
+func (r *productRepository) GetByID(ctx context.Context, id string) (*domain.Product, error) {
 	var product domain.Product
-	err := r.db.Preload("Category").Where("id = ?", id).First(&product).Error
+	err := dbFrom(ctx, r.db).Preload("Category").Where("id = ?", id).First(&product).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrProductNotFound
@@ -31,9 +35,11 @@ func (r *productRepository) GetByID(id string) (*domain.Product, error) {
 	return &product, nil
 }
 
-func (r *productRepository) GetBySKU(sku string) (*domain.Product, error) {
+func (r *productRepository) LockByID(ctx context.Context, id string) (*domain.Product, error) {
 	var product domain.Product
-	err := r.db.Where("sku = ?", sku).First(&product).Error
+	err := dbFrom(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&product).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrProductNotFound
@@ -44,11 +50,24 @@ func (r *productRepository) GetBySKU(sku string) (*domain.Product, error) {
 	return &product, nil
 }
 
-func (r *productRepository) List(query dto.ProductQuery) ([]domain.Product, int64, error) {
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	var product domain.Product
+	err := dbFrom(ctx, r.db).Where("sku = ?", sku).First(&product).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func (r *productRepository) List(ctx context.Context, query dto.ProductQuery) ([]domain.Product, int64, error) {
 	var products []domain.Product
 	var total int64
 
-	db := r.db.Model(&domain.Product{}).Preload("Category")
+	db := dbFrom(ctx, r.db).Model(&domain.Product{}).Preload("Category")
 
 	if query.Name != "" {
 		db = db.Where("name ILIKE ?", "%"+query.Name+"%")
@@ -58,15 +77,15 @@ func (r *productRepository) List(query dto.ProductQuery) ([]domain.Product, int6
 		db = db.Where("category_id = ?", query.CategoryID)
 	}
 
-	if query.MinPrice > 0 {
+	if query.MinPrice.IsPositive() {
 		db = db.Where("price >= ?", query.MinPrice)
 	}
 
-	if query.MaxPrice > 0 {
+	if query.MaxPrice.IsPositive() {
 		db = db.Where("price >= 0", query.MinPrice)
 	}
 
-	if query.MaxPrice > 0 {
+	if query.MaxPrice.IsPositive() {
 		db = db.Where("price <= ?", query.MaxPrice)
 	}
 
@@ -85,6 +104,11 @@ func (r *productRepository) List(query dto.ProductQuery) ([]domain.Product, int6
 		query.Limit = 10
 	}
 
+	switch query.Sort {
+	case "rating":
+		db = db.Order("rating_average DESC").Order("rating_count DESC")
+	}
+
 	offset := (query.Page - 1) * query.Limit
 	err := db.Offset(offset).Limit(query.Limit).Find(&products).Error
 	if err != nil {
@@ -94,14 +118,75 @@ func (r *productRepository) List(query dto.ProductQuery) ([]domain.Product, int6
 	return products, total, nil
 }
 
-func (r *productRepository) Update(product *domain.Product) error {
-	return r.db.Save(product).Error
+// Update saves product details. Stock is owned by the inventory and is
+// left untouched.
+func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
+	return dbFrom(ctx, r.db).Model(product).
+		Select("name", "description", "price", "sku", "category_id", "image_url", "reorder_level", "reorder_quantity").
+		Updates(product).Error
+}
+
+func (r *productRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.Product{}, "id = ?", id).Error
 }
 
-func (r *productRepository) Delete(id string) error {
-	return r.db.Delete(&domain.Product{}, "id = ?", id).Error
+// lowStock matches active products at or below their reorder level.
+func lowStock(db *gorm.DB) *gorm.DB {
+	return db.Where("is_active = ? AND reorder_level > 0 AND stock <= reorder_level", true)
 }
 
-func (r *productRepository) UpdateStock(id string, quantity int) error {
-	return r.db.Model(&domain.Product{}).Where("id = ?", id).UpdateColumn("stock", gorm.Expr("stock + ?", quantity)).Error
+func (r *productRepository) ListLowStock(ctx context.Context, page, limit int) ([]domain.Product, int64, error) {
+	var products []domain.Product
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.Product{}).Scopes(lowStock)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	err := db.Preload("Category").Order("stock ASC").Order("name ASC").Order("id").Offset(offset).Limit(limit).Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+func (r *productRepository) FetchLowStockToAlert(ctx context.Context, limit int) ([]domain.Product, error) {
+	var products []domain.Product
+	err := dbFrom(ctx, r.db).
+		Scopes(lowStock).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("low_stock_alerted_at IS NULL").
+		Order("stock ASC").
+		Limit(limit).
+		Find(&products).Error
+
+	return products, err
+}
+
+func (r *productRepository) MarkLowStockAlerted(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Model(&domain.Product{}).
+		Where("id IN ?", ids).
+		UpdateColumn("low_stock_alerted_at", at).Error
+}
+
+func (r *productRepository) ResetLowStockAlerts(ctx context.Context) (int64, error) {
+	result := dbFrom(ctx, r.db).Model(&domain.Product{}).
+		Where("low_stock_alerted_at IS NOT NULL AND (reorder_level = 0 OR stock > reorder_level)").
+		UpdateColumn("low_stock_alerted_at", nil)
+
+	return result.RowsAffected, result.Error
 }
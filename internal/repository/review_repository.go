@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"gorm.io/gorm"
+)
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) Create(ctx context.Context, review *domain.Review) error {
+	return dbFrom(ctx, r.db).Create(review).Error
+}
+
+func (r *reviewRepository) GetByID(ctx context.Context, id string) (*domain.Review, error) {
+	var review domain.Review
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&review).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *reviewRepository) GetByProductAndUser(ctx context.Context, productID, userID string) (*domain.Review, error) {
+	var review domain.Review
+	err := dbFrom(ctx, r.db).Where("product_id = ? AND user_id = ?", productID, userID).First(&review).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *reviewRepository) List(ctx context.Context, query dto.ReviewQuery) ([]domain.Review, int64, error) {
+	var reviews []domain.Review
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.Review{})
+
+	if query.ProductID != "" {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+
+	if query.UserID != "" {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := db.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (r *reviewRepository) Update(ctx context.Context, review *domain.Review) error {
+	return dbFrom(ctx, r.db).Save(review).Error
+}
+
+func (r *reviewRepository) RefreshProductRating(ctx context.Context, productID string) error {
+	approved := dbFrom(ctx, r.db).Model(&domain.Review{}).
+		Where("product_id = ? AND status = ?", productID, domain.ReviewStatusApproved)
+
+	return dbFrom(ctx, r.db).Model(&domain.Product{}).
+		Where("id = ?", productID).
+		Updates(map[string]interface{}{
+			"rating_average": gorm.Expr("COALESCE((?), 0)", approved.Session(&gorm.Session{}).Select("ROUND(AVG(rating), 2)")),
+			"rating_count":   gorm.Expr("(?)", approved.Session(&gorm.Session{}).Select("COUNT(*)")),
+		}).Error
+}
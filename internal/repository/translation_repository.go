@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type productTranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewProductTranslationRepository(db *gorm.DB) ProductTranslationRepository {
+	return &productTranslationRepository{db: db}
+}
+
+func (r *productTranslationRepository) Upsert(ctx context.Context, translation *domain.ProductTranslation) error {
+	db := dbFrom(ctx, r.db)
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"name":        translation.Name,
+			"description": translation.Description,
+			"updated_at":  time.Now(),
+			"deleted_at":  nil,
+		}),
+	}).Create(translation).Error
+	if err != nil {
+		return err
+	}
+
+	// On conflict the stored row keeps its ID and created_at.
+	var stored domain.ProductTranslation
+	if err := db.Where("product_id = ? AND locale = ?", translation.ProductID, translation.Locale).First(&stored).Error; err != nil {
+		return err
+	}
+	*translation = stored
+	return nil
+}
+
+func (r *productTranslationRepository) ListByProduct(ctx context.Context, productID string) ([]domain.ProductTranslation, error) {
+	var translations []domain.ProductTranslation
+	err := dbFrom(ctx, r.db).Where("product_id = ?", productID).Order("locale ASC").Find(&translations).Error
+	return translations, err
+}
+
+func (r *productTranslationRepository) ListByLocale(ctx context.Context, productIDs []string, locale string) ([]domain.ProductTranslation, error) {
+	var translations []domain.ProductTranslation
+	err := dbFrom(ctx, r.db).Where("product_id IN ? AND locale = ?", productIDs, locale).Find(&translations).Error
+	return translations, err
+}
+
+func (r *productTranslationRepository) Delete(ctx context.Context, productID, locale string) error {
+	result := dbFrom(ctx, r.db).Delete(&domain.ProductTranslation{}, "product_id = ? AND locale = ?", productID, locale)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTranslationNotFound
+	}
+	return nil
+}
+
+type categoryTranslationRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryTranslationRepository(db *gorm.DB) CategoryTranslationRepository {
+	return &categoryTranslationRepository{db: db}
+}
+
+func (r *categoryTranslationRepository) Upsert(ctx context.Context, translation *domain.CategoryTranslation) error {
+	db := dbFrom(ctx, r.db)
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"name":        translation.Name,
+			"description": translation.Description,
+			"updated_at":  time.Now(),
+			"deleted_at":  nil,
+		}),
+	}).Create(translation).Error
+	if err != nil {
+		return err
+	}
+
+	// On conflict the stored row keeps its ID and created_at.
+	var stored domain.CategoryTranslation
+	if err := db.Where("category_id = ? AND locale = ?", translation.CategoryID, translation.Locale).First(&stored).Error; err != nil {
+		return err
+	}
+	*translation = stored
+	return nil
+}
+
+func (r *categoryTranslationRepository) ListByCategory(ctx context.Context, categoryID string) ([]domain.CategoryTranslation, error) {
+	var translations []domain.CategoryTranslation
+	err := dbFrom(ctx, r.db).Where("category_id = ?", categoryID).Order("locale ASC").Find(&translations).Error
+	return translations, err
+}
+
+func (r *categoryTranslationRepository) ListByLocale(ctx context.Context, categoryIDs []string, locale string) ([]domain.CategoryTranslation, error) {
+	var translations []domain.CategoryTranslation
+	err := dbFrom(ctx, r.db).Where("category_id IN ? AND locale = ?", categoryIDs, locale).Find(&translations).Error
+	return translations, err
+}
+
+func (r *categoryTranslationRepository) Delete(ctx context.Context, categoryID, locale string) error {
+	result := dbFrom(ctx, r.db).Delete(&domain.CategoryTranslation{}, "category_id = ? AND locale = ?", categoryID, locale)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrTranslationNotFound
+	}
+	return nil
+}
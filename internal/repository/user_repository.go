@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/affandisy/goshop/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -13,13 +17,13 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{DB: db}
 }
 
-func (r *userRepository) Create(user *domain.User) error {
-	return r.DB.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return dbFrom(ctx, r.DB).Create(user).Error
 }
 
-func (r *userRepository) GetByID(id string) (*domain.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
-	err := r.DB.Where("id = ?", id).First(&user).Error
+	err := dbFrom(ctx, r.DB).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrUserNotFound
@@ -30,9 +34,9 @@ func (r *userRepository) GetByID(id string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	err := r.DB.Where("email = ?", email).First(&user).Error
+	err := dbFrom(ctx, r.DB).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrUserNotFound
@@ -43,24 +47,71 @@ func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *domain.User) error {
-	return r.DB.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return dbFrom(ctx, r.DB).Save(user).Error
+}
+
+// RegisterFailedLogin updates the counters in a single statement so that
+// concurrent failures can't overwrite each other. Each lockout lasts
+// BaseDuration * 2^(previous lockouts), capped at MaxDuration.
+func (r *userRepository) RegisterFailedLogin(ctx context.Context, id string, policy domain.LockoutPolicy, now time.Time) (*domain.User, error) {
+	reached := gorm.Expr("FALSE")
+	if policy.MaxFailedAttempts > 0 {
+		reached = gorm.Expr("failed_login_attempts + 1 >= ?", policy.MaxFailedAttempts)
+	}
+
+	seconds := gorm.Expr("?::double precision", policy.BaseDuration.Seconds())
+	if policy.MaxDuration > 0 {
+		seconds = gorm.Expr("LEAST(?::double precision * power(2, LEAST(lockout_count, 32)), ?::double precision)",
+			policy.BaseDuration.Seconds(), policy.MaxDuration.Seconds())
+	}
+
+	var user domain.User
+	result := dbFrom(ctx, r.DB).Model(&user).Clauses(clause.Returning{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN ? THEN 0 ELSE failed_login_attempts + 1 END", reached),
+			"lockout_count":         gorm.Expr("CASE WHEN ? THEN lockout_count + 1 ELSE lockout_count END", reached),
+			"locked_until":          gorm.Expr("CASE WHEN ? THEN ?::timestamptz + make_interval(secs => ?) ELSE locked_until END", reached, now, seconds),
+			"updated_at":            now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrAccountLocked
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) ResetLoginFailures(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.DB).Model(&domain.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"lockout_count":         0,
+			"locked_until":          nil,
+			"updated_at":            time.Now(),
+		}).Error
 }
 
-func (r *userRepository) Delete(id string) error {
-	return r.DB.Delete(&domain.User{}, "id = ?", id).Error
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.DB).Delete(&domain.User{}, "id = ?", id).Error
 }
 
-func (r *userRepository) List(page, limit int) ([]domain.User, int64, error) {
+func (r *userRepository) List(ctx context.Context, page, limit int) ([]domain.User, int64, error) {
 	var users []domain.User
 	var total int64
 
-	if err := r.DB.Model(&domain.User{}).Count(&total).Error; err != nil {
+	if err := dbFrom(ctx, r.DB).Model(&domain.User{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * limit
-	err := r.DB.Offset(offset).Limit(limit).Find(&users).Error
+	err := dbFrom(ctx, r.DB).Offset(offset).Limit(limit).Find(&users).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+)
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *domain.UserToken) error {
+	return dbFrom(ctx, r.db).Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(ctx context.Context, tokenHash string, purpose domain.UserTokenPurpose) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := dbFrom(ctx, r.db).Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes the token. The used_at guard makes it safe against two
+// concurrent requests redeeming the same token: only one of them succeeds.
+func (r *userTokenRepository) MarkUsed(ctx context.Context, id string) error {
+	result := dbFrom(ctx, r.db).Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
+
+func (r *userTokenRepository) InvalidateForUser(ctx context.Context, userID string, purpose domain.UserTokenPurpose) error {
+	return dbFrom(ctx, r.db).Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+)
+
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) WarehouseRepository {
+	return &warehouseRepository{db: db}
+}
+
+func (r *warehouseRepository) Create(ctx context.Context, warehouse *domain.Warehouse) error {
+	return dbFrom(ctx, r.db).Create(warehouse).Error
+}
+
+func (r *warehouseRepository) GetByID(ctx context.Context, id string) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&warehouse).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) GetByCode(ctx context.Context, code string) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	err := dbFrom(ctx, r.db).Where("code = ?", code).First(&warehouse).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWarehouseNotFound
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) GetDefault(ctx context.Context) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	err := dbFrom(ctx, r.db).Where("is_active = ?", true).Order("priority ASC").Order("created_at ASC").First(&warehouse).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNoActiveWarehouse
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) List(ctx context.Context) ([]domain.Warehouse, error) {
+	var warehouses []domain.Warehouse
+	err := dbFrom(ctx, r.db).Order("priority ASC").Order("code ASC").Find(&warehouses).Error
+	return warehouses, err
+}
+
+func (r *warehouseRepository) Update(ctx context.Context, warehouse *domain.Warehouse) error {
+	return dbFrom(ctx, r.db).Save(warehouse).Error
+}
+
+func (r *warehouseRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.Warehouse{}, "id = ?", id).Error
+}
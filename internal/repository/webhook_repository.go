@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookEndpointRepository(db *gorm.DB) WebhookEndpointRepository {
+	return &webhookEndpointRepository{db: db}
+}
+
+func (r *webhookEndpointRepository) Create(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	return dbFrom(ctx, r.db).Create(endpoint).Error
+}
+
+func (r *webhookEndpointRepository) GetByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&endpoint).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWebhookEndpointNotFound
+		}
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *webhookEndpointRepository) List(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	err := dbFrom(ctx, r.db).Order("created_at ASC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookEndpointRepository) ListActive(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	err := dbFrom(ctx, r.db).Where("is_active = ?", true).Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookEndpointRepository) Update(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	return dbFrom(ctx, r.db).Save(endpoint).Error
+}
+
+func (r *webhookEndpointRepository) Delete(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Delete(&domain.WebhookEndpoint{}, "id = ?", id).Error
+}
+
+func (r *webhookEndpointRepository) RecordSuccess(ctx context.Context, id string) error {
+	return dbFrom(ctx, r.db).Model(&domain.WebhookEndpoint{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		Update("consecutive_failures", 0).Error
+}
+
+func (r *webhookEndpointRepository) RecordFailure(ctx context.Context, id string, disableAfter int) (bool, error) {
+	err := dbFrom(ctx, r.db).Model(&domain.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error
+	if err != nil {
+		return false, err
+	}
+
+	result := dbFrom(ctx, r.db).Model(&domain.WebhookEndpoint{}).
+		Where("id = ? AND is_active = ? AND consecutive_failures >= ?", id, true, disableAfter).
+		Updates(map[string]interface{}{"is_active": false, "disabled_at": time.Now()})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+func (r *webhookDeliveryRepository) CreateBatch(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return dbFrom(ctx, r.db).Create(&deliveries).Error
+}
+
+func (r *webhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := dbFrom(ctx, r.db).Where("id = ?", id).First(&delivery).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) ListByEndpoint(ctx context.Context, endpointID string, query dto.WebhookDeliveryQuery) ([]domain.WebhookDelivery, int64, error) {
+	var deliveries []domain.WebhookDelivery
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	err := db.Order("created_at DESC").Offset(offset).Limit(query.Limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+func (r *webhookDeliveryRepository) FetchDue(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := dbFrom(ctx, r.db).
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id AND webhook_endpoints.is_active AND webhook_endpoints.deleted_at IS NULL").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "webhook_deliveries"}, Options: "SKIP LOCKED"}).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+		Order("webhook_deliveries.next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+func (r *webhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return dbFrom(ctx, r.db).Save(delivery).Error
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"gorm.io/gorm"
+)
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db: db}
+}
+
+func (r *wishlistRepository) Create(ctx context.Context, item *domain.WishlistItem) error {
+	return dbFrom(ctx, r.db).Create(item).Error
+}
+
+func (r *wishlistRepository) GetByUserAndProduct(ctx context.Context, userID, productID string) (*domain.WishlistItem, error) {
+	var item domain.WishlistItem
+	err := dbFrom(ctx, r.db).Preload("Product").Where("user_id = ? AND product_id = ?", userID, productID).First(&item).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrWishlistItemNotFound
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *wishlistRepository) ListByUser(ctx context.Context, userID string, page, limit int) ([]domain.WishlistItem, int64, error) {
+	var items []domain.WishlistItem
+	var total int64
+
+	db := dbFrom(ctx, r.db).Model(&domain.WishlistItem{}).Where("user_id = ?", userID)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	err := db.Preload("Product").Order("created_at DESC").Offset(offset).Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+func (r *wishlistRepository) ListByProduct(ctx context.Context, productID string) ([]domain.WishlistItem, error) {
+	var items []domain.WishlistItem
+	err := dbFrom(ctx, r.db).Preload("User").Where("product_id = ?", productID).Find(&items).Error
+	return items, err
+}
+
+// Delete removes the row outright so the product can be wishlisted again
+// without tripping the unique index.
+func (r *wishlistRepository) Delete(ctx context.Context, userID, productID string) error {
+	result := dbFrom(ctx, r.db).Unscoped().
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&domain.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWishlistItemNotFound
+	}
+
+	return nil
+}
+
+func (r *wishlistRepository) MarkBackInStockAlerted(ctx context.Context, id string, at time.Time) error {
+	return dbFrom(ctx, r.db).Model(&domain.WishlistItem{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"back_in_stock_alerted_at":   at,
+			"back_in_stock_alert_due_at": nil,
+		}).Error
+}
+
+func (r *wishlistRepository) MarkPriceAlerted(ctx context.Context, id string, price domain.Money) error {
+	return dbFrom(ctx, r.db).Model(&domain.WishlistItem{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_alerted_price":      price,
+			"price_drop_alert_due_at": nil,
+		}).Error
+}
+
+func (r *wishlistRepository) ScheduleAlert(ctx context.Context, id string, alert domain.WishlistAlert, dueAt *time.Time) error {
+	column := "back_in_stock_alert_due_at"
+	if alert == domain.WishlistAlertPriceDrop {
+		column = "price_drop_alert_due_at"
+	}
+
+	return dbFrom(ctx, r.db).Model(&domain.WishlistItem{}).
+		Where("id = ?", id).
+		Update(column, dueAt).Error
+}
+
+func (r *wishlistRepository) ListAlertsDue(ctx context.Context, now time.Time, limit int) ([]domain.WishlistItem, error) {
+	var items []domain.WishlistItem
+	err := dbFrom(ctx, r.db).Preload("User").Preload("Product").
+		Where("back_in_stock_alert_due_at <= ? OR price_drop_alert_due_at <= ?", now, now).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
@@ -2,83 +2,112 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/repository"
 	"github.com/affandisy/goshop/pkg/cache"
+	"github.com/affandisy/goshop/pkg/i18n"
 )
 
 type categoryService struct {
-	categoryRepo repository.CategoryRepository
-	cacheService cache.CacheService
+	categoryRepo    repository.CategoryRepository
+	translationRepo repository.CategoryTranslationRepository
+	cacheService    cache.CacheService
 }
 
-func NewCategoryService(categoryRepo repository.CategoryRepository, cacheService cache.CacheService) CategoryService {
-	return &categoryService{categoryRepo: categoryRepo, cacheService: cacheService}
+func NewCategoryService(categoryRepo repository.CategoryRepository, translationRepo repository.CategoryTranslationRepository, cacheService cache.CacheService) CategoryService {
+	return &categoryService{categoryRepo: categoryRepo, translationRepo: translationRepo, cacheService: cacheService}
 }
 
-func (s *categoryService) Create(req dto.CategoryRequest) (*domain.Category, error) {
+func (s *categoryService) Create(ctx context.Context, req dto.CategoryRequest) (*domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.Create")
+	defer span.End()
+
 	category := &domain.Category{
 		Name:        req.Name,
 		Description: req.Description,
 		IsActive:    true,
 	}
 
-	if err := s.categoryRepo.Create(category); err != nil {
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
 	s.cacheService.Delete(ctx, cache.AllCategoriesKey())
 
 	return category, nil
 }
 
-func (s *categoryService) GetByID(id string) (*domain.Category, error) {
-	// return s.categoryRepo.GetByID(id)
-	ctx := context.Background()
+func (s *categoryService) GetByID(ctx context.Context, id string) (*domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.GetByID")
+	defer span.End()
+
+	// return s.categoryRepo.GetByID(ctx, id)
 	cacheKey := cache.CategoryKey(id)
 
 	var cachedCategory domain.Category
 	err := s.cacheService.Get(ctx, cacheKey, &cachedCategory)
 	if err == nil {
-		return &cachedCategory, nil
+		categories := []domain.Category{cachedCategory}
+		if err := localizeCategories(ctx, s.translationRepo, categories); err != nil {
+			return nil, err
+		}
+		return &categories[0], nil
 	}
 
-	category, err := s.categoryRepo.GetByID(id)
+	category, err := s.categoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	s.cacheService.Set(ctx, cacheKey, category, cache.CategoryTTL)
 
-	return category, nil
+	categories := []domain.Category{*category}
+	if err := localizeCategories(ctx, s.translationRepo, categories); err != nil {
+		return nil, err
+	}
+
+	return &categories[0], nil
 }
 
-func (s *categoryService) GetAll() ([]domain.Category, error) {
-	// return s.categoryRepo.GetAll()
-	ctx := context.Background()
+func (s *categoryService) GetAll(ctx context.Context) ([]domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.GetAll")
+	defer span.End()
+
+	// return s.categoryRepo.GetAll(ctx)
 	cacheKey := cache.AllCategoriesKey()
 
 	var cachedCategories []domain.Category
 	err := s.cacheService.Get(ctx, cacheKey, &cachedCategories)
 	if err == nil {
+		if err := localizeCategories(ctx, s.translationRepo, cachedCategories); err != nil {
+			return nil, err
+		}
 		return cachedCategories, nil
 	}
 
-	categories, err := s.categoryRepo.GetAll()
+	categories, err := s.categoryRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	s.cacheService.Set(ctx, cacheKey, categories, cache.CategoryTTL)
 
+	if err := localizeCategories(ctx, s.translationRepo, categories); err != nil {
+		return nil, err
+	}
+
 	return categories, nil
 }
 
-func (s *categoryService) Update(id string, req dto.CategoryRequest) (*domain.Category, error) {
-	category, err := s.categoryRepo.GetByID(id)
+func (s *categoryService) Update(ctx context.Context, id string, req dto.CategoryRequest) (*domain.Category, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.Update")
+	defer span.End()
+
+	category, err := s.categoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +115,10 @@ func (s *categoryService) Update(id string, req dto.CategoryRequest) (*domain.Ca
 	category.Name = req.Name
 	category.Description = req.Description
 
-	if err := s.categoryRepo.Update(category); err != nil {
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
 	s.cacheService.Delete(ctx, cache.CategoryKey(id))
 	s.cacheService.Delete(ctx, cache.AllCategoriesKey())
 	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
@@ -98,23 +126,114 @@ func (s *categoryService) Update(id string, req dto.CategoryRequest) (*domain.Ca
 	return category, nil
 }
 
-func (s *categoryService) Delete(id string) error {
-	_, err := s.categoryRepo.GetByID(id)
+func (s *categoryService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "categoryService.Delete")
+	defer span.End()
+
+	_, err := s.categoryRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	// return s.categoryRepo.Delete(id)
+	// return s.categoryRepo.Delete(ctx, id)
 
-	err = s.categoryRepo.Delete(id)
+	err = s.categoryRepo.Delete(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
 	s.cacheService.Delete(ctx, cache.CategoryKey(id))
 	s.cacheService.Delete(ctx, cache.AllCategoriesKey())
 	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
 
 	return nil
 }
+
+func (s *categoryService) ListTranslations(ctx context.Context, categoryID string) ([]domain.CategoryTranslation, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.ListTranslations")
+	defer span.End()
+
+	if _, err := s.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	return s.translationRepo.ListByCategory(ctx, categoryID)
+}
+
+func (s *categoryService) SetTranslation(ctx context.Context, categoryID, locale string, req dto.TranslationRequest) (*domain.CategoryTranslation, error) {
+	ctx, span := tracer.Start(ctx, "categoryService.SetTranslation")
+	defer span.End()
+
+	locale, err := parseLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	translation := &domain.CategoryTranslation{
+		CategoryID:  categoryID,
+		Locale:      locale,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.translationRepo.Upsert(ctx, translation); err != nil {
+		return nil, err
+	}
+
+	return translation, nil
+}
+
+func (s *categoryService) DeleteTranslation(ctx context.Context, categoryID, locale string) error {
+	ctx, span := tracer.Start(ctx, "categoryService.DeleteTranslation")
+	defer span.End()
+
+	locale, err := parseLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	return s.translationRepo.Delete(ctx, categoryID, locale)
+}
+
+// localizeCategories replaces the text of the categories with translations
+// into the request's locale, where there are any.
+func localizeCategories(ctx context.Context, translationRepo repository.CategoryTranslationRepository, categories []domain.Category) error {
+	locale := i18n.FromContext(ctx)
+	if locale == "" || len(categories) == 0 {
+		return nil
+	}
+
+	categoryIDs := make([]string, len(categories))
+	for i, c := range categories {
+		categoryIDs[i] = c.ID
+	}
+
+	translations, err := translationRepo.ListByLocale(ctx, categoryIDs, locale)
+	if err != nil {
+		return err
+	}
+
+	byCategory := make(map[string]domain.CategoryTranslation, len(translations))
+	for _, t := range translations {
+		byCategory[t.CategoryID] = t
+	}
+	for i := range categories {
+		if t, ok := byCategory[categories[i].ID]; ok {
+			categories[i].Localize(t)
+		}
+	}
+
+	return nil
+}
+
+// parseLocale reads a locale from a request path.
+func parseLocale(locale string) (string, error) {
+	locale = strings.ToLower(locale)
+	if !i18n.Supported(locale) {
+		return "", fmt.Errorf("%w: %q", domain.ErrUnsupportedLocale, locale)
+	}
+	return locale, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/notification"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/cache"
+)
+
+// RegisterEventHandlers subscribes the in-process side effects of domain
+// events to bus. Handlers may see an event more than once and must be
+// idempotent.
+func RegisterEventHandlers(bus *event.Bus, orderRepo repository.OrderRepository, cacheService cache.CacheService, notifier notification.Notifier, webhookService WebhookService, wishlistService WishlistService) {
+	bus.Subscribe("product-cache", event.StockChanged, func(ctx context.Context, e event.Event) error {
+		var payload event.StockChangedPayload
+		if err := e.Decode(&payload); err != nil {
+			return err
+		}
+
+		if err := cacheService.Delete(ctx, cache.ProductKey(payload.ProductID)); err != nil {
+			return err
+		}
+		return cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
+	})
+
+	notify := func(eventType notification.EventType) event.Handler {
+		return func(ctx context.Context, e event.Event) error {
+			order, err := orderRepo.GetByID(ctx, e.AggregateID)
+			if err != nil {
+				return err
+			}
+
+			// Sent before returning, so a failure leaves the event in the
+			// outbox to be retried.
+			n := notification.OrderEvent(eventType, order)
+			n.EventID = e.ID
+			return notifier.Deliver(ctx, n)
+		}
+	}
+
+	bus.Subscribe("order-notifications", event.OrderCreated, notify(notification.EventOrderCreated))
+	bus.Subscribe("order-notifications", event.OrderPaid, notify(notification.EventOrderPaid))
+	bus.Subscribe("order-notifications", event.OrderCancelled, notify(notification.EventOrderCancelled))
+
+	bus.Subscribe("wishlist-alerts", event.StockChanged, wishlistService.HandleEvent)
+	bus.Subscribe("wishlist-alerts", event.ProductUpdated, wishlistService.HandleEvent)
+
+	for _, eventType := range event.Types {
+		bus.Subscribe("merchant-webhooks", eventType, webhookService.HandleEvent)
+	}
+}
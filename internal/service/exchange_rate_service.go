@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+type exchangeRateService struct {
+	rateRepo repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(rateRepo repository.ExchangeRateRepository) ExchangeRateService {
+	return &exchangeRateService{rateRepo: rateRepo}
+}
+
+func (s *exchangeRateService) Create(ctx context.Context, req dto.ExchangeRateRequest) (*domain.ExchangeRate, error) {
+	ctx, span := tracer.Start(ctx, "exchangeRateService.Create")
+	defer span.End()
+
+	currency := parseCurrency(req.Currency)
+	if !currency.Valid() || currency == domain.BaseCurrency {
+		return nil, fmt.Errorf("%w: %q", domain.ErrUnsupportedCurrency, req.Currency)
+	}
+
+	rate := &domain.ExchangeRate{
+		Currency:      currency,
+		Rate:          req.Rate,
+		EffectiveFrom: time.Now(),
+	}
+	if req.EffectiveFrom != nil {
+		rate.EffectiveFrom = *req.EffectiveFrom
+	}
+
+	if err := s.rateRepo.Create(ctx, rate); err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *exchangeRateService) List(ctx context.Context, query dto.ExchangeRateQuery) ([]domain.ExchangeRate, int64, error) {
+	ctx, span := tracer.Start(ctx, "exchangeRateService.List")
+	defer span.End()
+
+	if query.Currency != "" {
+		query.Currency = string(parseCurrency(query.Currency))
+	}
+
+	return s.rateRepo.List(ctx, query)
+}
+
+func (s *exchangeRateService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "exchangeRateService.Delete")
+	defer span.End()
+
+	if _, err := s.rateRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.rateRepo.Delete(ctx, id)
+}
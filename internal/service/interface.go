@@ -1,56 +1,132 @@
 package service
 
 import (
+	"context"
 	"time"
 
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
 )
 
 type UserService interface {
-	Register(req dto.UserRegisterRequest) (*domain.User, error)
-	Login(req dto.UserLoginRequest) (*domain.User, string, error)
-	GetProfile(userID string) (*domain.User, error)
-	UpdateProfile(userID string, req dto.UserRegisterRequest) (*domain.User, error)
-	GetUsers(page, limit int) ([]domain.User, int64, error)
+	Register(ctx context.Context, req dto.UserRegisterRequest) (*domain.User, error)
+	Login(ctx context.Context, req dto.UserLoginRequest, clientIP, userAgent string) (*domain.User, string, error)
+	GetProfile(ctx context.Context, userID string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, userID string, req dto.UserRegisterRequest) (*domain.User, error)
+	GetUsers(ctx context.Context, page, limit int) ([]domain.User, int64, error)
+	GetLoginAttempts(ctx context.Context, query dto.LoginAttemptQuery) ([]domain.LoginAttempt, int64, error)
+	UnlockUser(ctx context.Context, userID string) (*domain.User, error)
+	VerifyEmail(ctx context.Context, token string) (*domain.User, error)
+	ResendVerification(ctx context.Context, email string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
 }
 
 type CategoryService interface {
-	Create(req dto.CategoryRequest) (*domain.Category, error)
-	GetByID(id string) (*domain.Category, error)
-	GetAll() ([]domain.Category, error)
-	Update(id string, req dto.CategoryRequest) (*domain.Category, error)
-	Delete(id string) error
+	Create(ctx context.Context, req dto.CategoryRequest) (*domain.Category, error)
+	GetByID(ctx context.Context, id string) (*domain.Category, error)
+	GetAll(ctx context.Context) ([]domain.Category, error)
+	Update(ctx context.Context, id string, req dto.CategoryRequest) (*domain.Category, error)
+	Delete(ctx context.Context, id string) error
+	ListTranslations(ctx context.Context, categoryID string) ([]domain.CategoryTranslation, error)
+	SetTranslation(ctx context.Context, categoryID, locale string, req dto.TranslationRequest) (*domain.CategoryTranslation, error)
+	DeleteTranslation(ctx context.Context, categoryID, locale string) error
 }
 
 type ProductService interface {
-	Create(req dto.ProductRequest) (*domain.Product, error)
-	GetByID(id string) (*domain.Product, error)
-	List(query dto.ProductQuery) ([]domain.Product, int64, error)
-	Update(id string, req dto.ProductRequest) (*domain.Product, error)
-	Delete(id string) error
-	UpdateStock(id string, quantity int) error
+	Create(ctx context.Context, req dto.ProductRequest) (*domain.Product, error)
+	GetByID(ctx context.Context, id, currency string) (*domain.Product, error)
+	List(ctx context.Context, query dto.ProductQuery) ([]domain.Product, int64, error)
+	Update(ctx context.Context, id string, req dto.ProductRequest) (*domain.Product, error)
+	Delete(ctx context.Context, id string) error
+	UpdateStock(ctx context.Context, id string, quantity int) error
+	ListLowStock(ctx context.Context, page, limit int) ([]domain.Product, int64, error)
+	ListPriceRules(ctx context.Context, productID string) ([]domain.PriceRule, error)
+	CreatePriceRule(ctx context.Context, productID string, req dto.PriceRuleRequest) (*domain.PriceRule, error)
+	DeletePriceRule(ctx context.Context, productID, ruleID string) error
+	ListPriceHistory(ctx context.Context, productID string, page, limit int) ([]domain.PriceHistory, int64, error)
+	ListTranslations(ctx context.Context, productID string) ([]domain.ProductTranslation, error)
+	SetTranslation(ctx context.Context, productID, locale string, req dto.TranslationRequest) (*domain.ProductTranslation, error)
+	DeleteTranslation(ctx context.Context, productID, locale string) error
+}
+
+type InventoryService interface {
+	CreateWarehouse(ctx context.Context, req dto.WarehouseRequest) (*domain.Warehouse, error)
+	GetWarehouse(ctx context.Context, id string) (*domain.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]domain.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, id string, req dto.WarehouseRequest) (*domain.Warehouse, error)
+	DeleteWarehouse(ctx context.Context, id string) error
+	ListWarehouseInventory(ctx context.Context, warehouseID string, page, limit int) ([]domain.InventoryLevel, int64, error)
+	ListProductInventory(ctx context.Context, productID string) ([]domain.InventoryLevel, error)
+	AdjustStock(ctx context.Context, warehouseID string, req dto.AdjustInventoryRequest) ([]domain.InventoryLevel, error)
+	Transfer(ctx context.Context, userID string, req dto.StockTransferRequest) (*domain.StockTransfer, error)
+	ListTransfers(ctx context.Context, query dto.StockTransferQuery) ([]domain.StockTransfer, int64, error)
+}
+
+type ExchangeRateService interface {
+	Create(ctx context.Context, req dto.ExchangeRateRequest) (*domain.ExchangeRate, error)
+	List(ctx context.Context, query dto.ExchangeRateQuery) ([]domain.ExchangeRate, int64, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type WishlistService interface {
+	List(ctx context.Context, userID string, page, limit int) ([]domain.WishlistItem, int64, error)
+	Add(ctx context.Context, userID string, req dto.WishlistRequest) (*domain.WishlistItem, error)
+	Remove(ctx context.Context, userID, productID string) error
+	HandleEvent(ctx context.Context, e event.Event) error
+	SendDueAlerts(ctx context.Context, limit int) error
+}
+
+type ReviewService interface {
+	Create(ctx context.Context, userID, productID string, req dto.ReviewRequest) (*domain.Review, error)
+	ListProductReviews(ctx context.Context, productID string, page, limit int) ([]domain.Review, int64, error)
+	List(ctx context.Context, query dto.ReviewQuery) ([]domain.Review, int64, error)
+	Approve(ctx context.Context, id string) (*domain.Review, error)
+	Hide(ctx context.Context, id string) (*domain.Review, error)
 }
 
 type OrderService interface {
-	CreateOrder(userID string, req dto.CreateOrderRequest) (*domain.Order, error)
-	GetOrderByID(orderID string, userID string, isAdmin bool) (*domain.Order, error)
-	GetMyOrders(userID string, page, limit int) ([]domain.Order, int64, error)
-	GetAllOrders(page, limit int) ([]domain.Order, int64, error)
-	UpdateOrderStatus(orderID string, req dto.UpdateOrderStatusRequest) (*domain.Order, error)
-	CancelOrder(orderID string, userID string) error
+	CreateOrder(ctx context.Context, userID string, req dto.CreateOrderRequest) (*domain.Order, error)
+	CreateGuestOrder(ctx context.Context, req dto.CreateGuestOrderRequest) (*dto.GuestOrderResponse, error)
+	GetGuestOrder(ctx context.Context, orderID, token string) (*domain.Order, error)
+	ClaimGuestOrders(ctx context.Context, userID string) (int64, error)
+	GetOrderByID(ctx context.Context, orderID string, userID string, isAdmin bool) (*domain.Order, error)
+	GetMyOrders(ctx context.Context, userID string, page, limit int) ([]domain.Order, int64, error)
+	GetAllOrders(ctx context.Context, page, limit int) ([]domain.Order, int64, error)
+	UpdateOrderStatus(ctx context.Context, orderID string, req dto.UpdateOrderStatusRequest) (*domain.Order, error)
+	CancelOrder(ctx context.Context, orderID string, userID string) error
+	ExpireOrder(ctx context.Context, orderID string) error
 }
 
 type PaymentService interface {
-	CreatePayment(userID string, req dto.CreatePaymentRequest) (*domain.Payment, error)
-	GetPaymentByID(id string) (*domain.Payment, error)
-	GetPaymentByOrderID(orderID string) (*domain.Payment, error)
-	HandleNotification(notification dto.PaymentNotification) error
-	GetAllPayments(page, limit int) ([]domain.Payment, int64, error)
+	CreatePayment(ctx context.Context, userID string, req dto.CreatePaymentRequest) (*domain.Payment, error)
+	CreateGuestPayment(ctx context.Context, token string, req dto.CreatePaymentRequest) (*domain.Payment, error)
+	GetPaymentByID(ctx context.Context, id string) (*domain.Payment, error)
+	GetPaymentByOrderID(ctx context.Context, orderID string) (*domain.Payment, error)
+	HandleNotification(ctx context.Context, notification dto.PaymentNotification) error
+	GetAllPayments(ctx context.Context, page, limit int) ([]domain.Payment, int64, error)
+}
+
+type OrderNumberGenerator interface {
+	Next(ctx context.Context) (string, error)
 }
 
 type ReportService interface {
-	GenerateUsersReport(format string) ([]byte, string, error)
-	GenerateProductsReport(format string) ([]byte, string, error)
-	GenerateOrdersReport(startDate, endDate time.Time, format string) ([]byte, string, error)
+	GenerateUsersReport(ctx context.Context, format string) ([]byte, string, error)
+	GenerateProductsReport(ctx context.Context, format string) ([]byte, string, error)
+	GenerateOrdersReport(ctx context.Context, startDate, endDate time.Time, format string) ([]byte, string, error)
+	GenerateLowStockReport(ctx context.Context, format string) ([]byte, string, error)
+}
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (*domain.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, id string, req dto.WebhookEndpointRequest) (*domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	RotateSecret(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	ListDeliveries(ctx context.Context, endpointID string, query dto.WebhookDeliveryQuery) ([]domain.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, endpointID, deliveryID string) (*domain.WebhookDelivery, error)
+	HandleEvent(ctx context.Context, e event.Event) error
 }
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/cache"
+)
+
+type inventoryService struct {
+	warehouseRepo repository.WarehouseRepository
+	inventoryRepo repository.InventoryRepository
+	transferRepo  repository.StockTransferRepository
+	productRepo   repository.ProductRepository
+	stock         inventory.Manager
+	transactor    repository.Transactor
+	events        event.Publisher
+	cacheService  cache.CacheService
+}
+
+func NewInventoryService(warehouseRepo repository.WarehouseRepository, inventoryRepo repository.InventoryRepository, transferRepo repository.StockTransferRepository, productRepo repository.ProductRepository, stock inventory.Manager, transactor repository.Transactor, events event.Publisher, cacheService cache.CacheService) InventoryService {
+	return &inventoryService{
+		warehouseRepo: warehouseRepo,
+		inventoryRepo: inventoryRepo,
+		transferRepo:  transferRepo,
+		productRepo:   productRepo,
+		stock:         stock,
+		transactor:    transactor,
+		events:        events,
+		cacheService:  cacheService,
+	}
+}
+
+func (s *inventoryService) CreateWarehouse(ctx context.Context, req dto.WarehouseRequest) (*domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.CreateWarehouse")
+	defer span.End()
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if err := s.checkCodeFree(ctx, code); err != nil {
+		return nil, err
+	}
+
+	warehouse := &domain.Warehouse{
+		Code:     code,
+		Name:     req.Name,
+		Address:  req.Address,
+		City:     req.City,
+		Province: req.Province,
+		Priority: req.Priority,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+
+	if err := s.warehouseRepo.Create(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (s *inventoryService) GetWarehouse(ctx context.Context, id string) (*domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.GetWarehouse")
+	defer span.End()
+
+	return s.warehouseRepo.GetByID(ctx, id)
+}
+
+func (s *inventoryService) ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.ListWarehouses")
+	defer span.End()
+
+	return s.warehouseRepo.List(ctx)
+}
+
+func (s *inventoryService) UpdateWarehouse(ctx context.Context, id string, req dto.WarehouseRequest) (*domain.Warehouse, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.UpdateWarehouse")
+	defer span.End()
+
+	warehouse, err := s.warehouseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code != warehouse.Code {
+		if err := s.checkCodeFree(ctx, code); err != nil {
+			return nil, err
+		}
+	}
+
+	wasActive := warehouse.IsActive
+
+	warehouse.Code = code
+	warehouse.Name = req.Name
+	warehouse.Address = req.Address
+	warehouse.City = req.City
+	warehouse.Province = req.Province
+	warehouse.Priority = req.Priority
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.warehouseRepo.Update(ctx, warehouse); err != nil {
+			return err
+		}
+
+		if warehouse.IsActive == wasActive {
+			return nil
+		}
+
+		// Stock in inactive warehouses is not sellable.
+		return s.inventoryRepo.SyncWarehouseProducts(ctx, warehouse.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if warehouse.IsActive != wasActive {
+		s.cacheService.DeleteByPattern(ctx, cache.ProductPrefix+"*")
+	}
+
+	return warehouse, nil
+}
+
+func (s *inventoryService) DeleteWarehouse(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "inventoryService.DeleteWarehouse")
+	defer span.End()
+
+	if _, err := s.warehouseRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	stock, err := s.inventoryRepo.CountStock(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if stock > 0 {
+		return domain.ErrWarehouseNotEmpty
+	}
+
+	return s.warehouseRepo.Delete(ctx, id)
+}
+
+func (s *inventoryService) ListWarehouseInventory(ctx context.Context, warehouseID string, page, limit int) ([]domain.InventoryLevel, int64, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.ListWarehouseInventory")
+	defer span.End()
+
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.inventoryRepo.ListByWarehouse(ctx, warehouseID, page, limit)
+}
+
+func (s *inventoryService) ListProductInventory(ctx context.Context, productID string) ([]domain.InventoryLevel, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.ListProductInventory")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.inventoryRepo.ListByProduct(ctx, productID)
+}
+
+func (s *inventoryService) AdjustStock(ctx context.Context, warehouseID string, req dto.AdjustInventoryRequest) ([]domain.InventoryLevel, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.AdjustStock")
+	defer span.End()
+
+	if _, err := s.warehouseRepo.GetByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.stock.Adjust(ctx, req.ProductID, warehouseID, req.Quantity); err != nil {
+			return err
+		}
+
+		return s.publishStockChanged(ctx, req.ProductID, warehouseID, req.Quantity, event.StockReasonAdjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateProduct(ctx, req.ProductID)
+
+	return s.inventoryRepo.ListByProduct(ctx, req.ProductID)
+}
+
+func (s *inventoryService) Transfer(ctx context.Context, userID string, req dto.StockTransferRequest) (*domain.StockTransfer, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.Transfer")
+	defer span.End()
+
+	if req.FromWarehouseID == req.ToWarehouseID {
+		return nil, domain.ErrInvalidTransfer
+	}
+
+	for _, id := range []string{req.FromWarehouseID, req.ToWarehouseID} {
+		if _, err := s.warehouseRepo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	transfer := &domain.StockTransfer{
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Note:            req.Note,
+		CreatedBy:       userID,
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.stock.Adjust(ctx, req.ProductID, req.FromWarehouseID, -req.Quantity); err != nil {
+			return err
+		}
+
+		if err := s.stock.Adjust(ctx, req.ProductID, req.ToWarehouseID, req.Quantity); err != nil {
+			return err
+		}
+
+		if err := s.transferRepo.Create(ctx, transfer); err != nil {
+			return err
+		}
+
+		if err := s.publishStockChanged(ctx, req.ProductID, req.FromWarehouseID, -req.Quantity, event.StockReasonTransfer); err != nil {
+			return err
+		}
+
+		return s.publishStockChanged(ctx, req.ProductID, req.ToWarehouseID, req.Quantity, event.StockReasonTransfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateProduct(ctx, req.ProductID)
+
+	return transfer, nil
+}
+
+func (s *inventoryService) ListTransfers(ctx context.Context, query dto.StockTransferQuery) ([]domain.StockTransfer, int64, error) {
+	ctx, span := tracer.Start(ctx, "inventoryService.ListTransfers")
+	defer span.End()
+
+	return s.transferRepo.List(ctx, query)
+}
+
+func (s *inventoryService) checkCodeFree(ctx context.Context, code string) error {
+	existing, err := s.warehouseRepo.GetByCode(ctx, code)
+	if err != nil && !errors.Is(err, domain.ErrWarehouseNotFound) {
+		return err
+	}
+
+	if existing != nil {
+		return domain.ErrWarehouseAlreadyExists
+	}
+
+	return nil
+}
+
+// publishStockChanged announces a change to one warehouse with the
+// product's resulting available stock.
+func (s *inventoryService) publishStockChanged(ctx context.Context, productID, warehouseID string, delta int, reason event.StockReason) error {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	return s.events.Publish(ctx, event.StockChanged, productID, event.StockChangedPayload{
+		ProductID:   productID,
+		Delta:       delta,
+		Stock:       product.Available(),
+		Reason:      reason,
+		WarehouseID: warehouseID,
+	})
+}
+
+func (s *inventoryService) invalidateProduct(ctx context.Context, productID string) {
+	s.cacheService.Delete(ctx, cache.ProductKey(productID))
+	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+// OrderLinks builds storefront links to orders. Guest links carry a signed
+// order access token in place of a login.
+type OrderLinks struct {
+	BaseURL       string
+	GuestTokenTTL time.Duration
+}
+
+func (l OrderLinks) GuestToken(order *domain.Order) (string, error) {
+	return utils.GenerateOrderAccessToken(order.ID, order.CustomerEmail, l.GuestTokenTTL)
+}
+
+func (l OrderLinks) GuestURL(token string) string {
+	return strings.TrimRight(l.BaseURL, "/") + "/orders/track?token=" + url.QueryEscape(token)
+}
+
+// URL returns the link for order, or "" if a guest token cannot be issued.
+func (l OrderLinks) URL(order *domain.Order) string {
+	if !order.IsGuest() {
+		return strings.TrimRight(l.BaseURL, "/") + "/orders/" + order.ID
+	}
+
+	token, err := l.GuestToken(order)
+	if err != nil {
+		return ""
+	}
+	return l.GuestURL(token)
+}
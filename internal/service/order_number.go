@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// orderNumberGenerator numbers orders from a database sequence, so orders
+// placed at the same moment, on any instance, never share a number.
+type orderNumberGenerator struct {
+	sequence repository.OrderSequenceRepository
+	format   domain.OrderNumberFormat
+	now      func() time.Time
+}
+
+func NewOrderNumberGenerator(sequence repository.OrderSequenceRepository, format domain.OrderNumberFormat) OrderNumberGenerator {
+	return &orderNumberGenerator{sequence: sequence, format: format, now: time.Now}
+}
+
+func (g *orderNumberGenerator) Next(ctx context.Context) (string, error) {
+	ctx, span := tracer.Start(ctx, "orderNumberGenerator.Next")
+	defer span.End()
+
+	seq, err := g.sequence.Next(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return g.format.Format(g.now(), seq), nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/database/databasetest"
+)
+
+func TestOrderNumbersAreUniqueUnderConcurrency(t *testing.T) {
+	db := databasetest.Open(t)
+	generator := NewOrderNumberGenerator(repository.NewOrderSequenceRepository(db), domain.OrderNumberFormat{
+		Layout:         "ORD-{date}-{seq}{check}",
+		SequenceDigits: 6,
+	})
+
+	const orders, workers = 5000, 32
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		numbers = make(map[string]bool, orders)
+		jobs    = make(chan struct{})
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				number, err := generator.Next(context.Background())
+				if err != nil {
+					t.Errorf("Next: %v", err)
+					continue
+				}
+
+				mu.Lock()
+				if numbers[number] {
+					t.Errorf("order number %s handed out twice", number)
+				}
+				numbers[number] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < orders; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(numbers) != orders {
+		t.Errorf("got %d distinct order numbers, want %d", len(numbers), orders)
+	}
+	for number := range numbers {
+		if !strings.HasPrefix(number, "ORD-") {
+			t.Fatalf("order number %q does not follow the layout", number)
+		}
+	}
+}
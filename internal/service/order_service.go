@@ -1,130 +1,484 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/affandisy/goshop/internal/currency"
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
+	"github.com/affandisy/goshop/internal/notification"
 	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
+	"github.com/affandisy/goshop/pkg/utils"
+	"github.com/google/uuid"
 )
 
 type orderService struct {
-	orderRepo   repository.OrderRepository
-	productRepo repository.ProductRepository
+	orderRepo      repository.OrderRepository
+	productRepo    repository.ProductRepository
+	priceRuleRepo  repository.PriceRuleRepository
+	userRepo       repository.UserRepository
+	transactor     repository.Transactor
+	stock          inventory.Manager
+	reservationTTL time.Duration
+	events         event.Publisher
+	notifier       notification.Notifier
+	links          OrderLinks
+	orderNumbers   OrderNumberGenerator
+	converter      currency.Converter
+	logger         *slog.Logger
 }
 
-func NewOrderService(orderRepo repository.OrderRepository, productRepo repository.ProductRepository) OrderService {
-	return &orderService{orderRepo: orderRepo, productRepo: productRepo}
+func NewOrderService(orderRepo repository.OrderRepository, productRepo repository.ProductRepository, priceRuleRepo repository.PriceRuleRepository, userRepo repository.UserRepository, transactor repository.Transactor, stock inventory.Manager, reservationTTL time.Duration, events event.Publisher, notifier notification.Notifier, links OrderLinks, orderNumbers OrderNumberGenerator, converter currency.Converter, logger *slog.Logger) OrderService {
+	return &orderService{orderRepo: orderRepo, productRepo: productRepo, priceRuleRepo: priceRuleRepo, userRepo: userRepo, transactor: transactor, stock: stock, reservationTTL: reservationTTL, events: events, notifier: notifier, links: links, orderNumbers: orderNumbers, converter: converter, logger: logger}
 }
 
-func (s *orderService) CreateOrder(userID string, req dto.CreateOrderRequest) (*domain.Order, error) {
+func (s *orderService) CreateOrder(ctx context.Context, userID string, req dto.CreateOrderRequest) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "orderService.CreateOrder")
+	defer span.End()
+
 	if len(req.Items) == 0 {
 		return nil, domain.ErrEmptyCart
 	}
 
-	orderNumber := generateOrderNumber()
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	orderNumber, err := s.orderNumbers.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	order := &domain.Order{
-		OrderNumber: orderNumber,
-		UserID:      userID,
-		Status:      domain.OrderStatusPending,
-		Notes:       req.Notes,
-		OrderItems:  []domain.OrderItem{},
+		OrderNumber:   orderNumber,
+		UserID:        &userID,
+		Status:        domain.OrderStatusPending,
+		Notes:         req.Notes,
+		CustomerName:  user.Name,
+		CustomerEmail: user.Email,
+		CustomerPhone: user.Phone,
+		OrderItems:    []domain.OrderItem{},
+	}
+	if req.ShippingAddress != nil {
+		order.ShippingAddress = req.ShippingAddress.ToDomain()
+	}
+
+	if err := s.placeOrder(ctx, order, req.Items, req.Currency); err != nil {
+		return nil, err
+	}
+
+	order, _ = s.orderRepo.GetByID(ctx, order.ID)
+	s.setDisplayTotal(ctx, order)
+
+	return order, nil
+}
+
+func (s *orderService) CreateGuestOrder(ctx context.Context, req dto.CreateGuestOrderRequest) (*dto.GuestOrderResponse, error) {
+	ctx, span := tracer.Start(ctx, "orderService.CreateGuestOrder")
+	defer span.End()
+
+	if len(req.Items) == 0 {
+		return nil, domain.ErrEmptyCart
+	}
+
+	orderNumber, err := s.orderNumbers.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &domain.Order{
+		OrderNumber:     orderNumber,
+		Status:          domain.OrderStatusPending,
+		Notes:           req.Notes,
+		CustomerName:    req.Contact.Name,
+		CustomerEmail:   strings.ToLower(strings.TrimSpace(req.Contact.Email)),
+		CustomerPhone:   req.Contact.Phone,
+		ShippingAddress: req.ShippingAddress.ToDomain(),
+		OrderItems:      []domain.OrderItem{},
+	}
+
+	if err := s.placeOrder(ctx, order, req.Items, req.Currency); err != nil {
+		return nil, err
+	}
+
+	token, err := s.links.GuestToken(order)
+	if err != nil {
+		return nil, err
+	}
+
+	order, _ = s.orderRepo.GetByID(ctx, order.ID)
+	s.setDisplayTotal(ctx, order)
+
+	return &dto.GuestOrderResponse{
+		Order:       order,
+		AccessToken: token,
+		TrackingURL: s.links.GuestURL(token),
+	}, nil
+}
+
+// GetGuestOrder returns the guest order the access token was issued for.
+func (s *orderService) GetGuestOrder(ctx context.Context, orderID, token string) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "orderService.GetGuestOrder")
+	defer span.End()
+
+	claims, err := utils.ValidateOrderAccessToken(token)
+	if err != nil || claims.OrderID != orderID {
+		return nil, domain.ErrInvalidOrderToken
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
 	}
 
-	totalAmount := 0.0
+	// The token outlives a claim, so it must not reveal the account the
+	// order was claimed into.
+	order.User = nil
 
-	for _, item := range req.Items {
-		product, err := s.productRepo.GetByID(item.ProductID)
+	s.setDisplayTotal(ctx, order)
+
+	return order, nil
+}
+
+// ClaimGuestOrders moves guest orders placed with the user's verified email
+// address into their account.
+func (s *orderService) ClaimGuestOrders(ctx context.Context, userID string) (int64, error) {
+	ctx, span := tracer.Start(ctx, "orderService.ClaimGuestOrders")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !user.IsEmailVerified() {
+		return 0, domain.ErrEmailNotVerified
+	}
+
+	claimed, err := s.orderRepo.ClaimGuestOrders(ctx, user.Email, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	if claimed > 0 {
+		s.logger.InfoContext(ctx, "Guest orders claimed",
+			slog.String("user_id", user.ID),
+			slog.Int64("count", claimed),
+		)
+	}
+
+	return claimed, nil
+}
+
+// placeOrder reserves stock for items in the warehouses until the order is
+// paid, and stores the order with its events in one transaction. The order
+// records the exchange rate of the display currency code.
+func (s *orderService) placeOrder(ctx context.Context, order *domain.Order, items []dto.OrderItemRequest, code string) error {
+	stockOuts := 0
+
+	rate, err := s.converter.Rate(ctx, parseCurrency(code))
+	if err != nil {
+		return err
+	}
+	order.Currency = rate.Currency
+	order.ExchangeRate = rate.Rate
+
+	// The reservations reference the order before it is stored.
+	order.ID = uuid.New().String()
+	now := time.Now()
+	reservedUntil := now.Add(s.reservationTTL)
+	order.ReservedUntil = &reservedUntil
+
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		rules, err := s.priceRuleRepo.ListActive(ctx, productIDs, now)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		if !product.IsAvailable() {
-			return nil, fmt.Errorf("product %s is not available", product.Name)
+		rulesByProduct := make(map[string][]domain.PriceRule)
+		for _, rule := range rules {
+			rulesByProduct[rule.ProductID] = append(rulesByProduct[rule.ProductID], rule)
 		}
 
-		if product.Stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product %s", product.Name)
+		var totalAmount domain.Money
+		var lines []inventory.Line
+
+		for _, item := range items {
+			product, err := s.productRepo.GetByID(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+
+			if !product.IsAvailable() {
+				return fmt.Errorf("%w: %s", domain.ErrProductNotAvailable, product.Name)
+			}
+
+			if product.Available() < item.Quantity {
+				return fmt.Errorf("%w for product %s", domain.ErrInsufficientStock, product.Name)
+			}
+
+			price, rule, err := domain.ResolvePrice(product.Price, rulesByProduct[product.ID], item.Quantity, now)
+			if err != nil {
+				return err
+			}
+
+			orderItem := domain.OrderItem{
+				ProductID: product.ID,
+				Quantity:  item.Quantity,
+				Price:     price,
+				ListPrice: product.Price,
+			}
+			if rule != nil {
+				orderItem.PriceRuleID = &rule.ID
+				orderItem.PriceRuleName = rule.Label()
+			}
+
+			order.OrderItems = append(order.OrderItems, orderItem)
+			if totalAmount, err = totalAmount.Add(price.Mul(item.Quantity)); err != nil {
+				return err
+			}
+
+			lines = append(lines, inventory.Line{ProductID: product.ID, Quantity: item.Quantity})
 		}
 
-		orderItem := domain.OrderItem{
-			ProductID: product.ID,
-			Quantity:  item.Quantity,
-			Price:     product.Price,
+		allocations, err := s.stock.Reserve(ctx, order.ID, lines, inventory.Destination{
+			City:     order.ShippingAddress.City,
+			Province: order.ShippingAddress.Province,
+		}, reservedUntil)
+		if err != nil {
+			return err
 		}
 
-		order.OrderItems = append(order.OrderItems, orderItem)
-		totalAmount += product.Price * float64(item.Quantity)
+		order.TotalAmount = totalAmount
+		order.Allocations = allocations
 
-		if err := product.ReduceStock(item.Quantity); err != nil {
-			return nil, err
+		if err := s.orderRepo.Create(ctx, order); err != nil {
+			return err
 		}
 
-		if err := s.productRepo.Update(product); err != nil {
-			return nil, err
+		if err := s.events.Publish(ctx, event.OrderCreated, order.ID, orderCreatedPayload(order)); err != nil {
+			return err
 		}
-	}
 
-	order.TotalAmount = totalAmount
+		for _, item := range order.OrderItems {
+			product, err := s.productRepo.GetByID(ctx, item.ProductID)
+			if err != nil {
+				return err
+			}
+
+			if product.Available() == 0 {
+				stockOuts++
+			}
+
+			if err := s.events.Publish(ctx, event.StockChanged, product.ID, event.StockChangedPayload{
+				ProductID: product.ID,
+				Delta:     -item.Quantity,
+				Stock:     product.Available(),
+				Reason:    event.StockReasonOrder,
+				OrderID:   order.ID,
+			}); err != nil {
+				return err
+			}
+		}
 
-	if err := s.orderRepo.Create(order); err != nil {
-		return nil, err
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	order, _ = s.orderRepo.GetByID(order.ID)
-
-	return order, nil
+	metrics.OrdersCreatedTotal.Inc()
+	metrics.StockOutsTotal.Add(float64(stockOuts))
+	s.logger.InfoContext(ctx, "Order created",
+		slog.String("order_id", order.ID),
+		slog.String("order_number", order.OrderNumber),
+		slog.String("user_id", order.OwnerID()),
+		slog.Bool("guest", order.IsGuest()),
+		slog.Int("allocations", len(order.Allocations)),
+	)
+
+	return nil
 }
 
-func (s *orderService) GetOrderByID(orderID string, userID string, isAdmin bool) (*domain.Order, error) {
-	order, err := s.orderRepo.GetByID(orderID)
+func (s *orderService) GetOrderByID(ctx context.Context, orderID string, userID string, isAdmin bool) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "orderService.GetOrderByID")
+	defer span.End()
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !isAdmin && order.UserID != userID {
+	if !isAdmin && !order.IsOwnedBy(userID) {
 		return nil, domain.ErrForbidden
 	}
 
+	s.setDisplayTotal(ctx, order)
+
 	return order, nil
 }
 
-func (s *orderService) GetMyOrders(userID string, page, limit int) ([]domain.Order, int64, error) {
-	return s.orderRepo.GetByUserID(userID, page, limit)
-}
+func (s *orderService) GetMyOrders(ctx context.Context, userID string, page, limit int) ([]domain.Order, int64, error) {
+	ctx, span := tracer.Start(ctx, "orderService.GetMyOrders")
+	defer span.End()
+
+	orders, total, err := s.orderRepo.GetByUserID(ctx, userID, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
 
-func (s *orderService) GetAllOrders(page, limit int) ([]domain.Order, int64, error) {
-	return s.orderRepo.GetAll(page, limit)
+	for i := range orders {
+		s.setDisplayTotal(ctx, &orders[i])
+	}
+
+	return orders, total, nil
 }
 
-func (s *orderService) UpdateOrderStatus(orderID string, req dto.UpdateOrderStatusRequest) (*domain.Order, error) {
-	order, err := s.orderRepo.GetByID(orderID)
+func (s *orderService) GetAllOrders(ctx context.Context, page, limit int) ([]domain.Order, int64, error) {
+	ctx, span := tracer.Start(ctx, "orderService.GetAllOrders")
+	defer span.End()
+
+	orders, total, err := s.orderRepo.GetAll(ctx, page, limit)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	for i := range orders {
+		s.setDisplayTotal(ctx, &orders[i])
 	}
 
-	order.Status = req.Status
+	return orders, total, nil
+}
+
+// setDisplayTotal converts the order total into the customer's currency at
+// the rate recorded at checkout.
+func (s *orderService) setDisplayTotal(ctx context.Context, order *domain.Order) {
+	if order == nil || order.Currency == "" || order.Currency == domain.BaseCurrency {
+		return
+	}
 
-	if req.Status == domain.OrderStatusPaid {
-		order.MarkAsPaid()
+	rate := domain.ExchangeRate{Currency: order.Currency, Rate: order.ExchangeRate}
+	total, err := s.converter.Convert(order.TotalAmount, rate)
+	if err != nil {
+		// The order is still shown, in the base currency.
+		s.logger.WarnContext(ctx, "Failed to convert order total",
+			slog.String("order_id", order.ID),
+			slog.Any("error", err),
+		)
+		return
 	}
+	order.DisplayTotal = &total
+}
+
+func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID string, req dto.UpdateOrderStatusRequest) (*domain.Order, error) {
+	ctx, span := tracer.Start(ctx, "orderService.UpdateOrderStatus")
+	defer span.End()
+
+	var (
+		order    *domain.Order
+		previous domain.OrderStatus
+	)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		previous = order.Status
+		if previous == req.Status {
+			return nil
+		}
+		if !order.CanTransitionTo(req.Status) {
+			return fmt.Errorf("%w: %s to %s", domain.ErrInvalidTransition, previous, req.Status)
+		}
+
+		order.Status = req.Status
+		if req.Status == domain.OrderStatusPaid {
+			order.MarkAsPaid()
+		}
+
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return err
+		}
 
-	if err := s.orderRepo.Update(order); err != nil {
+		if err := s.events.Publish(ctx, event.OrderStatusChanged, order.ID, event.OrderStatusChangedPayload{
+			OrderID:     order.ID,
+			OrderNumber: order.OrderNumber,
+			UserID:      order.OwnerID(),
+			From:        string(previous),
+			To:          string(order.Status),
+		}); err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case domain.OrderStatusPaid:
+			if _, err := s.stock.Commit(ctx, order.ID); err != nil {
+				return err
+			}
+
+			return s.events.Publish(ctx, event.OrderPaid, order.ID, event.OrderPaidPayload{
+				OrderID:     order.ID,
+				OrderNumber: order.OrderNumber,
+				UserID:      order.OwnerID(),
+				Amount:      order.TotalAmount,
+			})
+		case domain.OrderStatusCancelled:
+			if err := s.returnStock(ctx, order); err != nil {
+				return err
+			}
+
+			return s.events.Publish(ctx, event.OrderCancelled, order.ID, orderCancelledPayload(order))
+		}
+
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// Paid and cancelled are announced through the outbox; the remaining
+	// statuses are notified directly.
+	if previous != order.Status && order.Status != domain.OrderStatusPaid && order.Status != domain.OrderStatusCancelled {
+		if eventType, ok := notification.EventForStatus(order.Status); ok {
+			s.notifier.Notify(ctx, notification.OrderEvent(eventType, order))
+		}
+	}
+
+	s.setDisplayTotal(ctx, order)
+
 	return order, nil
 }
 
-func (s *orderService) CancelOrder(orderID string, userID string) error {
-	order, err := s.orderRepo.GetByID(orderID)
+func (s *orderService) CancelOrder(ctx context.Context, orderID string, userID string) error {
+	ctx, span := tracer.Start(ctx, "orderService.CancelOrder")
+	defer span.End()
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	if order.UserID != userID {
+	if !order.IsOwnedBy(userID) {
 		return domain.ErrForbidden
 	}
 
@@ -132,22 +486,182 @@ func (s *orderService) CancelOrder(orderID string, userID string) error {
 		return domain.ErrCannotCancelOrder
 	}
 
-	order.Status = domain.OrderStatusCancelled
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// A payment may have settled, or the order expired, since it was
+		// read; the customer only cancels the order they saw.
+		current, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if current.Status != order.Status {
+			return domain.ErrCannotCancelOrder
+		}
+
+		current.Status = domain.OrderStatusCancelled
+
+		if err := s.returnStock(ctx, current); err != nil {
+			return err
+		}
+
+		if err := s.orderRepo.Update(ctx, current); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.OrderCancelled, current.ID, orderCancelledPayload(current))
+	})
+}
+
+// returnStock gives back the stock of a cancelled order and announces it,
+// one event per item.
+func (s *orderService) returnStock(ctx context.Context, order *domain.Order) error {
+	if err := s.releaseStock(ctx, order); err != nil {
+		return err
+	}
 
 	for _, item := range order.OrderItems {
-		product, err := s.productRepo.GetByID(item.ProductID)
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
 		if err != nil {
-			continue
+			return err
 		}
 
-		product.Stock += item.Quantity
-		s.productRepo.Update(product)
+		if err := s.events.Publish(ctx, event.StockChanged, product.ID, event.StockChangedPayload{
+			ProductID: product.ID,
+			Delta:     item.Quantity,
+			Stock:     product.Available(),
+			Reason:    event.StockReasonCancel,
+			OrderID:   order.ID,
+		}); err != nil {
+			return err
+		}
 	}
 
-	return s.orderRepo.Update(order)
+	return nil
 }
 
-func generateOrderNumber() string {
-	now := time.Now()
-	return fmt.Sprintf("ORD-%s-%d", now.Format("20060102"), now.Unix())
+// releaseStock frees the stock held for an unpaid order, or returns a paid
+// order's stock to the warehouses it was allocated from. Orders placed
+// before warehouses existed restock the default one.
+func (s *orderService) releaseStock(ctx context.Context, order *domain.Order) error {
+	released, err := s.stock.Release(ctx, order.ID)
+	if err != nil || len(released) > 0 {
+		return err
+	}
+
+	if len(order.Allocations) > 0 {
+		return s.stock.Restock(ctx, order.Allocations)
+	}
+
+	for _, item := range order.OrderItems {
+		if err := s.stock.Adjust(ctx, item.ProductID, "", item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExpireOrder releases the stock held for an unpaid order whose reservation
+// ran out and cancels the order.
+func (s *orderService) ExpireOrder(ctx context.Context, orderID string) error {
+	ctx, span := tracer.Start(ctx, "orderService.ExpireOrder")
+	defer span.End()
+
+	cancelled := false
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The order is locked before its reservations, as payments and
+		// cancellations do, so a payment settling at the same time either
+		// pays the order before this or finds it cancelled.
+		order, err := s.orderRepo.LockByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+
+		released, err := s.stock.Release(ctx, orderID)
+		if err != nil || len(released) == 0 {
+			return err
+		}
+
+		if err := s.publishReleased(ctx, orderID, released, event.StockReasonExpired); err != nil {
+			return err
+		}
+
+		if order.Status != domain.OrderStatusPending {
+			return nil
+		}
+
+		order.Status = domain.OrderStatusCancelled
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return err
+		}
+
+		cancelled = true
+		return s.events.Publish(ctx, event.OrderCancelled, order.ID, orderCancelledPayload(order))
+	})
+	if err != nil {
+		return err
+	}
+
+	if cancelled {
+		s.logger.InfoContext(ctx, "Unpaid order expired", slog.String("order_id", orderID))
+	}
+
+	return nil
+}
+
+// publishReleased announces the stock freed by released reservations, one
+// event per product.
+func (s *orderService) publishReleased(ctx context.Context, orderID string, released []domain.StockReservation, reason event.StockReason) error {
+	var productIDs []string
+	quantities := map[string]int{}
+	for _, reservation := range released {
+		if _, ok := quantities[reservation.ProductID]; !ok {
+			productIDs = append(productIDs, reservation.ProductID)
+		}
+		quantities[reservation.ProductID] += reservation.Quantity
+	}
+
+	for _, productID := range productIDs {
+		product, err := s.productRepo.GetByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.events.Publish(ctx, event.StockChanged, productID, event.StockChangedPayload{
+			ProductID: productID,
+			Delta:     quantities[productID],
+			Stock:     product.Available(),
+			Reason:    reason,
+			OrderID:   orderID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orderCreatedPayload(order *domain.Order) event.OrderCreatedPayload {
+	payload := event.OrderCreatedPayload{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		UserID:      order.OwnerID(),
+		TotalAmount: order.TotalAmount,
+	}
+	for _, item := range order.OrderItems {
+		payload.Items = append(payload.Items, event.OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		})
+	}
+	return payload
+}
+
+func orderCancelledPayload(order *domain.Order) event.OrderCancelledPayload {
+	return event.OrderCancelledPayload{
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		UserID:      order.OwnerID(),
+	}
 }
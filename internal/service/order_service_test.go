@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/currency"
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/database/databasetest"
+	"github.com/affandisy/goshop/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// orderFixture wires the order and payment services to a test database.
+type orderFixture struct {
+	db       *gorm.DB
+	orders   OrderService
+	payments PaymentService
+	notifier *recordingNotifier
+	user     *domain.User
+}
+
+func newOrderFixture(t *testing.T) *orderFixture {
+	t.Helper()
+
+	db := databasetest.Open(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	allocator, err := inventory.NewAllocator(inventory.StrategyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transactor := repository.NewTransactor(db)
+	orderRepo := repository.NewOrderRepository(db)
+	stock := inventory.NewManager(repository.NewInventoryRepository(db), repository.NewWarehouseRepository(db), repository.NewStockReservationRepository(db), allocator)
+	events := event.NewOutboxPublisher(repository.NewOutboxRepository(db))
+	orderNumbers := NewOrderNumberGenerator(repository.NewOrderSequenceRepository(db), domain.OrderNumberFormat{
+		Layout:         "ORD-{date}-{seq}{check}",
+		SequenceDigits: 6,
+	})
+	converter := currency.NewConverter(repository.NewExchangeRateRepository(db), domain.RoundingPolicy{Mode: domain.RoundHalfUp})
+
+	notifier := &recordingNotifier{}
+	f := &orderFixture{
+		db:       db,
+		orders:   NewOrderService(orderRepo, repository.NewProductRepository(db), repository.NewPriceRuleRepository(db), repository.NewUserRepository(db), transactor, stock, time.Hour, events, notifier, OrderLinks{BaseURL: "http://localhost"}, orderNumbers, converter, logger),
+		payments: NewPaymentService(repository.NewPaymentRepository(db), orderRepo, nil, transactor, stock, events, notifier, logger),
+		notifier: notifier,
+	}
+
+	f.user = &domain.User{Email: "customer@example.com", Password: "x", Name: "Customer", IsActive: true}
+	f.user.MarkEmailVerified()
+	if err := db.Create(f.user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+// product creates an active product with stock in the default warehouse.
+func (f *orderFixture) product(t *testing.T, stock int) *domain.Product {
+	t.Helper()
+
+	product := &domain.Product{Name: "Widget", Price: domain.IDR(10000), SKU: "WIDGET", IsActive: true}
+	if err := f.db.Create(product).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var warehouse domain.Warehouse
+	if err := f.db.Where("code = ?", "MAIN").First(&warehouse).Error; err != nil {
+		t.Fatal(err)
+	}
+	level := domain.InventoryLevel{ProductID: product.ID, WarehouseID: warehouse.ID, Quantity: stock}
+	if err := f.db.Create(&level).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := f.db.Model(product).Update("stock", stock).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return product
+}
+
+func (f *orderFixture) order(t *testing.T, productID string, quantity int) *domain.Order {
+	t.Helper()
+
+	order, err := f.orders.CreateOrder(context.Background(), f.user.ID, dto.CreateOrderRequest{
+		Items: []dto.OrderItemRequest{{ProductID: productID, Quantity: quantity}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return order
+}
+
+// assertStock checks the product's stock and reserved counts, and that its
+// inventory level agrees.
+func (f *orderFixture) assertStock(t *testing.T, productID string, stock, reserved int) {
+	t.Helper()
+
+	var product domain.Product
+	if err := f.db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if product.Stock != stock || product.Reserved != reserved {
+		t.Errorf("product stock = %d, reserved = %d; want %d, %d", product.Stock, product.Reserved, stock, reserved)
+	}
+
+	var level domain.InventoryLevel
+	if err := f.db.First(&level, "product_id = ?", productID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if level.Quantity != stock || level.Reserved != reserved {
+		t.Errorf("inventory level quantity = %d, reserved = %d; want %d, %d", level.Quantity, level.Reserved, stock, reserved)
+	}
+}
+
+func (f *orderFixture) assertStatus(t *testing.T, orderID string, want domain.OrderStatus) {
+	t.Helper()
+
+	var order domain.Order
+	if err := f.db.First(&order, "id = ?", orderID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != want {
+		t.Errorf("order status = %s, want %s", order.Status, want)
+	}
+}
+
+func (f *orderFixture) countEvents(t *testing.T, eventType event.Type, aggregateID string) int64 {
+	t.Helper()
+
+	var count int64
+	err := f.db.Model(&domain.OutboxEvent{}).
+		Where("event_type = ? AND aggregate_id = ?", string(eventType), aggregateID).
+		Count(&count).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestConcurrentCheckoutsDoNotOversell(t *testing.T) {
+	f := newOrderFixture(t)
+
+	const stock, checkouts = 5, 40
+	product := f.product(t, stock)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		placed int
+	)
+	for i := 0; i < checkouts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := f.orders.CreateOrder(context.Background(), f.user.ID, dto.CreateOrderRequest{
+				Items: []dto.OrderItemRequest{{ProductID: product.ID, Quantity: 1}},
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				placed++
+			case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrProductNotAvailable):
+			default:
+				t.Errorf("CreateOrder: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if placed != stock {
+		t.Errorf("placed %d orders for %d in stock", placed, stock)
+	}
+	f.assertStock(t, product.ID, stock, stock)
+
+	var reserved int64
+	err := f.db.Model(&domain.StockReservation{}).
+		Where("product_id = ? AND status = ?", product.ID, domain.ReservationStatusActive).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&reserved).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserved != stock {
+		t.Errorf("active reservations hold %d, want %d", reserved, stock)
+	}
+}
+
+func TestExpireOrderReleasesStock(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	f.assertStock(t, product.ID, 3, 2)
+
+	if err := f.orders.ExpireOrder(context.Background(), order.ID); err != nil {
+		t.Fatalf("ExpireOrder: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+	if n := f.countEvents(t, event.OrderCancelled, order.ID); n != 1 {
+		t.Errorf("published %d order cancelled events, want 1", n)
+	}
+}
+
+func TestExpireOrderLeavesPaidOrder(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+
+	if _, err := f.orders.UpdateOrderStatus(context.Background(), order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusPaid}); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if err := f.orders.ExpireOrder(context.Background(), order.ID); err != nil {
+		t.Fatalf("ExpireOrder: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusPaid)
+	f.assertStock(t, product.ID, 1, 0)
+}
+
+func TestCancelOrderReleasesReservedStock(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+
+	if err := f.orders.CancelOrder(context.Background(), order.ID, f.user.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+}
+
+func TestCancelOrderRestocksPaidOrder(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+
+	if _, err := f.orders.UpdateOrderStatus(context.Background(), order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusPaid}); err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	f.assertStock(t, product.ID, 1, 0)
+
+	if err := f.orders.CancelOrder(context.Background(), order.ID, f.user.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+}
+
+func TestAdminCancelRestocksPaidOrder(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	ctx := context.Background()
+
+	if _, err := f.orders.UpdateOrderStatus(ctx, order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusPaid}); err != nil {
+		t.Fatalf("UpdateOrderStatus paid: %v", err)
+	}
+	if _, err := f.orders.UpdateOrderStatus(ctx, order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusCancelled}); err != nil {
+		t.Fatalf("UpdateOrderStatus cancelled: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+}
+
+func TestCancelledOrderCannotBeReopened(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	ctx := context.Background()
+
+	if _, err := f.orders.UpdateOrderStatus(ctx, order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusCancelled}); err != nil {
+		t.Fatalf("UpdateOrderStatus cancelled: %v", err)
+	}
+	for _, status := range []domain.OrderStatus{domain.OrderStatusPending, domain.OrderStatusPaid} {
+		if _, err := f.orders.UpdateOrderStatus(ctx, order.ID, dto.UpdateOrderStatusRequest{Status: status}); !errors.Is(err, domain.ErrInvalidTransition) {
+			t.Errorf("UpdateOrderStatus %s = %v, want %v", status, err, domain.ErrInvalidTransition)
+		}
+	}
+	if _, err := f.orders.UpdateOrderStatus(ctx, order.ID, dto.UpdateOrderStatusRequest{Status: domain.OrderStatusCancelled}); err != nil {
+		t.Fatalf("UpdateOrderStatus cancelled again: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+	if n := f.countEvents(t, event.OrderCancelled, order.ID); n != 1 {
+		t.Errorf("published %d order cancelled events, want 1", n)
+	}
+}
+
+func TestCancelOrderMissing(t *testing.T) {
+	f := newOrderFixture(t)
+
+	err := f.orders.CancelOrder(context.Background(), "00000000-0000-0000-0000-000000000000", f.user.ID)
+	if !errors.Is(err, domain.ErrOrderNotFound) {
+		t.Errorf("CancelOrder = %v, want %v", err, domain.ErrOrderNotFound)
+	}
+}
+
+func TestClaimedGuestOrderHidesAccount(t *testing.T) {
+	utils.InitJWt("test-secret")
+
+	f := newOrderFixture(t)
+	product := f.product(t, 5)
+
+	placed, err := f.orders.CreateGuestOrder(context.Background(), dto.CreateGuestOrderRequest{
+		Items:   []dto.OrderItemRequest{{ProductID: product.ID, Quantity: 1}},
+		Contact: dto.GuestContactRequest{Name: "Guest", Email: f.user.Email, Phone: "081234567890"},
+		ShippingAddress: dto.ShippingAddressRequest{
+			RecipientName: "Guest", Phone: "081234567890", Line1: "Jl. Merdeka 1",
+			City: "Jakarta", Province: "DKI Jakarta", PostalCode: "10110",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateGuestOrder: %v", err)
+	}
+
+	if claimed, err := f.orders.ClaimGuestOrders(context.Background(), f.user.ID); err != nil || claimed != 1 {
+		t.Fatalf("ClaimGuestOrders = %d, %v; want 1", claimed, err)
+	}
+
+	order, err := f.orders.GetGuestOrder(context.Background(), placed.Order.ID, placed.AccessToken)
+	if err != nil {
+		t.Fatalf("GetGuestOrder: %v", err)
+	}
+	if order.User != nil {
+		t.Errorf("guest link shows account %s", order.User.Email)
+	}
+}
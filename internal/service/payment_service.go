@@ -1,41 +1,77 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
+	"github.com/affandisy/goshop/internal/notification"
 	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
 	"github.com/affandisy/goshop/pkg/payment"
+	"github.com/affandisy/goshop/pkg/utils"
 )
 
 type paymentService struct {
 	paymentRepo    repository.PaymentRepository
 	orderRepo      repository.OrderRepository
 	midtransClient *payment.MidtransClient
+	transactor     repository.Transactor
+	stock          inventory.Manager
+	events         event.Publisher
+	notifier       notification.Notifier
+	logger         *slog.Logger
 }
 
-func NewPaymentService(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository, midtransClient *payment.MidtransClient) PaymentService {
-	return &paymentService{paymentRepo: paymentRepo, orderRepo: orderRepo, midtransClient: midtransClient}
+func NewPaymentService(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository, midtransClient *payment.MidtransClient, transactor repository.Transactor, stock inventory.Manager, events event.Publisher, notifier notification.Notifier, logger *slog.Logger) PaymentService {
+	return &paymentService{paymentRepo: paymentRepo, orderRepo: orderRepo, midtransClient: midtransClient, transactor: transactor, stock: stock, events: events, notifier: notifier, logger: logger}
 }
 
-func (s *paymentService) CreatePayment(userID string, req dto.CreatePaymentRequest) (*domain.Payment, error) {
-	order, err := s.orderRepo.GetByID(req.OrderID)
+func (s *paymentService) CreatePayment(ctx context.Context, userID string, req dto.CreatePaymentRequest) (*domain.Payment, error) {
+	ctx, span := tracer.Start(ctx, "paymentService.CreatePayment")
+	defer span.End()
+
+	order, err := s.orderRepo.GetByID(ctx, req.OrderID)
 	if err != nil {
 		return nil, err
 	}
 
-	if order.UserID != userID {
+	if !order.IsOwnedBy(userID) {
 		return nil, domain.ErrForbidden
 	}
 
+	return s.createPayment(ctx, order, req)
+}
+
+func (s *paymentService) CreateGuestPayment(ctx context.Context, token string, req dto.CreatePaymentRequest) (*domain.Payment, error) {
+	ctx, span := tracer.Start(ctx, "paymentService.CreateGuestPayment")
+	defer span.End()
+
+	claims, err := utils.ValidateOrderAccessToken(token)
+	if err != nil || claims.OrderID != req.OrderID {
+		return nil, domain.ErrInvalidOrderToken
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createPayment(ctx, order, req)
+}
+
+func (s *paymentService) createPayment(ctx context.Context, order *domain.Order, req dto.CreatePaymentRequest) (*domain.Payment, error) {
 	if order.Status != domain.OrderStatusPending {
 		return nil, domain.ErrOrderAlreadyPaid
 	}
 
-	existingPayment, err := s.paymentRepo.GetByOrderID(req.OrderID)
+	existingPayment, err := s.paymentRepo.GetByOrderID(ctx, req.OrderID)
 	if err != nil && !errors.Is(err, domain.ErrPaymentNotFound) {
 		return nil, err
 	}
@@ -44,27 +80,38 @@ func (s *paymentService) CreatePayment(userID string, req dto.CreatePaymentReque
 		return nil, domain.ErrPaymentAlreadyExists
 	}
 
+	grossAmount, err := midtransAmount(order.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+
 	midtransOrderID := fmt.Sprintf("PAY-%s-%d", order.OrderNumber, time.Now().Unix())
 
+	contact := order.Contact()
 	snapReq := payment.CreateSnapTokenRequest{
 		OrderID:       midtransOrderID,
-		GrossAmount:   int64(order.TotalAmount),
-		CustomerName:  order.User.Name,
-		CustomerEmail: order.User.Email,
-		CustomerPhone: order.User.Phone,
+		GrossAmount:   grossAmount,
+		CustomerName:  contact.Name,
+		CustomerEmail: contact.Email,
+		CustomerPhone: contact.Phone,
 		Items:         []payment.ItemDetail{},
 	}
 
 	for _, item := range order.OrderItems {
+		price, err := midtransAmount(item.Price)
+		if err != nil {
+			return nil, err
+		}
+
 		snapReq.Items = append(snapReq.Items, payment.ItemDetail{
 			ID:       item.ProductID,
 			Name:     item.Product.Name,
-			Price:    int64(item.Price),
+			Price:    price,
 			Quantity: int32(item.Quantity),
 		})
 	}
 
-	snapResp, err := s.midtransClient.CreateSnapToken(snapReq)
+	snapResp, err := s.midtransClient.CreateSnapToken(ctx, snapReq)
 	if err != nil {
 		return nil, err
 	}
@@ -78,16 +125,16 @@ func (s *paymentService) CreatePayment(userID string, req dto.CreatePaymentReque
 		existingPayment.Status = domain.PaymentStatusPending
 		existingPayment.PaymentMethod = req.PaymentMethod
 
-		expiredAt := time.Now().Add(24 * time.Hour)
+		expiredAt := paymentExpiry(order)
 		existingPayment.ExpiredAt = &expiredAt
 
-		if err := s.paymentRepo.Update(existingPayment); err != nil {
+		if err := s.paymentRepo.Update(ctx, existingPayment); err != nil {
 			return nil, err
 		}
 
 		paymentRecord = existingPayment
 	} else {
-		expiredAt := time.Now().Add(24 * time.Hour)
+		expiredAt := paymentExpiry(order)
 
 		paymentRecord = &domain.Payment{
 			OrderID:           order.ID,
@@ -100,59 +147,211 @@ func (s *paymentService) CreatePayment(userID string, req dto.CreatePaymentReque
 			ExpiredAt:         &expiredAt,
 		}
 
-		if err := s.paymentRepo.Create(paymentRecord); err != nil {
+		if err := s.paymentRepo.Create(ctx, paymentRecord); err != nil {
 			return nil, err
 		}
 	}
 
-	paymentRecord, _ = s.paymentRepo.GetByID(paymentRecord.ID)
+	paymentRecord, _ = s.paymentRepo.GetByID(ctx, paymentRecord.ID)
 
 	return paymentRecord, nil
 }
 
-func (s *paymentService) GetPaymentByID(id string) (*domain.Payment, error) {
-	return s.paymentRepo.GetByID(id)
+// midtransAmount is amount in whole rupiah, the only amounts Midtrans
+// accepts. Rupiah have no minor unit, so this is the amount itself.
+func midtransAmount(amount domain.Money) (int64, error) {
+	if amount.Code() != domain.CurrencyIDR {
+		return 0, fmt.Errorf("%w: midtrans charges %s, not %s", domain.ErrUnsupportedCurrency, domain.CurrencyIDR, amount.Code())
+	}
+	return amount.Amount, nil
 }
 
-func (s *paymentService) GetPaymentByOrderID(orderID string) (*domain.Payment, error) {
-	return s.paymentRepo.GetByOrderID(orderID)
+// paymentExpiry is 24 hours from now, or when the order's stock
+// reservation runs out if that is sooner.
+func paymentExpiry(order *domain.Order) time.Time {
+	expiredAt := time.Now().Add(24 * time.Hour)
+	if order.ReservedUntil != nil && order.ReservedUntil.Before(expiredAt) {
+		expiredAt = *order.ReservedUntil
+	}
+	return expiredAt
+}
+
+func (s *paymentService) GetPaymentByID(ctx context.Context, id string) (*domain.Payment, error) {
+	ctx, span := tracer.Start(ctx, "paymentService.GetPaymentByID")
+	defer span.End()
+
+	return s.paymentRepo.GetByID(ctx, id)
 }
-func (s *paymentService) HandleNotification(notification dto.PaymentNotification) error {
-	payment, err := s.paymentRepo.GetByMidtransOrderID(notification.OrderID)
+
+func (s *paymentService) GetPaymentByOrderID(ctx context.Context, orderID string) (*domain.Payment, error) {
+	ctx, span := tracer.Start(ctx, "paymentService.GetPaymentByOrderID")
+	defer span.End()
+
+	return s.paymentRepo.GetByOrderID(ctx, orderID)
+}
+func (s *paymentService) HandleNotification(ctx context.Context, payload dto.PaymentNotification) error {
+	ctx, span := tracer.Start(ctx, "paymentService.HandleNotification")
+	defer span.End()
+
+	var (
+		payment        *domain.Payment
+		changed        bool
+		orderCancelled bool
+	)
+
+	// The payment and then its order are locked before anything is read,
+	// so duplicate notifications handled at the same time are applied one
+	// after the other and only the first settlement pays the order.
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		payment, err = s.paymentRepo.LockByMidtransOrderID(ctx, payload.OrderID)
+		if err != nil {
+			return err
+		}
+
+		// The gateway must have charged exactly what we asked for.
+		if payload.GrossAmount != "" {
+			grossAmount, err := domain.ParseMoney(payload.GrossAmount, domain.CurrencyIDR)
+			if err != nil || !grossAmount.Equal(payment.Amount) {
+				return fmt.Errorf("%w: got %s, expected %s", domain.ErrAmountMismatch, payload.GrossAmount, payment.Amount.Decimal())
+			}
+		}
+
+		// A successful payment is final, so a late or repeated expire or
+		// deny callback can't turn it back into a failure.
+		previousStatus := payment.Status
+		if previousStatus == domain.PaymentStatusSuccess {
+			return nil
+		}
+
+		order, err := s.orderRepo.LockByID(ctx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+
+		settled := false
+
+		switch payload.TransactionStatus {
+		case "capture", "settlement":
+			if payload.FraudStatus == "accept" || payload.FraudStatus == "" {
+				payment.MarkAsPaid()
+				payment.PaymentMethod = domain.PaymentMethod(payload.PaymentType)
+				settled = true
+			}
+		case "pending":
+			payment.Status = domain.PaymentStatusPending
+		case "deny", "cancel":
+			payment.MarkAsFailed()
+		case "expire":
+			payment.MarkAsExpired()
+		}
+
+		changed = payment.Status != previousStatus
+		if !changed {
+			return nil
+		}
+
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+
+		switch payment.Status {
+		case domain.PaymentStatusSuccess:
+			if err := s.events.Publish(ctx, event.PaymentSucceeded, payment.ID, event.NewPaymentPayload(payment)); err != nil {
+				return err
+			}
+		case domain.PaymentStatusFailed, domain.PaymentStatusExpired:
+			if err := s.events.Publish(ctx, event.PaymentFailed, payment.ID, event.NewPaymentPayload(payment)); err != nil {
+				return err
+			}
+		}
+
+		if !settled {
+			return nil
+		}
+
+		// Midtrans may send the same settlement more than once; only the
+		// first one pays the order. The order may also have expired,
+		// releasing its stock, while the customer was paying.
+		switch order.Status {
+		case domain.OrderStatusPending:
+		case domain.OrderStatusCancelled:
+			orderCancelled = previousStatus != domain.PaymentStatusSuccess
+			return nil
+		default:
+			return nil
+		}
+
+		if _, err := s.stock.Commit(ctx, order.ID); err != nil {
+			return err
+		}
+
+		order.MarkAsPaid()
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.OrderPaid, order.ID, event.OrderPaidPayload{
+			OrderID:     order.ID,
+			OrderNumber: order.OrderNumber,
+			UserID:      order.OwnerID(),
+			PaymentID:   payment.ID,
+			Amount:      payment.Amount,
+		})
+	})
 	if err != nil {
 		return err
 	}
 
-	order := payment.Order
-
-	switch notification.TransactionStatus {
-	case "capture", "settlement":
-		if notification.FraudStatus == "accept" || notification.FraudStatus == "" {
-			payment.MarkAsPaid()
-			payment.PaymentMethod = domain.PaymentMethod(notification.PaymentType)
-			order.MarkAsPaid()
-		}
-	case "pending":
-		payment.Status = domain.PaymentStatusPending
-	case "deny", "cancel":
-		payment.MarkAsFailed()
-	case "expire":
-		payment.MarkAsExpired()
+	// Repeated callbacks change nothing and are neither counted nor
+	// notified again.
+	if !changed {
+		return nil
 	}
 
-	if err := s.paymentRepo.Update(payment); err != nil {
-		return err
+	s.logger.InfoContext(ctx, "Payment status updated",
+		slog.String("payment_id", payment.ID),
+		slog.String("order_id", payment.OrderID),
+		slog.String("status", string(payment.Status)),
+	)
+
+	if orderCancelled {
+		s.logger.WarnContext(ctx, "Payment received for a cancelled order, refund required",
+			slog.String("payment_id", payment.ID),
+			slog.String("order_id", payment.OrderID),
+		)
 	}
 
-	if order.Status == domain.OrderStatusPaid {
-		if err := s.orderRepo.Update(order); err != nil {
-			return err
-		}
+	switch payment.Status {
+	case domain.PaymentStatusSuccess:
+		metrics.PaymentsTotal.WithLabelValues("succeeded").Inc()
+	case domain.PaymentStatusFailed, domain.PaymentStatusExpired:
+		metrics.PaymentsTotal.WithLabelValues("failed").Inc()
+		s.notifyPayment(ctx, notification.EventPaymentFailed, payment)
 	}
 
 	return nil
 }
 
-func (s *paymentService) GetAllPayments(page, limit int) ([]domain.Payment, int64, error) {
-	return s.paymentRepo.List(page, limit)
+// notifyPayment reloads the order with its items so the message can list
+// them, falling back to the order preloaded with the payment.
+func (s *paymentService) notifyPayment(ctx context.Context, eventType notification.EventType, payment *domain.Payment) {
+	order := payment.Order
+	if fresh, err := s.orderRepo.GetByID(ctx, payment.OrderID); err == nil {
+		order = fresh
+	}
+	if order == nil {
+		return
+	}
+
+	n := notification.OrderEvent(eventType, order)
+	n.Payment = payment
+	s.notifier.Notify(ctx, n)
+}
+
+func (s *paymentService) GetAllPayments(ctx context.Context, page, limit int) ([]domain.Payment, int64, error) {
+	ctx, span := tracer.Start(ctx, "paymentService.GetAllPayments")
+	defer span.End()
+
+	return s.paymentRepo.List(ctx, page, limit)
 }
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/notification"
+)
+
+func (f *orderFixture) payment(t *testing.T, order *domain.Order) *domain.Payment {
+	t.Helper()
+
+	payment := &domain.Payment{
+		OrderID:         order.ID,
+		Amount:          order.TotalAmount,
+		Status:          domain.PaymentStatusPending,
+		MidtransOrderID: order.OrderNumber,
+	}
+	if err := f.db.Create(payment).Error; err != nil {
+		t.Fatal(err)
+	}
+	return payment
+}
+
+func TestDuplicateSettlementsPayOrderOnce(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	payment := f.payment(t, order)
+
+	callback := dto.PaymentNotification{
+		TransactionStatus: "settlement",
+		OrderID:           payment.MidtransOrderID,
+		GrossAmount:       payment.Amount.Decimal(),
+		PaymentType:       "bank_transfer",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f.payments.HandleNotification(context.Background(), callback); err != nil {
+				t.Errorf("HandleNotification: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	f.assertStatus(t, order.ID, domain.OrderStatusPaid)
+	f.assertStock(t, product.ID, 1, 0)
+	if n := f.countEvents(t, event.OrderPaid, order.ID); n != 1 {
+		t.Errorf("published %d order paid events, want 1", n)
+	}
+	if n := f.countEvents(t, event.PaymentSucceeded, payment.ID); n != 1 {
+		t.Errorf("published %d payment succeeded events, want 1", n)
+	}
+}
+
+func TestSettlementAfterExpiryLeavesOrderCancelled(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	payment := f.payment(t, order)
+
+	if err := f.orders.ExpireOrder(context.Background(), order.ID); err != nil {
+		t.Fatalf("ExpireOrder: %v", err)
+	}
+
+	err := f.payments.HandleNotification(context.Background(), dto.PaymentNotification{
+		TransactionStatus: "settlement",
+		OrderID:           payment.MidtransOrderID,
+		GrossAmount:       payment.Amount.Decimal(),
+	})
+	if err != nil {
+		t.Fatalf("HandleNotification: %v", err)
+	}
+
+	f.assertStatus(t, order.ID, domain.OrderStatusCancelled)
+	f.assertStock(t, product.ID, 3, 0)
+	if n := f.countEvents(t, event.OrderPaid, order.ID); n != 0 {
+		t.Errorf("published %d order paid events, want 0", n)
+	}
+}
+
+func TestDuplicateFailureCallbacksNotifyOnce(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	payment := f.payment(t, order)
+
+	for i := 0; i < 3; i++ {
+		err := f.payments.HandleNotification(context.Background(), dto.PaymentNotification{
+			TransactionStatus: "expire",
+			OrderID:           payment.MidtransOrderID,
+		})
+		if err != nil {
+			t.Fatalf("HandleNotification: %v", err)
+		}
+	}
+
+	if n := f.countEvents(t, event.PaymentFailed, payment.ID); n != 1 {
+		t.Errorf("published %d payment failed events, want 1", n)
+	}
+	if n := f.notifier.count(notification.EventPaymentFailed); n != 1 {
+		t.Errorf("sent %d payment failed notifications, want 1", n)
+	}
+}
+
+func TestLateExpiryLeavesPaymentSucceeded(t *testing.T) {
+	f := newOrderFixture(t)
+	product := f.product(t, 3)
+	order := f.order(t, product.ID, 2)
+	payment := f.payment(t, order)
+	ctx := context.Background()
+
+	for _, status := range []string{"settlement", "expire", "deny"} {
+		err := f.payments.HandleNotification(ctx, dto.PaymentNotification{
+			TransactionStatus: status,
+			OrderID:           payment.MidtransOrderID,
+			GrossAmount:       payment.Amount.Decimal(),
+		})
+		if err != nil {
+			t.Fatalf("HandleNotification %s: %v", status, err)
+		}
+	}
+
+	var got domain.Payment
+	if err := f.db.First(&got, "id = ?", payment.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.PaymentStatusSuccess {
+		t.Errorf("payment status = %s, want %s", got.Status, domain.PaymentStatusSuccess)
+	}
+	f.assertStatus(t, order.ID, domain.OrderStatusPaid)
+	if n := f.countEvents(t, event.PaymentFailed, payment.ID); n != 0 {
+		t.Errorf("published %d payment failed events, want 0", n)
+	}
+	if n := f.notifier.count(notification.EventPaymentFailed); n != 0 {
+		t.Errorf("sent %d payment failed notifications, want 0", n)
+	}
+}
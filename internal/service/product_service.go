@@ -3,35 +3,58 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/affandisy/goshop/internal/currency"
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
 	"github.com/affandisy/goshop/internal/repository"
 	"github.com/affandisy/goshop/pkg/cache"
+	"github.com/affandisy/goshop/pkg/i18n"
+	"github.com/affandisy/goshop/pkg/metrics"
 )
 
 type productService struct {
-	productRepo  repository.ProductRepository
-	categoryRepo repository.CategoryRepository
-	cacheService cache.CacheService
+	productRepo             repository.ProductRepository
+	categoryRepo            repository.CategoryRepository
+	priceRuleRepo           repository.PriceRuleRepository
+	priceHistoryRepo        repository.PriceHistoryRepository
+	translationRepo         repository.ProductTranslationRepository
+	categoryTranslationRepo repository.CategoryTranslationRepository
+	cacheService            cache.CacheService
+	transactor              repository.Transactor
+	stock                   inventory.Manager
+	events                  event.Publisher
+	converter               currency.Converter
 }
 
-func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, cacheService cache.CacheService) ProductService {
-	return &productService{productRepo: productRepo, categoryRepo: categoryRepo, cacheService: cacheService}
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, priceRuleRepo repository.PriceRuleRepository, priceHistoryRepo repository.PriceHistoryRepository, translationRepo repository.ProductTranslationRepository, categoryTranslationRepo repository.CategoryTranslationRepository, cacheService cache.CacheService, transactor repository.Transactor, stock inventory.Manager, events event.Publisher, converter currency.Converter) ProductService {
+	return &productService{productRepo: productRepo, categoryRepo: categoryRepo, priceRuleRepo: priceRuleRepo, priceHistoryRepo: priceHistoryRepo, translationRepo: translationRepo, categoryTranslationRepo: categoryTranslationRepo, cacheService: cacheService, transactor: transactor, stock: stock, events: events, converter: converter}
 }
 
-func (s *productService) Create(req dto.ProductRequest) (*domain.Product, error) {
-	existingProduct, err := s.productRepo.GetBySKU(req.SKU)
+func (s *productService) Create(ctx context.Context, req dto.ProductRequest) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "productService.Create")
+	defer span.End()
+
+	if err := checkBasePrice(req.Price); err != nil {
+		return nil, err
+	}
+
+	existingProduct, err := s.productRepo.GetBySKU(ctx, req.SKU)
 	if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
 		return nil, err
 	}
 
 	if existingProduct != nil {
-		return nil, errors.New("sku already exists")
+		return nil, domain.ErrSKUAlreadyExists
 	}
 
 	if req.CategoryID != "" {
-		_, err := s.categoryRepo.GetByID(req.CategoryID)
+		_, err := s.categoryRepo.GetByID(ctx, req.CategoryID)
 		if err != nil {
 			return nil, err
 		}
@@ -46,47 +69,82 @@ func (s *productService) Create(req dto.ProductRequest) (*domain.Product, error)
 		CategoryID:  req.CategoryID,
 		ImageURL:    req.ImageURL,
 		IsActive:    true,
+
+		ReorderLevel:    req.ReorderLevel,
+		ReorderQuantity: req.ReorderQuantity,
 	}
 
-	if err := s.productRepo.Create(product); err != nil {
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.productRepo.Create(ctx, product); err != nil {
+			return err
+		}
+
+		if err := s.priceHistoryRepo.Create(ctx, &domain.PriceHistory{ProductID: product.ID, Price: product.Price}); err != nil {
+			return err
+		}
+
+		// Stock entered on the product is held in the default warehouse.
+		if product.Stock > 0 {
+			if err := s.stock.Adjust(ctx, product.ID, "", product.Stock); err != nil {
+				return err
+			}
+		}
+
+		return s.events.Publish(ctx, event.ProductCreated, product.ID, event.NewProductPayload(product))
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	product, _ = s.productRepo.GetByID(product.ID)
+	product, _ = s.productRepo.GetByID(ctx, product.ID)
 
 	return product, nil
 }
 
-func (s *productService) GetByID(id string) (*domain.Product, error) {
-	// return s.productRepo.GetByID(id)
-	ctx := context.Background()
+func (s *productService) GetByID(ctx context.Context, id, code string) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "productService.GetByID")
+	defer span.End()
+
+	// return s.productRepo.GetByID(ctx, id)
 	cacheKey := cache.ProductKey(id)
 
 	var cachedProduct domain.Product
 	err := s.cacheService.Get(ctx, cacheKey, &cachedProduct)
 	if err == nil {
-		return &cachedProduct, nil
+		products := []domain.Product{cachedProduct}
+		if err := s.present(ctx, code, products); err != nil {
+			return nil, err
+		}
+		return &products[0], nil
 	}
 
-	product, err := s.productRepo.GetByID(id)
+	product, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	s.cacheService.Set(ctx, cacheKey, product, cache.ProductTTL)
 
-	return product, nil
+	products := []domain.Product{*product}
+	if err := s.present(ctx, code, products); err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
 }
 
-func (s *productService) List(query dto.ProductQuery) ([]domain.Product, int64, error) {
-	// return s.productRepo.List(query)
-	ctx := context.Background()
+func (s *productService) List(ctx context.Context, query dto.ProductQuery) ([]domain.Product, int64, error) {
+	ctx, span := tracer.Start(ctx, "productService.List")
+	defer span.End()
+
+	// return s.productRepo.List(ctx, query)
 
 	filters := map[string]interface{}{
 		"name":        query.Name,
 		"category_id": query.CategoryID,
-		"min_price":   query.MinPrice,
-		"max_price":   query.MaxPrice,
+		"min_price":   query.MinPrice.Amount,
+		"max_price":   query.MaxPrice.Amount,
+		"sort":        query.Sort,
 	}
 	cacheKey := cache.ProductsKey(query.Page, query.Limit, filters)
 
@@ -97,10 +155,13 @@ func (s *productService) List(query dto.ProductQuery) ([]domain.Product, int64,
 
 	err := s.cacheService.Get(ctx, cacheKey, &cachedResult)
 	if err == nil {
+		if err := s.present(ctx, query.Currency, cachedResult.Products); err != nil {
+			return nil, 0, err
+		}
 		return cachedResult.Products, cachedResult.TotalCount, nil
 	}
 
-	products, total, err := s.productRepo.List(query)
+	products, total, err := s.productRepo.List(ctx, query)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -109,91 +170,423 @@ func (s *productService) List(query dto.ProductQuery) ([]domain.Product, int64,
 	cachedResult.TotalCount = total
 	s.cacheService.Set(ctx, cacheKey, cachedResult, cache.ProductTTL)
 
+	if err := s.present(ctx, query.Currency, products); err != nil {
+		return nil, 0, err
+	}
+
 	return products, total, nil
 }
 
-func (s *productService) Update(id string, req dto.ProductRequest) (*domain.Product, error) {
-	product, err := s.productRepo.GetByID(id)
-	if err != nil {
+func (s *productService) Update(ctx context.Context, id string, req dto.ProductRequest) (*domain.Product, error) {
+	ctx, span := tracer.Start(ctx, "productService.Update")
+	defer span.End()
+
+	if err := checkBasePrice(req.Price); err != nil {
 		return nil, err
 	}
 
-	if req.SKU != product.SKU {
-		existingProduct, err := s.productRepo.GetBySKU(req.SKU)
-		if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
-			return nil, err
-		}
-		if existingProduct != nil {
-			return nil, errors.New("sku already exists")
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Stock levels are locked before the product, the same order
+		// checkout takes them in, so the totals read here stay current.
+		if err := s.stock.Lock(ctx, id); err != nil {
+			return err
 		}
-	}
 
-	if req.CategoryID != "" && req.CategoryID != product.CategoryID {
-		_, err := s.categoryRepo.GetByID(req.CategoryID)
+		var err error
+		product, err = s.productRepo.LockByID(ctx, id)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		if req.SKU != product.SKU {
+			existingProduct, err := s.productRepo.GetBySKU(ctx, req.SKU)
+			if err != nil && !errors.Is(err, domain.ErrProductNotFound) {
+				return err
+			}
+			if existingProduct != nil {
+				return domain.ErrSKUAlreadyExists
+			}
 		}
-	}
 
-	product.Name = req.Name
-	product.Description = req.Description
-	product.Price = req.Price
-	product.Stock = req.Stock
-	product.SKU = req.SKU
-	product.CategoryID = req.CategoryID
-	product.ImageURL = req.ImageURL
+		if req.CategoryID != "" && req.CategoryID != product.CategoryID {
+			if _, err := s.categoryRepo.GetByID(ctx, req.CategoryID); err != nil {
+				return err
+			}
+		}
 
-	if err := s.productRepo.Update(product); err != nil {
+		delta := req.Stock - product.Stock
+		previousPrice := product.Price
+
+		product.Name = req.Name
+		product.Description = req.Description
+		product.Price = req.Price
+		product.Stock = req.Stock
+		product.SKU = req.SKU
+		product.CategoryID = req.CategoryID
+		product.ImageURL = req.ImageURL
+		product.ReorderLevel = req.ReorderLevel
+		product.ReorderQuantity = req.ReorderQuantity
+
+		if err := s.productRepo.Update(ctx, product); err != nil {
+			return err
+		}
+
+		if !product.Price.Equal(previousPrice) {
+			if err := s.priceHistoryRepo.Create(ctx, &domain.PriceHistory{
+				ProductID:     product.ID,
+				PreviousPrice: previousPrice,
+				Price:         product.Price,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := s.events.Publish(ctx, event.ProductUpdated, product.ID, event.NewProductPayload(product)); err != nil {
+			return err
+		}
+
+		if delta == 0 {
+			return s.stock.Sync(ctx, product.ID)
+		}
+
+		if err := s.stock.Adjust(ctx, product.ID, "", delta); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.StockChanged, product.ID, event.StockChangedPayload{
+			ProductID: product.ID,
+			Delta:     delta,
+			Stock:     product.Available(),
+			Reason:    event.StockReasonAdjustment,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
 	s.cacheService.Delete(ctx, cache.ProductKey(id))
 	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
 
-	product, _ = s.productRepo.GetByID(product.ID)
+	product, _ = s.productRepo.GetByID(ctx, product.ID)
 
 	s.cacheService.Set(ctx, cache.ProductKey(id), product, cache.ProductTTL)
 
 	return product, nil
 }
 
-func (s *productService) Delete(id string) error {
-	_, err := s.productRepo.GetByID(id)
+func (s *productService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "productService.Delete")
+	defer span.End()
+
+	product, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	err = s.productRepo.Delete(id)
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.productRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.ProductDeleted, id, event.NewProductPayload(product))
+	})
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
 	s.cacheService.Delete(ctx, cache.ProductKey(id))
 	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
 
 	return nil
 }
 
-func (s *productService) UpdateStock(id string, quantity int) error {
-	product, err := s.productRepo.GetByID(id)
-	if err != nil {
+func (s *productService) UpdateStock(ctx context.Context, id string, quantity int) error {
+	ctx, span := tracer.Start(ctx, "productService.UpdateStock")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, id); err != nil {
 		return err
 	}
 
-	if product.Stock+quantity < 0 {
-		return domain.ErrInsufficientStock
-	}
+	// Adjust rejects a change that would leave less than the reserved
+	// stock, so the product is read back only once its levels are locked.
+	var product *domain.Product
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.stock.Adjust(ctx, id, "", quantity); err != nil {
+			return err
+		}
 
-	err = s.productRepo.UpdateStock(id, quantity)
+		var err error
+		product, err = s.productRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.StockChanged, id, event.StockChangedPayload{
+			ProductID: id,
+			Delta:     quantity,
+			Stock:     product.Available(),
+			Reason:    event.StockReasonAdjustment,
+		})
+	})
 	if err != nil {
 		return err
 	}
 
-	ctx := context.Background()
+	if quantity < 0 && product.Available() == 0 {
+		metrics.StockOutsTotal.Inc()
+	}
+
 	s.cacheService.Delete(ctx, cache.ProductKey(id))
 	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
 
 	return nil
 }
+
+func (s *productService) ListLowStock(ctx context.Context, page, limit int) ([]domain.Product, int64, error) {
+	ctx, span := tracer.Start(ctx, "productService.ListLowStock")
+	defer span.End()
+
+	return s.productRepo.ListLowStock(ctx, page, limit)
+}
+
+func (s *productService) ListPriceRules(ctx context.Context, productID string) ([]domain.PriceRule, error) {
+	ctx, span := tracer.Start(ctx, "productService.ListPriceRules")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.priceRuleRepo.ListByProduct(ctx, productID, time.Now())
+}
+
+func (s *productService) CreatePriceRule(ctx context.Context, productID string, req dto.PriceRuleRequest) (*domain.PriceRule, error) {
+	ctx, span := tracer.Start(ctx, "productService.CreatePriceRule")
+	defer span.End()
+
+	if err := checkBasePrice(req.Price); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	rule := &domain.PriceRule{
+		ProductID:   productID,
+		Name:        req.Name,
+		Type:        domain.PriceRuleType(req.Type),
+		Price:       req.Price,
+		MinQuantity: max(req.MinQuantity, 1),
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+	}
+	if err := validatePriceRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.priceRuleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *productService) DeletePriceRule(ctx context.Context, productID, ruleID string) error {
+	ctx, span := tracer.Start(ctx, "productService.DeletePriceRule")
+	defer span.End()
+
+	rule, err := s.priceRuleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if rule.ProductID != productID {
+		return domain.ErrPriceRuleNotFound
+	}
+
+	return s.priceRuleRepo.Delete(ctx, ruleID)
+}
+
+func (s *productService) ListPriceHistory(ctx context.Context, productID string, page, limit int) ([]domain.PriceHistory, int64, error) {
+	ctx, span := tracer.Start(ctx, "productService.ListPriceHistory")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.priceHistoryRepo.ListByProduct(ctx, productID, page, limit)
+}
+
+func (s *productService) ListTranslations(ctx context.Context, productID string) ([]domain.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "productService.ListTranslations")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.translationRepo.ListByProduct(ctx, productID)
+}
+
+func (s *productService) SetTranslation(ctx context.Context, productID, locale string, req dto.TranslationRequest) (*domain.ProductTranslation, error) {
+	ctx, span := tracer.Start(ctx, "productService.SetTranslation")
+	defer span.End()
+
+	locale, err := parseLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	translation := &domain.ProductTranslation{
+		ProductID:   productID,
+		Locale:      locale,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.translationRepo.Upsert(ctx, translation); err != nil {
+		return nil, err
+	}
+
+	return translation, nil
+}
+
+func (s *productService) DeleteTranslation(ctx context.Context, productID, locale string) error {
+	ctx, span := tracer.Start(ctx, "productService.DeleteTranslation")
+	defer span.End()
+
+	locale, err := parseLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	return s.translationRepo.Delete(ctx, productID, locale)
+}
+
+// present prepares products for the response, in the request's locale and
+// with display prices in currency code.
+func (s *productService) present(ctx context.Context, code string, products []domain.Product) error {
+	if err := s.localize(ctx, products); err != nil {
+		return err
+	}
+	return s.setDisplayPrices(ctx, code, products)
+}
+
+// localize replaces the text of the products and their categories with
+// translations into the request's locale, where there are any. Like display
+// prices, translations are not cached with the products.
+func (s *productService) localize(ctx context.Context, products []domain.Product) error {
+	locale := i18n.FromContext(ctx)
+	if locale == "" || len(products) == 0 {
+		return nil
+	}
+
+	productIDs := make([]string, len(products))
+	var categories []domain.Category
+	for i, p := range products {
+		productIDs[i] = p.ID
+		if p.Category != nil {
+			categories = append(categories, *p.Category)
+		}
+	}
+
+	translations, err := s.translationRepo.ListByLocale(ctx, productIDs, locale)
+	if err != nil {
+		return err
+	}
+	byProduct := make(map[string]domain.ProductTranslation, len(translations))
+	for _, t := range translations {
+		byProduct[t.ProductID] = t
+	}
+
+	if err := localizeCategories(ctx, s.categoryTranslationRepo, categories); err != nil {
+		return err
+	}
+	byCategory := make(map[string]domain.Category, len(categories))
+	for _, c := range categories {
+		byCategory[c.ID] = c
+	}
+
+	for i := range products {
+		if t, ok := byProduct[products[i].ID]; ok {
+			products[i].Localize(t)
+		}
+		if products[i].Category != nil {
+			category := byCategory[products[i].Category.ID]
+			products[i].Category = &category
+		}
+	}
+
+	return nil
+}
+
+// setDisplayPrices converts the products' prices into the currency with
+// today's rate. Cached products are stored without them, as rates change.
+func (s *productService) setDisplayPrices(ctx context.Context, code string, products []domain.Product) error {
+	if code == "" {
+		return nil
+	}
+
+	rate, err := s.converter.Rate(ctx, parseCurrency(code))
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		price, err := s.converter.Convert(products[i].Price, rate)
+		if err != nil {
+			return err
+		}
+		products[i].DisplayPrice = &price
+	}
+
+	return nil
+}
+
+// parseCurrency reads a currency code from a request, the base currency
+// when there is none.
+func parseCurrency(code string) domain.Currency {
+	if code == "" {
+		return domain.BaseCurrency
+	}
+	return domain.Currency(strings.ToUpper(code))
+}
+
+// checkBasePrice rejects prices in a currency other than the one they are
+// stored in.
+func checkBasePrice(price domain.Money) error {
+	if price.Code() != domain.BaseCurrency {
+		return fmt.Errorf("%w: prices are set in %s", domain.ErrUnsupportedCurrency, domain.BaseCurrency)
+	}
+	return nil
+}
+
+func validatePriceRule(rule *domain.PriceRule) error {
+	switch rule.Type {
+	case domain.PriceRuleSale:
+		if rule.StartsAt == nil || rule.EndsAt == nil {
+			return fmt.Errorf("%w: a sale needs starts_at and ends_at", domain.ErrInvalidPriceRule)
+		}
+	case domain.PriceRuleTier:
+		if rule.MinQuantity < 2 {
+			return fmt.Errorf("%w: a tier needs a min_quantity of at least 2", domain.ErrInvalidPriceRule)
+		}
+	}
+
+	if rule.StartsAt != nil && rule.EndsAt != nil && !rule.EndsAt.After(*rule.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidPriceRule)
+	}
+
+	if rule.EndsAt != nil && !rule.EndsAt.After(time.Now()) {
+		return fmt.Errorf("%w: ends_at must be in the future", domain.ErrInvalidPriceRule)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/currency"
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/inventory"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/cache"
+)
+
+// noCache misses on every read.
+type noCache struct{}
+
+func (noCache) Set(context.Context, string, interface{}, time.Duration) error { return nil }
+func (noCache) Get(context.Context, string, interface{}) error                { return errors.New("miss") }
+func (noCache) Delete(context.Context, ...string) error                       { return nil }
+func (noCache) DeleteByPattern(context.Context, string) error                 { return nil }
+func (noCache) Exists(context.Context, string) bool                           { return false }
+func (noCache) FlushAll(context.Context) error                                { return nil }
+
+var _ cache.CacheService = noCache{}
+
+func newTestProductService(t *testing.T, f *orderFixture) ProductService {
+	t.Helper()
+
+	allocator, err := inventory.NewAllocator(inventory.StrategyPriority)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := f.db
+	stock := inventory.NewManager(repository.NewInventoryRepository(db), repository.NewWarehouseRepository(db), repository.NewStockReservationRepository(db), allocator)
+	converter := currency.NewConverter(repository.NewExchangeRateRepository(db), domain.RoundingPolicy{Mode: domain.RoundHalfUp})
+
+	return NewProductService(
+		repository.NewProductRepository(db),
+		repository.NewCategoryRepository(db),
+		repository.NewPriceRuleRepository(db),
+		repository.NewPriceHistoryRepository(db),
+		repository.NewProductTranslationRepository(db),
+		repository.NewCategoryTranslationRepository(db),
+		noCache{},
+		repository.NewTransactor(db),
+		stock,
+		event.NewOutboxPublisher(repository.NewOutboxRepository(db)),
+		converter,
+	)
+}
+
+func TestProductEditKeepsReservedStockAndRatings(t *testing.T) {
+	f := newOrderFixture(t)
+	products := newTestProductService(t, f)
+	product := f.product(t, 5)
+	f.order(t, product.ID, 2)
+
+	if err := f.db.Model(product).UpdateColumns(map[string]interface{}{"rating_average": 4.5, "rating_count": 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	category := &domain.Category{Name: "Gadgets"}
+	if err := f.db.Create(category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := products.Update(context.Background(), product.ID, dto.ProductRequest{
+		Name:       "Renamed widget",
+		Price:      product.Price,
+		Stock:      5,
+		SKU:        product.SKU,
+		CategoryID: category.ID,
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	f.assertStock(t, product.ID, 5, 2)
+
+	var saved domain.Product
+	if err := f.db.First(&saved, "id = ?", product.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "Renamed widget" {
+		t.Errorf("name = %q, want %q", saved.Name, "Renamed widget")
+	}
+	if saved.RatingCount != 2 || saved.RatingAverage != 4.5 {
+		t.Errorf("rating = %v from %d reviews, want 4.5 from 2", saved.RatingAverage, saved.RatingCount)
+	}
+}
+
+func TestUpdateStockCannotRemoveReservedStock(t *testing.T) {
+	f := newOrderFixture(t)
+	products := newTestProductService(t, f)
+	product := f.product(t, 5)
+	f.order(t, product.ID, 2)
+
+	if err := products.UpdateStock(context.Background(), product.ID, -4); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("UpdateStock = %v, want ErrInsufficientStock", err)
+	}
+	f.assertStock(t, product.ID, 5, 2)
+
+	if err := products.UpdateStock(context.Background(), product.ID, -3); err != nil {
+		t.Fatalf("UpdateStock: %v", err)
+	}
+	f.assertStock(t, product.ID, 2, 2)
+}
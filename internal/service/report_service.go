@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,8 +25,11 @@ func NewReportService(userRepo repository.UserRepository, productRepo repository
 	}
 }
 
-func (s *reportService) GenerateUsersReport(format string) ([]byte, string, error) {
-	users, _, err := s.userRepo.List(1, 1000)
+func (s *reportService) GenerateUsersReport(ctx context.Context, format string) ([]byte, string, error) {
+	ctx, span := tracer.Start(ctx, "reportService.GenerateUsersReport")
+	defer span.End()
+
+	users, _, err := s.userRepo.List(ctx, 1, 1000)
 	if err != nil {
 		return nil, "", err
 	}
@@ -118,8 +122,11 @@ func (s *reportService) generateUsersExcel(users []domain.User) ([]byte, string,
 	return data, filename, nil
 }
 
-func (s *reportService) GenerateProductsReport(format string) ([]byte, string, error) {
-	products, _, err := s.productRepo.List(dto.ProductQuery{
+func (s *reportService) GenerateProductsReport(ctx context.Context, format string) ([]byte, string, error) {
+	ctx, span := tracer.Start(ctx, "reportService.GenerateProductsReport")
+	defer span.End()
+
+	products, _, err := s.productRepo.List(ctx, dto.ProductQuery{
 		Page:  1,
 		Limit: 1000,
 	})
@@ -141,14 +148,18 @@ func (s *reportService) generateProductsPDF(products []domain.Product) ([]byte,
 	widths := []float64{10, 60, 40, 35, 25, 20}
 	pdf.AddTableHeader(headers, widths)
 
-	totalValue := 0.0
+	var totalValue domain.Money
 	totalStock := 0
 
 	for i, product := range products {
 		price := product.Price
 		stock := product.Stock
 
-		totalValue += price * float64(stock)
+		sum, err := totalValue.Add(price.Mul(stock))
+		if err != nil {
+			return nil, "", err
+		}
+		totalValue = sum
 		totalStock += stock
 
 		categoryName := ""
@@ -161,7 +172,7 @@ func (s *reportService) generateProductsPDF(products []domain.Product) ([]byte,
 			product.Name,
 			product.SKU,
 			categoryName,
-			fmt.Sprintf("Rp %.0f", price),
+			fmt.Sprintf("Rp %s", price.Decimal()),
 			fmt.Sprintf("%d", stock),
 		}
 		pdf.AddTableRow(values, widths)
@@ -170,7 +181,7 @@ func (s *reportService) generateProductsPDF(products []domain.Product) ([]byte,
 	summary := map[string]string{
 		"Total Products":        fmt.Sprintf("%d", len(products)),
 		"Total Stock":           fmt.Sprintf("%d", totalStock),
-		"Total Inventory Value": fmt.Sprintf("Rp %.0f", totalValue),
+		"Total Inventory Value": fmt.Sprintf("Rp %s", totalValue.Decimal()),
 		"Report Date":           time.Now().Format("2006-01-02"),
 	}
 	pdf.AddSummary("Summary", summary)
@@ -191,15 +202,19 @@ func (s *reportService) generateProductsExcel(products []domain.Product) ([]byte
 	headers := []string{"No", "Name", "SKU", "Category", "Price", "Stock", "Total Value"}
 	excel.AddTableHeader(headers)
 
-	totalValue := 0.0
+	var totalValue domain.Money
 	totalStock := 0
 
 	for i, product := range products {
 		price := product.Price
 		stock := product.Stock
-		itemValue := price * float64(stock)
+		itemValue := price.Mul(stock)
 
-		totalValue += itemValue
+		sum, err := totalValue.Add(itemValue)
+		if err != nil {
+			return nil, "", err
+		}
+		totalValue = sum
 		totalStock += stock
 
 		categoryName := ""
@@ -212,9 +227,9 @@ func (s *reportService) generateProductsExcel(products []domain.Product) ([]byte
 			product.Name,
 			product.SKU,
 			categoryName,
-			price,
+			price.Float64(),
 			stock,
-			itemValue,
+			itemValue.Float64(),
 		}
 		excel.AddTableRow(values)
 	}
@@ -222,7 +237,7 @@ func (s *reportService) generateProductsExcel(products []domain.Product) ([]byte
 	summary := map[string]interface{}{
 		"Total Products":        len(products),
 		"Total Stock":           totalStock,
-		"Total Inventory Value": totalValue,
+		"Total Inventory Value": totalValue.Float64(),
 		"Report Date":           time.Now().Format("2006-01-02 15:04:05"),
 	}
 	excel.AddSummary(summary)
@@ -237,8 +252,11 @@ func (s *reportService) generateProductsExcel(products []domain.Product) ([]byte
 	return data, filename, nil
 }
 
-func (s *reportService) GenerateOrdersReport(startDate, endDate time.Time, format string) ([]byte, string, error) {
-	orders, _, err := s.orderRepo.GetAll(1, 1000)
+func (s *reportService) GenerateOrdersReport(ctx context.Context, startDate, endDate time.Time, format string) ([]byte, string, error) {
+	ctx, span := tracer.Start(ctx, "reportService.GenerateOrdersReport")
+	defer span.End()
+
+	orders, _, err := s.orderRepo.GetAll(ctx, 1, 1000)
 	if err != nil {
 		return nil, "", err
 	}
@@ -266,37 +284,38 @@ func (s *reportService) generateOrdersPDF(orders []domain.Order, startDate, endD
 	widths := []float64{10, 45, 45, 30, 25, 35}
 	pdf.AddTableHeader(headers, widths)
 
-	totalAmount := 0.0
+	var totalAmount domain.Money
 
 	for i, order := range orders {
 		amount := order.TotalAmount
-		totalAmount += amount
-
-		customerName := ""
-		if order.User != nil {
-			customerName = order.User.Name
+		sum, err := totalAmount.Add(amount)
+		if err != nil {
+			return nil, "", err
 		}
+		totalAmount = sum
+
+		customerName := order.Contact().Name
 
 		values := []string{
 			fmt.Sprintf("%d", i+1),
 			order.OrderNumber,
 			customerName,
-			fmt.Sprintf("Rp %.0f", amount),
+			fmt.Sprintf("Rp %s", amount.Decimal()),
 			string(order.Status),
 			order.CreatedAt.Format("2006-01-02"),
 		}
 		pdf.AddTableRow(values, widths)
 	}
 
-	avgOrder := 0.0
+	var avgOrder domain.Money
 	if len(orders) > 0 {
-		avgOrder = totalAmount / float64(len(orders))
+		avgOrder = totalAmount.Div(len(orders))
 	}
 
 	summary := map[string]string{
 		"Total Orders":  fmt.Sprintf("%d", len(orders)),
-		"Total Revenue": fmt.Sprintf("Rp %.0f", totalAmount),
-		"Average Order": fmt.Sprintf("Rp %.0f", avgOrder),
+		"Total Revenue": fmt.Sprintf("Rp %s", totalAmount.Decimal()),
+		"Average Order": fmt.Sprintf("Rp %s", avgOrder.Decimal()),
 		"Report Date":   time.Now().Format("2006-01-02"),
 	}
 	pdf.AddSummary("Summary", summary)
@@ -318,40 +337,41 @@ func (s *reportService) generateOrdersExcel(orders []domain.Order, startDate, en
 	headers := []string{"No", "Order Number", "Customer", "Email", "Amount", "Status", "Date"}
 	excel.AddTableHeader(headers)
 
-	totalAmount := 0.0
+	var totalAmount domain.Money
 
 	for i, order := range orders {
 		amount := order.TotalAmount
-		totalAmount += amount
-
-		customerName := ""
-		customerEmail := ""
-		if order.User != nil {
-			customerName = order.User.Name
-			customerEmail = order.User.Email
+		sum, err := totalAmount.Add(amount)
+		if err != nil {
+			return nil, "", err
 		}
+		totalAmount = sum
+
+		contact := order.Contact()
+		customerName := contact.Name
+		customerEmail := contact.Email
 
 		values := []interface{}{
 			i + 1,
 			order.OrderNumber,
 			customerName,
 			customerEmail,
-			amount,
+			amount.Float64(),
 			string(order.Status),
 			order.CreatedAt.Format("2006-01-02 15:04"),
 		}
 		excel.AddTableRow(values)
 	}
 
-	avgOrder := 0.0
+	var avgOrder domain.Money
 	if len(orders) > 0 {
-		avgOrder = totalAmount / float64(len(orders))
+		avgOrder = totalAmount.Div(len(orders))
 	}
 
 	summary := map[string]interface{}{
 		"Total Orders":  len(orders),
-		"Total Revenue": totalAmount,
-		"Average Order": avgOrder,
+		"Total Revenue": totalAmount.Float64(),
+		"Average Order": avgOrder.Float64(),
 		"Report Date":   time.Now().Format("2006-01-02 15:04:05"),
 	}
 	excel.AddSummary(summary)
@@ -365,3 +385,133 @@ func (s *reportService) generateOrdersExcel(orders []domain.Order, startDate, en
 	filename := fmt.Sprintf("orders_report_%s.xlsx", time.Now().Format("20060102_150405"))
 	return data, filename, nil
 }
+
+func (s *reportService) GenerateLowStockReport(ctx context.Context, format string) ([]byte, string, error) {
+	ctx, span := tracer.Start(ctx, "reportService.GenerateLowStockReport")
+	defer span.End()
+
+	products, err := s.listLowStock(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == "pdf" {
+		return s.generateLowStockPDF(products)
+	}
+	return s.generateLowStockExcel(products)
+}
+
+// lowStockPageSize is how many products the low-stock report reads at once.
+const lowStockPageSize = 500
+
+// listLowStock pages through every low-stock product.
+func (s *reportService) listLowStock(ctx context.Context) ([]domain.Product, error) {
+	var products []domain.Product
+	for page := 1; ; page++ {
+		batch, total, err := s.productRepo.ListLowStock(ctx, page, lowStockPageSize)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, batch...)
+
+		if len(batch) < lowStockPageSize || int64(len(products)) >= total {
+			return products, nil
+		}
+	}
+}
+
+func (s *reportService) generateLowStockPDF(products []domain.Product) ([]byte, string, error) {
+	pdf := utils.NewPDFGenerator()
+	pdf.SetTitle("Low Stock Report")
+
+	headers := []string{"No", "Name", "SKU", "Stock", "Reorder Level", "Reorder Qty"}
+	widths := []float64{10, 60, 40, 20, 30, 30}
+	pdf.AddTableHeader(headers, widths)
+
+	outOfStock := 0
+	totalReorder := 0
+
+	for i, product := range products {
+		if product.Stock <= 0 {
+			outOfStock++
+		}
+		totalReorder += product.ReorderQuantity
+
+		values := []string{
+			fmt.Sprintf("%d", i+1),
+			product.Name,
+			product.SKU,
+			fmt.Sprintf("%d", product.Stock),
+			fmt.Sprintf("%d", product.ReorderLevel),
+			fmt.Sprintf("%d", product.ReorderQuantity),
+		}
+		pdf.AddTableRow(values, widths)
+	}
+
+	summary := map[string]string{
+		"Low Stock Products":   fmt.Sprintf("%d", len(products)),
+		"Out of Stock":         fmt.Sprintf("%d", outOfStock),
+		"Total Units to Order": fmt.Sprintf("%d", totalReorder),
+		"Report Date":          time.Now().Format("2006-01-02"),
+	}
+	pdf.AddSummary("Summary", summary)
+
+	data, err := pdf.Output()
+	if err != nil {
+		return nil, "", err
+	}
+
+	filename := fmt.Sprintf("low_stock_report_%s.pdf", time.Now().Format("20060102_150405"))
+	return data, filename, nil
+}
+
+func (s *reportService) generateLowStockExcel(products []domain.Product) ([]byte, string, error) {
+	excel := utils.NewExcelGenerator("Low Stock Report")
+	excel.SetTitle("Low Stock Report - " + time.Now().Format("2006-01-02"))
+
+	headers := []string{"No", "Name", "SKU", "Category", "Stock", "Reorder Level", "Reorder Qty"}
+	excel.AddTableHeader(headers)
+
+	outOfStock := 0
+	totalReorder := 0
+
+	for i, product := range products {
+		if product.Stock <= 0 {
+			outOfStock++
+		}
+		totalReorder += product.ReorderQuantity
+
+		categoryName := ""
+		if product.Category != nil {
+			categoryName = product.Category.Name
+		}
+
+		values := []interface{}{
+			i + 1,
+			product.Name,
+			product.SKU,
+			categoryName,
+			product.Stock,
+			product.ReorderLevel,
+			product.ReorderQuantity,
+		}
+		excel.AddTableRow(values)
+	}
+
+	summary := map[string]interface{}{
+		"Low Stock Products":   len(products),
+		"Out of Stock":         outOfStock,
+		"Total Units to Order": totalReorder,
+		"Report Date":          time.Now().Format("2006-01-02 15:04:05"),
+	}
+	excel.AddSummary(summary)
+	excel.AutoFitColumns(7)
+
+	data, err := excel.Output()
+	if err != nil {
+		return nil, "", err
+	}
+
+	filename := fmt.Sprintf("low_stock_report_%s.xlsx", time.Now().Format("20060102_150405"))
+	return data, filename, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+)
+
+// lowStockProducts serves ListLowStock from a fixed slice.
+type lowStockProducts struct {
+	repository.ProductRepository
+	products []domain.Product
+}
+
+func (r *lowStockProducts) ListLowStock(_ context.Context, page, limit int) ([]domain.Product, int64, error) {
+	start := min((page-1)*limit, len(r.products))
+	end := min(start+limit, len(r.products))
+	return r.products[start:end], int64(len(r.products)), nil
+}
+
+func TestLowStockReportListsEveryProduct(t *testing.T) {
+	for _, n := range []int{0, lowStockPageSize - 1, lowStockPageSize, 2*lowStockPageSize + 1} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			repo := &lowStockProducts{products: make([]domain.Product, n)}
+			service := &reportService{productRepo: repo}
+
+			products, err := service.listLowStock(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(products) != n {
+				t.Errorf("report lists %d products, want %d", len(products), n)
+			}
+		})
+	}
+}
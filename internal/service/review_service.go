@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/cache"
+)
+
+type reviewService struct {
+	reviewRepo   repository.ReviewRepository
+	productRepo  repository.ProductRepository
+	orderRepo    repository.OrderRepository
+	userRepo     repository.UserRepository
+	cacheService cache.CacheService
+	transactor   repository.Transactor
+}
+
+func NewReviewService(reviewRepo repository.ReviewRepository, productRepo repository.ProductRepository, orderRepo repository.OrderRepository, userRepo repository.UserRepository, cacheService cache.CacheService, transactor repository.Transactor) ReviewService {
+	return &reviewService{reviewRepo: reviewRepo, productRepo: productRepo, orderRepo: orderRepo, userRepo: userRepo, cacheService: cacheService, transactor: transactor}
+}
+
+func (s *reviewService) Create(ctx context.Context, userID, productID string, req dto.ReviewRequest) (*domain.Review, error) {
+	ctx, span := tracer.Start(ctx, "reviewService.Create")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.reviewRepo.GetByProductAndUser(ctx, productID, userID)
+	if err != nil && !errors.Is(err, domain.ErrReviewNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrReviewAlreadyExists
+	}
+
+	item, err := s.orderRepo.GetDeliveredItem(ctx, userID, productID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderNotFound) {
+			return nil, domain.ErrReviewNotAllowed
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &domain.Review{
+		ProductID:   productID,
+		UserID:      userID,
+		OrderItemID: item.ID,
+		AuthorName:  user.Name,
+		Rating:      req.Rating,
+		Title:       req.Title,
+		Body:        req.Body,
+		Status:      domain.ReviewStatusPending,
+	}
+
+	if err := s.reviewRepo.Create(ctx, review); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *reviewService) ListProductReviews(ctx context.Context, productID string, page, limit int) ([]domain.Review, int64, error) {
+	ctx, span := tracer.Start(ctx, "reviewService.ListProductReviews")
+	defer span.End()
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.reviewRepo.List(ctx, dto.ReviewQuery{
+		ProductID: productID,
+		Status:    string(domain.ReviewStatusApproved),
+		Page:      page,
+		Limit:     limit,
+	})
+}
+
+func (s *reviewService) List(ctx context.Context, query dto.ReviewQuery) ([]domain.Review, int64, error) {
+	ctx, span := tracer.Start(ctx, "reviewService.List")
+	defer span.End()
+
+	return s.reviewRepo.List(ctx, query)
+}
+
+func (s *reviewService) Approve(ctx context.Context, id string) (*domain.Review, error) {
+	ctx, span := tracer.Start(ctx, "reviewService.Approve")
+	defer span.End()
+
+	return s.moderate(ctx, id, domain.ReviewStatusApproved)
+}
+
+func (s *reviewService) Hide(ctx context.Context, id string) (*domain.Review, error) {
+	ctx, span := tracer.Start(ctx, "reviewService.Hide")
+	defer span.End()
+
+	return s.moderate(ctx, id, domain.ReviewStatusHidden)
+}
+
+// moderate changes the review's status and refreshes the product rating in
+// the same transaction, so the aggregate never disagrees with the reviews.
+func (s *reviewService) moderate(ctx context.Context, id string, status domain.ReviewStatus) (*domain.Review, error) {
+	review, err := s.reviewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.Status == status {
+		return review, nil
+	}
+
+	review.Moderate(status)
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.reviewRepo.Update(ctx, review); err != nil {
+			return err
+		}
+
+		return s.reviewRepo.RefreshProductRating(ctx, review.ProductID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheService.Delete(ctx, cache.ProductKey(review.ProductID))
+	s.cacheService.DeleteByPattern(ctx, cache.ProductsPrefix+"*")
+
+	return review, nil
+}
@@ -1,24 +1,56 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/internal/domain/dto"
 	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/mailer"
 	"github.com/affandisy/goshop/pkg/utils"
 )
 
+type UserServiceConfig struct {
+	Lockout               domain.LockoutPolicy
+	VerificationTokenTTL  time.Duration
+	PasswordResetTokenTTL time.Duration
+	AppBaseURL            string // used to build the links in verification and reset emails
+}
+
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	userTokenRepo    repository.UserTokenRepository
+	orderRepo        repository.OrderRepository
+	mailer           mailer.Mailer
+	cfg              UserServiceConfig
+	logger           *slog.Logger
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, loginAttemptRepo repository.LoginAttemptRepository, userTokenRepo repository.UserTokenRepository, orderRepo repository.OrderRepository, mailer mailer.Mailer, cfg UserServiceConfig, logger *slog.Logger) UserService {
+	return &userService{
+		userRepo:         userRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		userTokenRepo:    userTokenRepo,
+		orderRepo:        orderRepo,
+		mailer:           mailer,
+		cfg:              cfg,
+		logger:           logger,
+	}
 }
 
-func (s *userService) Register(req dto.UserRegisterRequest) (*domain.User, error) {
-	existingUser, err := s.userRepo.GetByEmail(req.Email)
+func (s *userService) Register(ctx context.Context, req dto.UserRegisterRequest) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.Register")
+	defer span.End()
+
+	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
 		return nil, err
 	}
@@ -42,41 +74,121 @@ func (s *userService) Register(req dto.UserRegisterRequest) (*domain.User, error
 
 	user.Password = hashedPassword
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
+	// The account exists either way; a failed email can be retried through
+	// ResendVerification.
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to send verification email",
+			slog.String("user_id", user.ID),
+			slog.Any("error", err),
+		)
+	}
+
 	return user, nil
 }
 
-func (s *userService) Login(req dto.UserLoginRequest) (*domain.User, string, error) {
-	user, err := s.userRepo.GetByEmail(req.Email)
+func (s *userService) Login(ctx context.Context, req dto.UserLoginRequest, clientIP, userAgent string) (*domain.User, string, error) {
+	ctx, span := tracer.Start(ctx, "userService.Login")
+	defer span.End()
+
+	now := time.Now()
+	attempt := &domain.LoginAttempt{
+		Email:     req.Email,
+		IPAddress: clientIP,
+		UserAgent: userAgent,
+	}
+
+	if s.cfg.Lockout.IPMaxFailures > 0 {
+		failures, err := s.loginAttemptRepo.CountFailedByIP(ctx, clientIP, now.Add(-s.cfg.Lockout.IPWindow))
+		if err != nil {
+			return nil, "", err
+		}
+		if failures >= int64(s.cfg.Lockout.IPMaxFailures) {
+			s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptIPBlocked)
+			return nil, "", domain.ErrTooManyAttempts
+		}
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
+			s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptInvalidCredentials)
 			return nil, "", domain.ErrInvalidCredentials
 		}
 
 		return nil, "", err
 	}
 
+	attempt.UserID = &user.ID
+
+	if user.IsLocked(now) {
+		s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptLocked)
+		return nil, "", domain.ErrAccountLocked
+	}
+
 	if !user.IsActive {
+		s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptInactive)
 		return nil, "", domain.ErrUnauthorized
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
+		user, err = s.userRepo.RegisterFailedLogin(ctx, user.ID, s.cfg.Lockout, now)
+		if errors.Is(err, domain.ErrAccountLocked) {
+			s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptLocked)
+			return nil, "", err
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		locked := user.IsLocked(now)
+
+		s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptInvalidCredentials)
+		s.logger.WarnContext(ctx, "Failed login attempt",
+			slog.String("user_id", user.ID),
+			slog.String("client_ip", clientIP),
+			slog.Bool("locked", locked),
+		)
+
+		if locked {
+			return nil, "", domain.ErrAccountLocked
+		}
 		return nil, "", domain.ErrInvalidCredentials
 	}
 
+	if user.FailedLoginAttempts > 0 || user.LockoutCount > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return nil, "", err
+		}
+		user.ResetLoginFailures()
+	}
+
 	token, err := utils.GenerateToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		return nil, "", err
 	}
 
+	s.recordLoginAttempt(ctx, attempt, domain.LoginAttemptSuccess)
+
 	return user, token, nil
 }
 
-func (s *userService) GetProfile(userID string) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(userID)
+// recordLoginAttempt writes the audit record. Failing to audit must not
+// change the outcome of the login, so errors are only logged.
+func (s *userService) recordLoginAttempt(ctx context.Context, attempt *domain.LoginAttempt, result domain.LoginAttemptResult) {
+	attempt.Result = result
+	if err := s.loginAttemptRepo.Create(ctx, attempt); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record login attempt", slog.Any("error", err))
+	}
+}
+
+func (s *userService) GetProfile(ctx context.Context, userID string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetProfile")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +196,11 @@ func (s *userService) GetProfile(userID string) (*domain.User, error) {
 	return user, nil
 }
 
-func (s *userService) UpdateProfile(userID string, req dto.UserRegisterRequest) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(userID)
+func (s *userService) UpdateProfile(ctx context.Context, userID string, req dto.UserRegisterRequest) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -101,17 +216,254 @@ func (s *userService) UpdateProfile(userID string, req dto.UserRegisterRequest)
 		user.Password = hashedPassword
 	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (s *userService) GetUsers(page, limit int) ([]domain.User, int64, error) {
-	users, total, err := s.userRepo.List(page, limit)
+func (s *userService) GetUsers(ctx context.Context, page, limit int) ([]domain.User, int64, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetUsers")
+	defer span.End()
+
+	users, total, err := s.userRepo.List(ctx, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 	return users, total, nil
 }
+
+func (s *userService) GetLoginAttempts(ctx context.Context, query dto.LoginAttemptQuery) ([]domain.LoginAttempt, int64, error) {
+	ctx, span := tracer.Start(ctx, "userService.GetLoginAttempts")
+	defer span.End()
+
+	return s.loginAttemptRepo.List(ctx, query)
+}
+
+func (s *userService) UnlockUser(ctx context.Context, userID string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.UnlockUser")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	user.Unlock()
+
+	s.logger.InfoContext(ctx, "User unlocked", slog.String("user_id", user.ID))
+
+	return user, nil
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.VerifyEmail")
+	defer span.End()
+
+	userToken, err := s.consumeToken(ctx, token, domain.UserTokenEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		user.MarkEmailVerified()
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		s.claimGuestOrders(ctx, user)
+	}
+
+	return user, nil
+}
+
+func (s *userService) ResendVerification(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "userService.ResendVerification")
+	defer span.End()
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		// Do not reveal whether the email is registered
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.userTokenRepo.InvalidateForUser(ctx, user.ID, domain.UserTokenEmailVerification); err != nil {
+		return err
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *userService) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := tracer.Start(ctx, "userService.ForgotPassword")
+	defer span.End()
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		// Do not reveal whether the email is registered
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !user.IsActive {
+		return nil
+	}
+
+	if err := s.userTokenRepo.InvalidateForUser(ctx, user.ID, domain.UserTokenPasswordReset); err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, domain.UserTokenPasswordReset, s.cfg.PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.buildLink("/reset-password", token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your GoShop password",
+		TextBody: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.Name, link, s.cfg.PasswordResetTokenTTL),
+		HTMLBody: fmt.Sprintf("<p>Hi %s,</p><p>We received a request to reset your password. <a href=\"%s\">Choose a new password</a>.</p><p>The link expires in %s. If you did not request this, you can ignore this email.</p>",
+			html.EscapeString(user.Name), html.EscapeString(link), s.cfg.PasswordResetTokenTTL),
+	})
+}
+
+func (s *userService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	ctx, span := tracer.Start(ctx, "userService.ResetPassword")
+	defer span.End()
+
+	userToken, err := s.consumeToken(ctx, req.Token, domain.UserTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	// Receiving the reset email proves ownership of the address, and a
+	// successful reset should let a locked out user back in.
+	newlyVerified := !user.IsEmailVerified()
+	if newlyVerified {
+		user.MarkEmailVerified()
+	}
+	user.ResetLoginFailures()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if newlyVerified {
+		s.claimGuestOrders(ctx, user)
+	}
+
+	s.logger.InfoContext(ctx, "Password reset", slog.String("user_id", user.ID))
+
+	return s.userTokenRepo.InvalidateForUser(ctx, user.ID, domain.UserTokenPasswordReset)
+}
+
+// claimGuestOrders attaches guest orders placed with the user's email once
+// they have proven they own it. Failures are logged, not returned: the
+// user can still claim later from their account.
+func (s *userService) claimGuestOrders(ctx context.Context, user *domain.User) {
+	claimed, err := s.orderRepo.ClaimGuestOrders(ctx, user.Email, user.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to claim guest orders",
+			slog.String("user_id", user.ID),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	if claimed > 0 {
+		s.logger.InfoContext(ctx, "Guest orders claimed",
+			slog.String("user_id", user.ID),
+			slog.Int64("count", claimed),
+		)
+	}
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	token, err := s.issueToken(ctx, user.ID, domain.UserTokenEmailVerification, s.cfg.VerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.buildLink("/verify-email", token)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your GoShop email address",
+		TextBody: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, link, s.cfg.VerificationTokenTTL),
+		HTMLBody: fmt.Sprintf("<p>Hi %s,</p><p>Please <a href=\"%s\">confirm your email address</a>.</p><p>The link expires in %s.</p>",
+			html.EscapeString(user.Name), html.EscapeString(link), s.cfg.VerificationTokenTTL),
+	})
+}
+
+// issueToken stores the hash of a fresh token and returns the raw token,
+// which is only ever sent to the user.
+func (s *userService) issueToken(ctx context.Context, userID string, purpose domain.UserTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	userToken := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.userTokenRepo.Create(ctx, userToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *userService) consumeToken(ctx context.Context, token string, purpose domain.UserTokenPurpose) (*domain.UserToken, error) {
+	userToken, err := s.userTokenRepo.GetByHash(ctx, utils.HashToken(token), purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userToken.IsUsable(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := s.userTokenRepo.MarkUsed(ctx, userToken.ID); err != nil {
+		return nil, err
+	}
+
+	return userToken, nil
+}
+
+func (s *userService) buildLink(path, token string) string {
+	return strings.TrimRight(s.cfg.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/database/databasetest"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+func TestConcurrentFailedLoginsLockOnce(t *testing.T) {
+	db := databasetest.Open(t)
+
+	const maxFailed, attempts = 5, 20
+	users := repository.NewUserRepository(db)
+	service := NewUserService(users, repository.NewLoginAttemptRepository(db), nil, nil, nil, UserServiceConfig{
+		Lockout: domain.LockoutPolicy{MaxFailedAttempts: maxFailed, BaseDuration: time.Hour, MaxDuration: 24 * time.Hour},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	password, err := utils.HashPassword("correct-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{Email: "customer@example.com", Password: password, Name: "Customer", IsActive: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		invalid int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _, err := service.Login(context.Background(), dto.UserLoginRequest{Email: user.Email, Password: "wrong-password"}, "192.0.2.1", "test")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, domain.ErrInvalidCredentials):
+				invalid++
+			case errors.Is(err, domain.ErrAccountLocked):
+			default:
+				t.Errorf("Login = %v, want invalid credentials or a locked account", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if invalid != maxFailed-1 {
+		t.Errorf("%d attempts were checked before the lockout, want %d", invalid, maxFailed-1)
+	}
+
+	got, err := users.GetByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsLocked(time.Now()) || got.LockoutCount != 1 || got.FailedLoginAttempts != 0 {
+		t.Errorf("locked until %v after %d lockouts with %d failures; want one lockout", got.LockedUntil, got.LockoutCount, got.FailedLoginAttempts)
+	}
+}
+
+func TestRejectedAttemptsDoNotExtendIPBlock(t *testing.T) {
+	db := databasetest.Open(t)
+
+	utils.InitJWt("test-secret")
+
+	const ip = "192.0.2.1"
+	users := repository.NewUserRepository(db)
+	service := NewUserService(users, repository.NewLoginAttemptRepository(db), nil, nil, nil, UserServiceConfig{
+		Lockout: domain.LockoutPolicy{IPMaxFailures: 3, IPWindow: time.Hour},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	password, err := utils.HashPassword("correct-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{Email: "customer@example.com", Password: password, Name: "Customer", IsActive: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	login := func(password string) error {
+		_, _, err := service.Login(context.Background(), dto.UserLoginRequest{Email: user.Email, Password: password}, ip, "test")
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := login("wrong-password"); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("Login = %v, want ErrInvalidCredentials", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if err := login("correct-password"); !errors.Is(err, domain.ErrTooManyAttempts) {
+			t.Fatalf("Login = %v, want ErrTooManyAttempts", err)
+		}
+	}
+
+	// Once the failures leave the window the IP is let through, however
+	// many blocked attempts came after them.
+	err = db.Model(&domain.LoginAttempt{}).
+		Where("result = ?", domain.LoginAttemptInvalidCredentials).
+		Update("created_at", time.Now().Add(-2*time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := login("correct-password"); err != nil {
+		t.Fatalf("Login = %v, want success", err)
+	}
+}
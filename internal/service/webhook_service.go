@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+type webhookService struct {
+	endpointRepo repository.WebhookEndpointRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+}
+
+func NewWebhookService(endpointRepo repository.WebhookEndpointRepository, deliveryRepo repository.WebhookDeliveryRepository) WebhookService {
+	return &webhookService{endpointRepo: endpointRepo, deliveryRepo: deliveryRepo}
+}
+
+// webhookPayload is the JSON body merchants receive.
+type webhookPayload struct {
+	ID        string          `json:"id"`
+	Type      event.Type      `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func (s *webhookService) CreateEndpoint(ctx context.Context, req dto.WebhookEndpointRequest) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.CreateEndpoint")
+	defer span.End()
+
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		Events:      req.Events,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := s.endpointRepo.Create(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) GetEndpoint(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.GetEndpoint")
+	defer span.End()
+
+	return s.endpointRepo.GetByID(ctx, id)
+}
+
+func (s *webhookService) ListEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.ListEndpoints")
+	defer span.End()
+
+	return s.endpointRepo.List(ctx)
+}
+
+func (s *webhookService) UpdateEndpoint(ctx context.Context, id string, req dto.WebhookEndpointRequest) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.UpdateEndpoint")
+	defer span.End()
+
+	if err := validateWebhookRequest(req); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.endpointRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.URL = req.URL
+	endpoint.Description = req.Description
+	endpoint.Events = req.Events
+
+	if req.IsActive != nil {
+		if *req.IsActive {
+			endpoint.Enable()
+		} else {
+			endpoint.IsActive = false
+		}
+	}
+
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "webhookService.DeleteEndpoint")
+	defer span.End()
+
+	if _, err := s.endpointRepo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.endpointRepo.Delete(ctx, id)
+}
+
+func (s *webhookService) RotateSecret(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.RotateSecret")
+	defer span.End()
+
+	endpoint, err := s.endpointRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Secret, err = newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, endpointID string, query dto.WebhookDeliveryQuery) ([]domain.WebhookDelivery, int64, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.ListDeliveries")
+	defer span.End()
+
+	if _, err := s.endpointRepo.GetByID(ctx, endpointID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.deliveryRepo.ListByEndpoint(ctx, endpointID, query)
+}
+
+// Redeliver queues a fresh copy of a delivery with the original payload,
+// leaving the original in the log.
+func (s *webhookService) Redeliver(ctx context.Context, endpointID, deliveryID string) (*domain.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "webhookService.Redeliver")
+	defer span.End()
+
+	original, err := s.deliveryRepo.GetByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if original.EndpointID != endpointID {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+
+	redelivery := domain.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		RedeliveryOf:  &original.ID,
+	}
+
+	// CreateBatch sets the ID on the slice element, not on redelivery.
+	deliveries := []domain.WebhookDelivery{redelivery}
+	if err := s.deliveryRepo.CreateBatch(ctx, deliveries); err != nil {
+		return nil, err
+	}
+
+	return &deliveries[0], nil
+}
+
+// HandleEvent queues a delivery of e for every active endpoint subscribed
+// to it. It runs as an event bus subscriber.
+func (s *webhookService) HandleEvent(ctx context.Context, e event.Event) error {
+	ctx, span := tracer.Start(ctx, "webhookService.HandleEvent")
+	defer span.End()
+
+	endpoints, err := s.endpointRepo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        e.ID,
+		Type:      e.Type,
+		CreatedAt: e.OccurredAt,
+		Data:      e.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	var deliveries []domain.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(string(e.Type)) {
+			continue
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       e.ID,
+			EventType:     string(e.Type),
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	// One insert, so a failure never leaves some endpoints queued and
+	// others not when the event is redelivered.
+	return s.deliveryRepo.CreateBatch(ctx, deliveries)
+}
+
+func validateWebhookRequest(req dto.WebhookEndpointRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return domain.ErrInvalidWebhookURL
+	}
+
+	for _, t := range req.Events {
+		if !event.IsValidType(event.Type(t)) {
+			return domain.ErrInvalidWebhookEvent
+		}
+	}
+
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	token, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type WishlistAlertSweeperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// WishlistAlertSweeper periodically sends wishlist alerts that were
+// deferred by the per-user alert limit.
+type WishlistAlertSweeper struct {
+	wishlistService WishlistService
+	cfg             WishlistAlertSweeperConfig
+	logger          *slog.Logger
+}
+
+func NewWishlistAlertSweeper(wishlistService WishlistService, cfg WishlistAlertSweeperConfig, logger *slog.Logger) *WishlistAlertSweeper {
+	return &WishlistAlertSweeper{
+		wishlistService: wishlistService,
+		cfg:             cfg,
+		logger:          logger,
+	}
+}
+
+// Run sends due alerts every interval until ctx is cancelled.
+func (s *WishlistAlertSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.wishlistService.SendDueAlerts(ctx, s.cfg.BatchSize); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Wishlist alert sweep failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
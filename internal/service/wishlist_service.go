@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/notification"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/ratelimit"
+)
+
+type WishlistServiceConfig struct {
+	AlertLimit          int // alerts per user per AlertWindow
+	AlertWindow         time.Duration
+	BackInStockCooldown time.Duration // minimum time between back-in-stock alerts for the same item
+}
+
+type wishlistService struct {
+	wishlistRepo repository.WishlistRepository
+	productRepo  repository.ProductRepository
+	notifier     notification.Notifier
+	limiter      ratelimit.Limiter
+	cfg          WishlistServiceConfig
+	logger       *slog.Logger
+}
+
+// NewWishlistService creates the wishlist service. limiter may be nil, in
+// which case alerts are not rate limited.
+func NewWishlistService(wishlistRepo repository.WishlistRepository, productRepo repository.ProductRepository, notifier notification.Notifier, limiter ratelimit.Limiter, cfg WishlistServiceConfig, logger *slog.Logger) WishlistService {
+	return &wishlistService{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		notifier:     notifier,
+		limiter:      limiter,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+func (s *wishlistService) List(ctx context.Context, userID string, page, limit int) ([]domain.WishlistItem, int64, error) {
+	ctx, span := tracer.Start(ctx, "wishlistService.List")
+	defer span.End()
+
+	return s.wishlistRepo.ListByUser(ctx, userID, page, limit)
+}
+
+// Add wishlists a product. Adding one that is already there returns the
+// existing entry, keeping its original price snapshot.
+func (s *wishlistService) Add(ctx context.Context, userID string, req dto.WishlistRequest) (*domain.WishlistItem, error) {
+	ctx, span := tracer.Start(ctx, "wishlistService.Add")
+	defer span.End()
+
+	existing, err := s.wishlistRepo.GetByUserAndProduct(ctx, userID, req.ProductID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, domain.ErrWishlistItemNotFound) {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &domain.WishlistItem{
+		UserID:        userID,
+		ProductID:     product.ID,
+		PriceSnapshot: product.Price,
+	}
+
+	if err := s.wishlistRepo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+
+	item.Product = product
+
+	return item, nil
+}
+
+func (s *wishlistService) Remove(ctx context.Context, userID, productID string) error {
+	ctx, span := tracer.Start(ctx, "wishlistService.Remove")
+	defer span.End()
+
+	return s.wishlistRepo.Delete(ctx, userID, productID)
+}
+
+// HandleEvent sends back-in-stock alerts on stock.changed and price-drop
+// alerts on product.updated. It runs as an event bus subscriber; alerts
+// that fail fail the event, so the relay retries them. Alerts over the
+// user's limit are deferred and sent by SendDueAlerts.
+func (s *wishlistService) HandleEvent(ctx context.Context, e event.Event) error {
+	ctx, span := tracer.Start(ctx, "wishlistService.HandleEvent")
+	defer span.End()
+
+	switch e.Type {
+	case event.StockChanged:
+		var payload event.StockChangedPayload
+		if err := e.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Stock-payload.Delta > 0 || payload.Stock <= 0 {
+			return nil
+		}
+		return s.alertProduct(ctx, e.ID, payload.ProductID, domain.WishlistAlertBackInStock)
+	case event.ProductUpdated:
+		var payload event.ProductPayload
+		if err := e.Decode(&payload); err != nil {
+			return err
+		}
+		return s.alertProduct(ctx, e.ID, payload.ProductID, domain.WishlistAlertPriceDrop)
+	}
+
+	return nil
+}
+
+func (s *wishlistService) alertProduct(ctx context.Context, eventID, productID string, alert domain.WishlistAlert) error {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.wishlistRepo.ListByProduct(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range items {
+		if items[i].User == nil {
+			continue
+		}
+		if err := s.sendAlert(ctx, eventID, &items[i], product, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SendDueAlerts sends up to limit deferred alerts that are due, deferring
+// them again while the user is still over the limit. Alerts that no longer
+// apply, e.g. because the product sold out again, are dropped.
+func (s *wishlistService) SendDueAlerts(ctx context.Context, limit int) error {
+	ctx, span := tracer.Start(ctx, "wishlistService.SendDueAlerts")
+	defer span.End()
+
+	now := time.Now()
+	items, err := s.wishlistRepo.ListAlertsDue(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.User == nil || item.Product == nil {
+			continue
+		}
+
+		for alert, dueAt := range map[domain.WishlistAlert]*time.Time{
+			domain.WishlistAlertBackInStock: item.BackInStockAlertDueAt,
+			domain.WishlistAlertPriceDrop:   item.PriceDropAlertDueAt,
+		} {
+			if dueAt == nil || dueAt.After(now) {
+				continue
+			}
+			// A failed alert stays due and is tried on the next sweep.
+			if err := s.sendAlert(ctx, "", item, item.Product, alert); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to send deferred wishlist alert",
+					slog.String("wishlist_item_id", item.ID),
+					slog.String("alert", string(alert)),
+					slog.Any("error", err),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sendAlert sends alert for item if it still applies. The item is marked
+// only once the alert is out, so a failed alert is sent again on retry;
+// eventID lets a retried event skip the channels that already sent it.
+func (s *wishlistService) sendAlert(ctx context.Context, eventID string, item *domain.WishlistItem, product *domain.Product, alert domain.WishlistAlert) error {
+	var n notification.Event
+	switch alert {
+	case domain.WishlistAlertBackInStock:
+		// It may have sold out again before the alert was sent.
+		cooling := item.BackInStockAlertedAt != nil && time.Since(*item.BackInStockAlertedAt) < s.cfg.BackInStockCooldown
+		if !product.IsAvailable() || cooling {
+			return s.cancelAlert(ctx, item, alert)
+		}
+		n = notification.ProductEvent(notification.EventWishlistBackInStock, product, item.User)
+	case domain.WishlistAlertPriceDrop:
+		if !product.IsActive {
+			return s.cancelAlert(ctx, item, alert)
+		}
+		dropped, err := item.PriceDropBelow(product.Price)
+		if err != nil {
+			// A mismatch won't go away on retry, so it only skips the item.
+			s.logger.WarnContext(ctx, "Skipping wishlist price drop alert",
+				slog.String("wishlist_item_id", item.ID),
+				slog.Any("error", err),
+			)
+			return s.cancelAlert(ctx, item, alert)
+		}
+		if !dropped {
+			return s.cancelAlert(ctx, item, alert)
+		}
+		n = notification.ProductEvent(notification.EventWishlistPriceDrop, product, item.User)
+		n.PreviousPrice = item.PreviousPrice()
+	default:
+		return nil
+	}
+
+	if retryAfter, ok := s.allow(ctx, item.UserID); !ok {
+		dueAt := time.Now().Add(retryAfter)
+		return s.wishlistRepo.ScheduleAlert(ctx, item.ID, alert, &dueAt)
+	}
+
+	n.EventID = eventID
+	n.Scope = item.ID
+	if err := s.notifier.Deliver(ctx, n); err != nil {
+		return err
+	}
+
+	if alert == domain.WishlistAlertBackInStock {
+		return s.wishlistRepo.MarkBackInStockAlerted(ctx, item.ID, time.Now())
+	}
+	return s.wishlistRepo.MarkPriceAlerted(ctx, item.ID, product.Price)
+}
+
+// cancelAlert drops a deferred alert that no longer applies.
+func (s *wishlistService) cancelAlert(ctx context.Context, item *domain.WishlistItem, alert domain.WishlistAlert) error {
+	dueAt := item.BackInStockAlertDueAt
+	if alert == domain.WishlistAlertPriceDrop {
+		dueAt = item.PriceDropAlertDueAt
+	}
+	if dueAt == nil {
+		return nil
+	}
+	return s.wishlistRepo.ScheduleAlert(ctx, item.ID, alert, nil)
+}
+
+// allow applies the per-user alert limit, returning how long to wait when
+// it is reached. A limiter outage lets the alert through rather than
+// silently dropping it.
+func (s *wishlistService) allow(ctx context.Context, userID string) (time.Duration, bool) {
+	if s.limiter == nil || s.cfg.AlertLimit <= 0 {
+		return 0, true
+	}
+
+	result, err := s.limiter.Allow(ctx, "wishlist_alert:"+userID, s.cfg.AlertLimit, s.cfg.AlertWindow)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Rate limiter unavailable", slog.String("policy", "wishlist_alert"), slog.Any("error", err))
+		return 0, true
+	}
+	if result.Allowed {
+		return 0, true
+	}
+
+	s.logger.InfoContext(ctx, "Wishlist alert rate limited, deferred", slog.String("user_id", userID))
+	if result.RetryAfter <= 0 {
+		return s.cfg.AlertWindow, false
+	}
+	return result.RetryAfter, false
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/event"
+	"github.com/affandisy/goshop/internal/notification"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/ratelimit"
+)
+
+// memoryWishlist holds one product's wishlist entries.
+type memoryWishlist struct {
+	repository.WishlistRepository
+	items []domain.WishlistItem
+}
+
+func (r *memoryWishlist) ListByProduct(_ context.Context, productID string) ([]domain.WishlistItem, error) {
+	var items []domain.WishlistItem
+	for _, item := range r.items {
+		if item.ProductID == productID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *memoryWishlist) MarkBackInStockAlerted(_ context.Context, id string, at time.Time) error {
+	for i := range r.items {
+		if r.items[i].ID == id {
+			r.items[i].BackInStockAlertedAt = &at
+			r.items[i].BackInStockAlertDueAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryWishlist) MarkPriceAlerted(_ context.Context, id string, price domain.Money) error {
+	for i := range r.items {
+		if r.items[i].ID == id {
+			r.items[i].LastAlertedPrice = &price
+			r.items[i].PriceDropAlertDueAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *memoryWishlist) ScheduleAlert(_ context.Context, id string, alert domain.WishlistAlert, dueAt *time.Time) error {
+	for i := range r.items {
+		if r.items[i].ID != id {
+			continue
+		}
+		if alert == domain.WishlistAlertBackInStock {
+			r.items[i].BackInStockAlertDueAt = dueAt
+		} else {
+			r.items[i].PriceDropAlertDueAt = dueAt
+		}
+	}
+	return nil
+}
+
+func (r *memoryWishlist) ListAlertsDue(_ context.Context, now time.Time, limit int) ([]domain.WishlistItem, error) {
+	var items []domain.WishlistItem
+	for _, item := range r.items {
+		due := func(at *time.Time) bool { return at != nil && !at.After(now) }
+		if (due(item.BackInStockAlertDueAt) || due(item.PriceDropAlertDueAt)) && len(items) < limit {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// switchLimiter allows or refuses every call.
+type switchLimiter struct {
+	allowed bool
+}
+
+func (l *switchLimiter) Allow(_ context.Context, _ string, limit int, window time.Duration) (ratelimit.Result, error) {
+	if l.allowed {
+		return ratelimit.Result{Allowed: true, Limit: limit}, nil
+	}
+	return ratelimit.Result{Limit: limit, RetryAfter: window}, nil
+}
+
+type singleProduct struct {
+	repository.ProductRepository
+	product *domain.Product
+}
+
+func (r singleProduct) GetByID(context.Context, string) (*domain.Product, error) {
+	p := *r.product
+	return &p, nil
+}
+
+// recordingNotifier records queued and delivered events, and fails
+// deliveries with err.
+type recordingNotifier struct {
+	mu        sync.Mutex
+	err       error
+	notified  []notification.Event
+	delivered []notification.Event
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, e notification.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notified = append(n.notified, e)
+}
+
+// count returns how many events of type eventType were queued.
+func (n *recordingNotifier) count(eventType notification.EventType) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	count := 0
+	for _, e := range n.notified {
+		if e.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func (n *recordingNotifier) Deliver(_ context.Context, e notification.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.delivered = append(n.delivered, e)
+	return nil
+}
+
+func newWishlistTest(t *testing.T, limiter ratelimit.Limiter) (*memoryWishlist, *recordingNotifier, *domain.Product, WishlistService) {
+	t.Helper()
+
+	product := &domain.Product{Name: "Widget", Price: domain.IDR(8000), Stock: 5, IsActive: true}
+	product.ID = "product-1"
+	user := &domain.User{Name: "Customer", Email: "customer@example.com"}
+	user.ID = "user-1"
+
+	item := domain.WishlistItem{UserID: user.ID, ProductID: product.ID, PriceSnapshot: domain.IDR(10000), User: user, Product: product}
+	item.ID = "item-1"
+
+	wishlist := &memoryWishlist{items: []domain.WishlistItem{item}}
+	notifier := &recordingNotifier{}
+	service := NewWishlistService(wishlist, singleProduct{product: product}, notifier, limiter, WishlistServiceConfig{
+		AlertLimit:          1,
+		AlertWindow:         time.Hour,
+		BackInStockCooldown: time.Hour,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	return wishlist, notifier, product, service
+}
+
+func backInStockEvent(t *testing.T, productID string) event.Event {
+	t.Helper()
+
+	payload, err := json.Marshal(event.StockChangedPayload{ProductID: productID, Delta: 5, Stock: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event.Event{ID: "event-1", Type: event.StockChanged, AggregateID: productID, Payload: payload}
+}
+
+func priceDropEvent(t *testing.T, productID string) event.Event {
+	t.Helper()
+
+	payload, err := json.Marshal(event.ProductPayload{ProductID: productID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event.Event{ID: "event-2", Type: event.ProductUpdated, AggregateID: productID, Payload: payload}
+}
+
+func TestBackInStockAlertMarkedAfterDelivery(t *testing.T) {
+	wishlist, notifier, product, service := newWishlistTest(t, nil)
+	ctx := context.Background()
+	e := backInStockEvent(t, product.ID)
+
+	notifier.err = errors.New("smtp unavailable")
+	if err := service.HandleEvent(ctx, e); !errors.Is(err, notifier.err) {
+		t.Fatalf("HandleEvent = %v, want the delivery error", err)
+	}
+	if wishlist.items[0].BackInStockAlertedAt != nil {
+		t.Fatal("item marked as alerted although the alert was not delivered")
+	}
+
+	notifier.err = nil
+	if err := service.HandleEvent(ctx, e); err != nil {
+		t.Fatalf("HandleEvent retry: %v", err)
+	}
+	if len(notifier.delivered) != 1 || wishlist.items[0].BackInStockAlertedAt == nil {
+		t.Fatalf("delivered %d alerts, alerted at %v; want 1 alert and the item marked", len(notifier.delivered), wishlist.items[0].BackInStockAlertedAt)
+	}
+
+	// Within the cooldown a redelivered event alerts nobody.
+	if err := service.HandleEvent(ctx, e); err != nil {
+		t.Fatalf("HandleEvent again: %v", err)
+	}
+	if len(notifier.delivered) != 1 {
+		t.Errorf("delivered %d alerts, want 1", len(notifier.delivered))
+	}
+}
+
+func TestRateLimitedBackInStockAlertIsDeferred(t *testing.T) {
+	limiter := &switchLimiter{}
+	wishlist, notifier, product, service := newWishlistTest(t, limiter)
+	ctx := context.Background()
+
+	if err := service.HandleEvent(ctx, backInStockEvent(t, product.ID)); err != nil {
+		t.Fatalf("HandleEvent = %v, want the alert deferred without failing the event", err)
+	}
+	item := wishlist.items[0]
+	if len(notifier.delivered) != 0 || item.BackInStockAlertedAt != nil {
+		t.Fatalf("delivered %d alerts, alerted at %v; want none until the limit allows it", len(notifier.delivered), item.BackInStockAlertedAt)
+	}
+	if item.BackInStockAlertDueAt == nil || time.Until(*item.BackInStockAlertDueAt) < 59*time.Minute {
+		t.Fatalf("alert due at %v, want it deferred for the alert window", item.BackInStockAlertDueAt)
+	}
+
+	// Not due yet.
+	if err := service.SendDueAlerts(ctx, 10); err != nil {
+		t.Fatalf("SendDueAlerts: %v", err)
+	}
+	if len(notifier.delivered) != 0 {
+		t.Fatalf("delivered %d alerts before they were due", len(notifier.delivered))
+	}
+
+	past := time.Now().Add(-time.Minute)
+	wishlist.items[0].BackInStockAlertDueAt = &past
+	limiter.allowed = true
+	if err := service.SendDueAlerts(ctx, 10); err != nil {
+		t.Fatalf("SendDueAlerts: %v", err)
+	}
+	item = wishlist.items[0]
+	if len(notifier.delivered) != 1 || item.BackInStockAlertedAt == nil || item.BackInStockAlertDueAt != nil {
+		t.Errorf("delivered %d alerts, alerted at %v, due at %v; want the deferred alert sent", len(notifier.delivered), item.BackInStockAlertedAt, item.BackInStockAlertDueAt)
+	}
+}
+
+func TestDeferredAlertDroppedWhenSoldOut(t *testing.T) {
+	limiter := &switchLimiter{}
+	wishlist, notifier, product, service := newWishlistTest(t, limiter)
+	ctx := context.Background()
+
+	if err := service.HandleEvent(ctx, backInStockEvent(t, product.ID)); err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	wishlist.items[0].BackInStockAlertDueAt = &past
+	wishlist.items[0].Product = &domain.Product{Name: product.Name, Price: product.Price, IsActive: true}
+	limiter.allowed = true
+	if err := service.SendDueAlerts(ctx, 10); err != nil {
+		t.Fatalf("SendDueAlerts: %v", err)
+	}
+
+	if len(notifier.delivered) != 0 || wishlist.items[0].BackInStockAlertDueAt != nil {
+		t.Errorf("delivered %d alerts, due at %v; want the alert dropped", len(notifier.delivered), wishlist.items[0].BackInStockAlertDueAt)
+	}
+}
+
+func TestPriceDropAlertMarkedAfterDelivery(t *testing.T) {
+	wishlist, notifier, product, service := newWishlistTest(t, nil)
+	ctx := context.Background()
+	e := priceDropEvent(t, product.ID)
+
+	notifier.err = errors.New("smtp unavailable")
+	if err := service.HandleEvent(ctx, e); !errors.Is(err, notifier.err) {
+		t.Fatalf("HandleEvent = %v, want the delivery error", err)
+	}
+	if wishlist.items[0].LastAlertedPrice != nil {
+		t.Fatal("item marked as alerted although the alert was not delivered")
+	}
+
+	notifier.err = nil
+	if err := service.HandleEvent(ctx, e); err != nil {
+		t.Fatalf("HandleEvent retry: %v", err)
+	}
+	if len(notifier.delivered) != 1 {
+		t.Fatalf("delivered %d alerts, want 1", len(notifier.delivered))
+	}
+	if got := notifier.delivered[0].PreviousPrice; !got.Equal(domain.IDR(10000)) {
+		t.Errorf("previous price = %s, want the wishlisted price", got)
+	}
+	if got := wishlist.items[0].LastAlertedPrice; got == nil || !got.Equal(product.Price) {
+		t.Errorf("last alerted price = %v, want %s", got, product.Price)
+	}
+
+	if err := service.HandleEvent(ctx, e); err != nil {
+		t.Fatalf("HandleEvent again: %v", err)
+	}
+	if len(notifier.delivered) != 1 {
+		t.Errorf("delivered %d alerts for the same price, want 1", len(notifier.delivered))
+	}
+}
@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/repository"
+	"github.com/affandisy/goshop/pkg/metrics"
+	"github.com/affandisy/goshop/pkg/utils"
+)
+
+// maxResponseBody is how much of the receiver's response is kept in the
+// delivery log.
+const maxResponseBody = 1024
+
+type DelivererConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	Timeout        time.Duration
+	DisableAfter   int // consecutive failed attempts before an endpoint is disabled
+}
+
+// Deliverer sends pending webhook deliveries and records the outcome of
+// every attempt.
+type Deliverer struct {
+	transactor   repository.Transactor
+	endpointRepo repository.WebhookEndpointRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	client       *http.Client
+	cfg          DelivererConfig
+	logger       *slog.Logger
+}
+
+func NewDeliverer(transactor repository.Transactor, endpointRepo repository.WebhookEndpointRepository, deliveryRepo repository.WebhookDeliveryRepository, cfg DelivererConfig, logger *slog.Logger) *Deliverer {
+	return &Deliverer{
+		transactor:   transactor,
+		endpointRepo: endpointRepo,
+		deliveryRepo: deliveryRepo,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// Run polls for due deliveries until ctx is cancelled.
+func (d *Deliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := d.processBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				d.logger.ErrorContext(ctx, "Webhook delivery batch failed", slog.Any("error", err))
+			}
+			if err != nil || n < d.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Deliverer) processBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// The endpoint is read again for every delivery, as an earlier
+		// failure in this batch or an admin may have disabled or removed
+		// it. Its deliveries then stay pending until it is enabled again.
+		endpoint, err := d.endpointRepo.GetByID(ctx, delivery.EndpointID)
+		if errors.Is(err, domain.ErrWebhookEndpointNotFound) {
+			continue
+		}
+		if err != nil {
+			return len(deliveries), err
+		}
+		if !endpoint.IsActive {
+			continue
+		}
+
+		if err := d.attempt(ctx, endpoint, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// claim leases a batch of due deliveries by pushing their next attempt past
+// the send timeout, so a crash mid-send only delays them.
+func (d *Deliverer) claim(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+
+	err := d.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = d.deliveryRepo.FetchDue(ctx, time.Now(), d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		lease := time.Now().Add(d.cfg.Timeout + time.Minute)
+		for i := range deliveries {
+			deliveries[i].NextAttemptAt = lease
+			if err := d.deliveryRepo.Update(ctx, &deliveries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return deliveries, err
+}
+
+func (d *Deliverer) attempt(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) error {
+	status, body, err := d.send(ctx, endpoint, delivery)
+
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		metrics.WebhookDeliveriesTotal.WithLabelValues("succeeded").Inc()
+
+		if err := d.endpointRepo.RecordSuccess(ctx, endpoint.ID); err != nil {
+			return err
+		}
+		return d.deliveryRepo.Update(ctx, delivery)
+	}
+
+	delivery.LastError = err.Error()
+	attrs := []any{
+		slog.String("endpoint_id", endpoint.ID),
+		slog.String("delivery_id", delivery.ID),
+		slog.String("event_type", delivery.EventType),
+		slog.Int("attempt", delivery.Attempts),
+		slog.Any("error", err),
+	}
+
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+		metrics.WebhookDeliveriesTotal.WithLabelValues("failed").Inc()
+		d.logger.ErrorContext(ctx, "Webhook delivery failed, giving up", attrs...)
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(utils.Backoff(delivery.Attempts, d.cfg.RetryBaseDelay, d.cfg.RetryMaxDelay))
+		metrics.WebhookDeliveriesTotal.WithLabelValues("retry").Inc()
+		d.logger.WarnContext(ctx, "Webhook delivery failed, retrying", attrs...)
+	}
+
+	disabled, err := d.endpointRepo.RecordFailure(ctx, endpoint.ID, d.cfg.DisableAfter)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		d.logger.WarnContext(ctx, "Webhook endpoint disabled after repeated failures",
+			slog.String("endpoint_id", endpoint.ID),
+			slog.String("url", endpoint.URL),
+		)
+	}
+
+	return d.deliveryRepo.Update(ctx, delivery)
+}
+
+// send POSTs the delivery and returns the response status and a truncated
+// body. Any non-2xx response is an error.
+func (d *Deliverer) send(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoShop-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	SetSignatureHeaders(req, endpoint.Secret, body, time.Now())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Postgres text columns reject NUL bytes and invalid UTF-8.
+	logged := strings.ReplaceAll(strings.ToValidUTF8(string(respBody), ""), "\x00", "")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, logged, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, logged, nil
+}
@@ -0,0 +1,362 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/service"
+	"github.com/affandisy/goshop/internal/webhook"
+)
+
+const testSecret = "whsec_test"
+
+var testConfig = webhook.DelivererConfig{
+	PollInterval:   10 * time.Millisecond,
+	BatchSize:      10,
+	MaxAttempts:    3,
+	RetryBaseDelay: time.Hour,
+	RetryMaxDelay:  24 * time.Hour,
+	Timeout:        time.Second,
+	DisableAfter:   10,
+}
+
+func newDeliverer(store *memoryWebhooks, cfg webhook.DelivererConfig) *webhook.Deliverer {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return webhook.NewDeliverer(noTransactor{}, store.endpointRepo(), store.deliveryRepo(), cfg, logger)
+}
+
+// deliverDue runs the deliverer until nothing is due.
+func deliverDue(t *testing.T, d *webhook.Deliverer, store *memoryWebhooks) {
+	t.Helper()
+
+	select {
+	case <-store.idle:
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-store.idle:
+	case <-time.After(10 * time.Second):
+		t.Error("deliverer did not finish the due deliveries")
+	}
+	cancel()
+	<-done
+}
+
+func addEndpoint(t *testing.T, store *memoryWebhooks, url string) *domain.WebhookEndpoint {
+	t.Helper()
+
+	endpoint := &domain.WebhookEndpoint{URL: url, Secret: testSecret, IsActive: true}
+	if err := store.endpointRepo().Create(context.Background(), endpoint); err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+func addDeliveries(t *testing.T, store *memoryWebhooks, endpoint *domain.WebhookEndpoint, n int) []domain.WebhookDelivery {
+	t.Helper()
+
+	deliveries := make([]domain.WebhookDelivery, n)
+	for i := range deliveries {
+		deliveries[i] = domain.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       "evt_" + strconv.Itoa(i),
+			EventType:     "order.paid",
+			Payload:       `{"id":"evt_` + strconv.Itoa(i) + `"}`,
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+	if err := store.deliveryRepo().CreateBatch(context.Background(), deliveries); err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+// makeDue moves a delivery's next attempt to now, as if its backoff passed.
+func makeDue(t *testing.T, store *memoryWebhooks, id string) {
+	t.Helper()
+
+	delivery := store.delivery(id)
+	delivery.NextAttemptAt = time.Now()
+	if err := store.deliveryRepo().Update(context.Background(), &delivery); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelivererSendsSignedRequests(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received *http.Request
+		body     []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	delivery := addDeliveries(t, store, endpoint, 1)[0]
+
+	before := time.Now().Unix()
+	deliverDue(t, newDeliverer(store, testConfig), store)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received == nil {
+		t.Fatal("endpoint was not called")
+	}
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", received.Method)
+	}
+	if got := string(body); got != delivery.Payload {
+		t.Errorf("body = %s, want %s", got, delivery.Payload)
+	}
+	for header, want := range map[string]string{
+		"Content-Type":         "application/json",
+		webhook.EventHeader:    delivery.EventType,
+		webhook.DeliveryHeader: delivery.ID,
+	} {
+		if got := received.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	timestamp := received.Header.Get(webhook.TimestampHeader)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || ts < before || ts > time.Now().Unix() {
+		t.Fatalf("%s = %q, want the send time in unix seconds", webhook.TimestampHeader, timestamp)
+	}
+
+	signature := received.Header.Get(webhook.SignatureHeader)
+	if !strings.HasPrefix(signature, "v1=") {
+		t.Fatalf("%s = %q, want a v1= signature", webhook.SignatureHeader, signature)
+	}
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	if want := hex.EncodeToString(mac.Sum(nil)); !hmac.Equal([]byte(signature[3:]), []byte(want)) {
+		t.Errorf("signature %s does not verify, want v1=%s", signature, want)
+	}
+
+	got := store.delivery(delivery.ID)
+	if got.Status != domain.WebhookDeliverySucceeded || got.Attempts != 1 || got.DeliveredAt == nil {
+		t.Errorf("delivery status = %s, attempts = %d, delivered at = %v; want succeeded after 1 attempt", got.Status, got.Attempts, got.DeliveredAt)
+	}
+	if got.ResponseStatus != http.StatusOK || got.ResponseBody != "ok" {
+		t.Errorf("response = %d %q, want 200 \"ok\"", got.ResponseStatus, got.ResponseBody)
+	}
+}
+
+func TestDelivererRetriesFailedResponsesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	delivery := addDeliveries(t, store, endpoint, 1)[0]
+	deliverer := newDeliverer(store, testConfig)
+
+	// Backoff doubles from RetryBaseDelay, plus up to 20% jitter.
+	for attempt, delay := range []time.Duration{time.Hour, 2 * time.Hour} {
+		sent := time.Now()
+		deliverDue(t, deliverer, store)
+
+		got := store.delivery(delivery.ID)
+		if got.Status != domain.WebhookDeliveryPending || got.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d: status = %s, attempts = %d; want pending", attempt+1, got.Status, got.Attempts)
+		}
+		if got.ResponseStatus != http.StatusServiceUnavailable || !strings.Contains(got.LastError, "503") {
+			t.Errorf("after attempt %d: response status = %d, last error = %q", attempt+1, got.ResponseStatus, got.LastError)
+		}
+		if wait := got.NextAttemptAt.Sub(sent); wait < delay || wait > delay+delay/5+time.Second {
+			t.Errorf("after attempt %d: retry in %s, want %s plus jitter", attempt+1, wait, delay)
+		}
+
+		// Not due yet, so polling again sends nothing.
+		deliverDue(t, deliverer, store)
+		if n := calls.Load(); n != int32(attempt+1) {
+			t.Fatalf("endpoint called %d times before the retry was due, want %d", n, attempt+1)
+		}
+		makeDue(t, store, delivery.ID)
+	}
+
+	deliverDue(t, deliverer, store)
+
+	got := store.delivery(delivery.ID)
+	if got.Status != domain.WebhookDeliveryFailed || got.Attempts != testConfig.MaxAttempts {
+		t.Errorf("status = %s after %d attempts, want failed after %d", got.Status, got.Attempts, testConfig.MaxAttempts)
+	}
+}
+
+func TestDelivererRetriesTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	delivery := addDeliveries(t, store, endpoint, 1)[0]
+
+	cfg := testConfig
+	cfg.Timeout = 50 * time.Millisecond
+	deliverDue(t, newDeliverer(store, cfg), store)
+
+	got := store.delivery(delivery.ID)
+	if got.Status != domain.WebhookDeliveryPending || got.Attempts != 1 {
+		t.Fatalf("status = %s, attempts = %d; want pending after 1 attempt", got.Status, got.Attempts)
+	}
+	if got.ResponseStatus != 0 || got.LastError == "" {
+		t.Errorf("response status = %d, last error = %q; want a timeout", got.ResponseStatus, got.LastError)
+	}
+	if !got.NextAttemptAt.After(time.Now().Add(cfg.RetryBaseDelay - time.Minute)) {
+		t.Errorf("next attempt at %s, want after the backoff", got.NextAttemptAt)
+	}
+}
+
+func TestDelivererDisablesFailingEndpoint(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	deliveries := addDeliveries(t, store, endpoint, 5)
+
+	cfg := testConfig
+	cfg.DisableAfter = 3
+	deliverDue(t, newDeliverer(store, cfg), store)
+
+	if n := calls.Load(); n != int32(cfg.DisableAfter) {
+		t.Errorf("endpoint called %d times, want %d", n, cfg.DisableAfter)
+	}
+
+	got := store.endpoint(endpoint.ID)
+	if got.IsActive || got.DisabledAt == nil || got.ConsecutiveFailures != cfg.DisableAfter {
+		t.Errorf("endpoint active = %t, disabled at = %v, failures = %d; want disabled after %d", got.IsActive, got.DisabledAt, got.ConsecutiveFailures, cfg.DisableAfter)
+	}
+
+	// Deliveries after the one that disabled the endpoint wait for it.
+	for _, delivery := range deliveries[cfg.DisableAfter:] {
+		if d := store.delivery(delivery.ID); d.Status != domain.WebhookDeliveryPending || d.Attempts != 0 {
+			t.Errorf("delivery %s: status = %s, attempts = %d; want pending and unsent", d.EventID, d.Status, d.Attempts)
+		}
+	}
+}
+
+func TestDelivererSuccessResetsFailureStreak(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	addDeliveries(t, store, endpoint, 2)
+
+	cfg := testConfig
+	cfg.DisableAfter = 3
+	deliverer := newDeliverer(store, cfg)
+	deliverDue(t, deliverer, store)
+
+	if got := store.endpoint(endpoint.ID); got.ConsecutiveFailures != 2 || !got.IsActive {
+		t.Fatalf("failures = %d, active = %t; want 2 and active", got.ConsecutiveFailures, got.IsActive)
+	}
+
+	fail.Store(false)
+	addDeliveries(t, store, endpoint, 1)
+	deliverDue(t, deliverer, store)
+
+	if got := store.endpoint(endpoint.ID); got.ConsecutiveFailures != 0 || !got.IsActive {
+		t.Errorf("failures = %d, active = %t; want 0 and active", got.ConsecutiveFailures, got.IsActive)
+	}
+}
+
+func TestRedeliverSendsCopyOfDelivery(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		deliveryIDs []string
+		bodies      []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		deliveryIDs = append(deliveryIDs, r.Header.Get(webhook.DeliveryHeader))
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	store := newMemoryWebhooks()
+	endpoint := addEndpoint(t, store, server.URL)
+	original := addDeliveries(t, store, endpoint, 1)[0]
+	original.Status = domain.WebhookDeliveryFailed
+	original.Attempts = testConfig.MaxAttempts
+	if err := store.deliveryRepo().Update(context.Background(), &original); err != nil {
+		t.Fatal(err)
+	}
+
+	webhooks := service.NewWebhookService(store.endpointRepo(), store.deliveryRepo())
+	redelivery, err := webhooks.Redeliver(context.Background(), endpoint.ID, original.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+
+	deliverDue(t, newDeliverer(store, testConfig), store)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deliveryIDs) != 1 || deliveryIDs[0] != redelivery.ID || bodies[0] != original.Payload {
+		t.Fatalf("endpoint received deliveries %v with bodies %v, want %s with %s", deliveryIDs, bodies, redelivery.ID, original.Payload)
+	}
+
+	got := store.delivery(redelivery.ID)
+	if got.Status != domain.WebhookDeliverySucceeded || got.RedeliveryOf == nil || *got.RedeliveryOf != original.ID {
+		t.Errorf("redelivery status = %s, redelivery of = %v; want succeeded copy of %s", got.Status, got.RedeliveryOf, original.ID)
+	}
+	if got := store.delivery(original.ID); got.Status != domain.WebhookDeliveryFailed || got.Attempts != original.Attempts {
+		t.Errorf("original status = %s, attempts = %d; want it left as it was", got.Status, got.Attempts)
+	}
+
+	if _, err := webhooks.Redeliver(context.Background(), "other-endpoint", original.ID); err != domain.ErrWebhookDeliveryNotFound {
+		t.Errorf("Redeliver for another endpoint = %v, want %v", err, domain.ErrWebhookDeliveryNotFound)
+	}
+}
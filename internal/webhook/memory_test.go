@@ -0,0 +1,212 @@
+package webhook_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/affandisy/goshop/internal/domain"
+	"github.com/affandisy/goshop/internal/domain/dto"
+	"github.com/google/uuid"
+)
+
+// memoryWebhooks keeps endpoints and deliveries in memory for the
+// deliverer. Reads return copies, as the database would.
+type memoryWebhooks struct {
+	mu         sync.Mutex
+	endpoints  map[string]*domain.WebhookEndpoint
+	deliveries []*domain.WebhookDelivery
+
+	// idle receives when FetchDue finds nothing due.
+	idle chan struct{}
+}
+
+func newMemoryWebhooks() *memoryWebhooks {
+	return &memoryWebhooks{
+		endpoints: make(map[string]*domain.WebhookEndpoint),
+		idle:      make(chan struct{}, 1),
+	}
+}
+
+func (m *memoryWebhooks) endpointRepo() *memoryEndpoints  { return &memoryEndpoints{m} }
+func (m *memoryWebhooks) deliveryRepo() *memoryDeliveries { return &memoryDeliveries{m} }
+
+func (m *memoryWebhooks) endpoint(id string) domain.WebhookEndpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.endpoints[id]
+}
+
+func (m *memoryWebhooks) delivery(id string) domain.WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			return *d
+		}
+	}
+	return domain.WebhookDelivery{}
+}
+
+type memoryEndpoints struct{ *memoryWebhooks }
+
+func (m *memoryEndpoints) Create(_ context.Context, endpoint *domain.WebhookEndpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if endpoint.ID == "" {
+		endpoint.ID = uuid.New().String()
+	}
+	e := *endpoint
+	m.endpoints[e.ID] = &e
+	return nil
+}
+
+func (m *memoryEndpoints) GetByID(_ context.Context, id string) (*domain.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.endpoints[id]
+	if !ok {
+		return nil, domain.ErrWebhookEndpointNotFound
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (m *memoryEndpoints) List(context.Context) ([]domain.WebhookEndpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var endpoints []domain.WebhookEndpoint
+	for _, e := range m.endpoints {
+		endpoints = append(endpoints, *e)
+	}
+	return endpoints, nil
+}
+
+func (m *memoryEndpoints) ListActive(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	all, _ := m.List(ctx)
+	var endpoints []domain.WebhookEndpoint
+	for _, e := range all {
+		if e.IsActive {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints, nil
+}
+
+func (m *memoryEndpoints) Update(_ context.Context, endpoint *domain.WebhookEndpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := *endpoint
+	m.endpoints[e.ID] = &e
+	return nil
+}
+
+func (m *memoryEndpoints) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.endpoints, id)
+	return nil
+}
+
+func (m *memoryEndpoints) RecordSuccess(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.endpoints[id]; ok {
+		e.ConsecutiveFailures = 0
+	}
+	return nil
+}
+
+func (m *memoryEndpoints) RecordFailure(_ context.Context, id string, disableAfter int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.endpoints[id]
+	if !ok {
+		return false, nil
+	}
+	e.ConsecutiveFailures++
+	if e.IsActive && e.ConsecutiveFailures >= disableAfter {
+		now := time.Now()
+		e.IsActive = false
+		e.DisabledAt = &now
+		return true, nil
+	}
+	return false, nil
+}
+
+type memoryDeliveries struct{ *memoryWebhooks }
+
+func (m *memoryDeliveries) CreateBatch(_ context.Context, deliveries []domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range deliveries {
+		if deliveries[i].ID == "" {
+			deliveries[i].ID = uuid.New().String()
+		}
+		d := deliveries[i]
+		m.deliveries = append(m.deliveries, &d)
+	}
+	return nil
+}
+
+func (m *memoryDeliveries) GetByID(_ context.Context, id string) (*domain.WebhookDelivery, error) {
+	d := m.delivery(id)
+	if d.ID == "" {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	return &d, nil
+}
+
+func (m *memoryDeliveries) ListByEndpoint(_ context.Context, endpointID string, _ dto.WebhookDeliveryQuery) ([]domain.WebhookDelivery, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deliveries []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.EndpointID == endpointID {
+			deliveries = append(deliveries, *d)
+		}
+	}
+	return deliveries, int64(len(deliveries)), nil
+}
+
+func (m *memoryDeliveries) FetchDue(_ context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deliveries []domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		e, ok := m.endpoints[d.EndpointID]
+		if !ok || !e.IsActive || d.Status != domain.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if len(deliveries) == limit {
+			break
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if len(deliveries) == 0 {
+		select {
+		case m.idle <- struct{}{}:
+		default:
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *memoryDeliveries) Update(_ context.Context, delivery *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, d := range m.deliveries {
+		if d.ID == delivery.ID {
+			copied := *delivery
+			m.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+// noTransactor runs fn directly; the memory store needs no transactions.
+type noTransactor struct{}
+
+func (noTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
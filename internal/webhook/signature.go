@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-GoShop-Timestamp"
+	SignatureHeader = "X-GoShop-Signature"
+	EventHeader     = "X-GoShop-Event"
+	DeliveryHeader  = "X-GoShop-Delivery"
+)
+
+// Sign returns "v1=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>".
+// Receivers should recompute it, compare in constant time and reject stale
+// timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetSignatureHeaders sets the timestamp and signature headers on req.
+func SetSignatureHeaders(req *http.Request, secret string, body []byte, now time.Time) {
+	ts := now.Unix()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, ts, body))
+}
@@ -0,0 +1,42 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/internal/webhook"
+)
+
+func TestSign(t *testing.T) {
+	got := webhook.Sign(testSecret, 1700000000, []byte(`{"id":"evt_1"}`))
+	want := "v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	for name, other := range map[string]string{
+		"secret":    webhook.Sign("other", 1700000000, []byte(`{"id":"evt_1"}`)),
+		"timestamp": webhook.Sign(testSecret, 1700000001, []byte(`{"id":"evt_1"}`)),
+		"body":      webhook.Sign(testSecret, 1700000000, []byte(`{"id":"evt_2"}`)),
+	} {
+		if other == want {
+			t.Errorf("signature does not change with the %s", name)
+		}
+	}
+}
+
+func TestSetSignatureHeaders(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	webhook.SetSignatureHeaders(req, testSecret, body, time.Unix(1700000000, 0))
+
+	if got := req.Header.Get(webhook.TimestampHeader); got != "1700000000" {
+		t.Errorf("%s = %q, want 1700000000", webhook.TimestampHeader, got)
+	}
+	if got, want := req.Header.Get(webhook.SignatureHeader), webhook.Sign(testSecret, 1700000000, body); got != want {
+		t.Errorf("%s = %q, want %q", webhook.SignatureHeader, got, want)
+	}
+}
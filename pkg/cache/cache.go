@@ -3,8 +3,10 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
+	"github.com/affandisy/goshop/pkg/metrics"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -39,9 +41,16 @@ func (s *cacheService) Set(ctx context.Context, key string, value interface{}, e
 func (s *cacheService) Get(ctx context.Context, key string, dest interface{}) error {
 	val, err := s.client.Get(ctx, key).Result()
 	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			metrics.CacheRequestsTotal.WithLabelValues("miss").Inc()
+		} else {
+			metrics.CacheRequestsTotal.WithLabelValues("error").Inc()
+		}
 		return err
 	}
 
+	metrics.CacheRequestsTotal.WithLabelValues("hit").Inc()
+
 	return json.Unmarshal([]byte(val), dest)
 }
 
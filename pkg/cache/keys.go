@@ -34,11 +34,11 @@ func ProductsKey(page, limit int, filters map[string]interface{}) string {
 	if categoryID, ok := filters["category_id"].(string); ok && categoryID != "" {
 		key += fmt.Sprintf(":cat:%s", categoryID)
 	}
-	if minPrice, ok := filters["min_price"].(float64); ok && minPrice > 0 {
-		key += fmt.Sprintf(":minp:%.0f", minPrice)
+	if minPrice, ok := filters["min_price"].(int64); ok && minPrice > 0 {
+		key += fmt.Sprintf(":minp:%d", minPrice)
 	}
-	if maxPrice, ok := filters["max_price"].(float64); ok && maxPrice > 0 {
-		key += fmt.Sprintf(":maxp:%.0f", maxPrice)
+	if maxPrice, ok := filters["max_price"].(int64); ok && maxPrice > 0 {
+		key += fmt.Sprintf(":maxp:%d", maxPrice)
 	}
 
 	return key
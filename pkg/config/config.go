@@ -4,22 +4,155 @@ import (
 	"log"
 	"os"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Environment         string `yaml:"environment"`
-	HTTPPort            string `yaml:"http_port"`
-	GRPCPort            string `yaml:"grpc_port"`
-	AuthSecret          string `yaml:"auth_secret"`
-	DatabaseURI         string `yaml:"database_uri"`
-	RedisURI            string `yaml:"redis_uri"`
-	RedisPassword       string `yaml:"redis_password"`
-	RedisDB             int    `yaml:"redis_db"`
-	MidtransServerKey   string `yaml:"midtrans_server_key"`
-	MidtransClientKey   string `yaml:"midtrans_client_key"`
-	MidtransEnvironment string `yaml:"midtrans_environment"`
+	Environment           string             `yaml:"environment"`
+	HTTPPort              string             `yaml:"http_port"`
+	GRPCPort              string             `yaml:"grpc_port"`
+	HTTPReadTimeout       time.Duration      `yaml:"http_read_timeout"`
+	HTTPWriteTimeout      time.Duration      `yaml:"http_write_timeout"`
+	HTTPIdleTimeout       time.Duration      `yaml:"http_idle_timeout"`
+	ShutdownTimeout       time.Duration      `yaml:"shutdown_timeout"`
+	RequestTimeout        time.Duration      `yaml:"request_timeout"` // deadline applied to each request's context
+	TrustedProxies        []string           `yaml:"trusted_proxies"` // CIDRs or IPs allowed to set X-Forwarded-For; empty trusts none
+	AuthSecret            string             `yaml:"auth_secret"`
+	DatabaseURI           string             `yaml:"database_uri"`
+	RedisURI              string             `yaml:"redis_uri"`
+	RedisPassword         string             `yaml:"redis_password"`
+	RedisDB               int                `yaml:"redis_db"`
+	MidtransServerKey     string             `yaml:"midtrans_server_key"`
+	MidtransClientKey     string             `yaml:"midtrans_client_key"`
+	MidtransEnvironment   string             `yaml:"midtrans_environment"`
+	TracingExporter       string             `yaml:"tracing_exporter"` // none, stdout, otlp
+	OTLPEndpoint          string             `yaml:"otlp_endpoint"`
+	TracingSampleRatio    float64            `yaml:"tracing_sample_ratio"`
+	RateLimit             RateLimitConfig    `yaml:"rate_limit"`
+	Idempotency           IdempotencyConfig  `yaml:"idempotency"`
+	Lockout               LockoutConfig      `yaml:"lockout"`
+	AppBaseURL            string             `yaml:"app_base_url"` // storefront URL used in email links
+	Mailer                MailerConfig       `yaml:"mailer"`
+	VerificationTokenTTL  time.Duration      `yaml:"verification_token_ttl"`
+	PasswordResetTokenTTL time.Duration      `yaml:"password_reset_token_ttl"`
+	GuestOrderTokenTTL    time.Duration      `yaml:"guest_order_token_ttl"`
+	Notification          NotificationConfig `yaml:"notification"`
+	Outbox                OutboxConfig       `yaml:"outbox"`
+	Webhooks              WebhooksConfig     `yaml:"webhooks"`
+	Wishlist              WishlistConfig     `yaml:"wishlist"`
+	Inventory             InventoryConfig    `yaml:"inventory"`
+	Currency              CurrencyConfig     `yaml:"currency"`
+	I18n                  I18nConfig         `yaml:"i18n"`
+	OrderNumber           OrderNumberConfig  `yaml:"order_number"`
+}
+
+type OrderNumberConfig struct {
+	Format         string `yaml:"format"`          // {date}, {seq} and {check} are replaced
+	SequenceDigits int    `yaml:"sequence_digits"` // {seq} is zero-padded to this many digits
+}
+
+type I18nConfig struct {
+	DefaultLocale string `yaml:"default_locale"` // used when the request asks for no supported locale
+}
+
+type CurrencyConfig struct {
+	Rounding           string           `yaml:"rounding"`            // half_up, half_even, up or down
+	RoundingIncrements map[string]int64 `yaml:"rounding_increments"` // smallest step per currency in minor units
+}
+
+type InventoryConfig struct {
+	LowStockCheckInterval time.Duration `yaml:"low_stock_check_interval"`
+	LowStockBatchSize     int           `yaml:"low_stock_batch_size"`
+	AlertRecipients       []string      `yaml:"alert_recipients"`    // emails; empty writes alerts to the log
+	AllocationStrategy    string        `yaml:"allocation_strategy"` // priority, nearest or fewest_splits
+	// Stock is held for unpaid orders for ReservationTTL, then released and
+	// the order cancelled.
+	ReservationTTL            time.Duration `yaml:"reservation_ttl"`
+	ReservationSweepInterval  time.Duration `yaml:"reservation_sweep_interval"`
+	ReservationSweepBatchSize int           `yaml:"reservation_sweep_batch_size"`
+}
+
+type WishlistConfig struct {
+	AlertLimit          int           `yaml:"alert_limit"` // alerts per user per alert_window
+	AlertWindow         time.Duration `yaml:"alert_window"`
+	BackInStockCooldown time.Duration `yaml:"back_in_stock_cooldown"`
+	AlertSweepInterval  time.Duration `yaml:"alert_sweep_interval"` // how often deferred alerts are sent
+	AlertSweepBatchSize int           `yaml:"alert_sweep_batch_size"`
+}
+
+type WebhooksConfig struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay"`
+	Timeout        time.Duration `yaml:"timeout"`
+	DisableAfter   int           `yaml:"disable_after"` // consecutive failed attempts
+}
+
+type OutboxConfig struct {
+	PollInterval      time.Duration `yaml:"poll_interval"`
+	BatchSize         int           `yaml:"batch_size"`
+	MaxAttempts       int           `yaml:"max_attempts"`
+	RetryBaseDelay    time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay     time.Duration `yaml:"retry_max_delay"`
+	LeaseDuration     time.Duration `yaml:"lease_duration"` // how long a claimed batch is hidden from other relays
+	Retention         time.Duration `yaml:"retention"`
+	RedisStream       string        `yaml:"redis_stream"` // empty disables the Redis Streams sink
+	RedisStreamMaxLen int64         `yaml:"redis_stream_max_len"`
+}
+
+type NotificationConfig struct {
+	Channels       []string      `yaml:"channels"` // email, webhook, log
+	DefaultLocale  string        `yaml:"default_locale"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	WebhookSecret  string        `yaml:"webhook_secret"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+	QueueSize      int           `yaml:"queue_size"`
+	Workers        int           `yaml:"workers"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay"`
+}
+
+type MailerConfig struct {
+	Driver       string `yaml:"driver"` // smtp, file, log
+	From         string `yaml:"from"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	FileDir      string `yaml:"file_dir"`
+}
+
+type LockoutConfig struct {
+	MaxFailedAttempts int           `yaml:"max_failed_attempts"`
+	BaseDuration      time.Duration `yaml:"base_duration"`
+	MaxDuration       time.Duration `yaml:"max_duration"`
+	IPMaxFailures     int           `yaml:"ip_max_failures"`
+	IPWindow          time.Duration `yaml:"ip_window"`
+}
+
+type RateLimitRule struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+type RateLimitConfig struct {
+	Backend             string        `yaml:"backend"` // redis, memory, none
+	Login               RateLimitRule `yaml:"login"`
+	LoginPerEmail       RateLimitRule `yaml:"login_per_email"`
+	Register            RateLimitRule `yaml:"register"`
+	GuestCheckout       RateLimitRule `yaml:"guest_checkout"`
+	PaymentNotification RateLimitRule `yaml:"payment_notification"`
+}
+
+type IdempotencyConfig struct {
+	Backend     string        `yaml:"backend"`      // redis, memory, none
+	TTL         time.Duration `yaml:"ttl"`          // how long responses are replayed
+	LockTimeout time.Duration `yaml:"lock_timeout"` // how long an unfinished request holds its key
 }
 
 var (
@@ -39,6 +172,8 @@ func Load(configPath string) *Config {
 			log.Fatalf("Failed to parse config file: %v", err)
 		}
 
+		config.setDefaults()
+
 		log.Println("Configuration loaded successfully")
 	})
 
@@ -52,3 +187,211 @@ func GetConfig() *Config {
 
 	return config
 }
+
+// setDefaults fills in values that are optional in config.yaml.
+func (c *Config) setDefaults() {
+	if c.HTTPReadTimeout == 0 {
+		c.HTTPReadTimeout = 15 * time.Second
+	}
+	if c.HTTPWriteTimeout == 0 {
+		c.HTTPWriteTimeout = 30 * time.Second
+	}
+	if c.HTTPIdleTimeout == 0 {
+		c.HTTPIdleTimeout = 60 * time.Second
+	}
+	if c.RequestTimeout == 0 {
+		c.RequestTimeout = 10 * time.Second
+	}
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 20 * time.Second
+	}
+	if c.TracingExporter == "" {
+		c.TracingExporter = "none"
+	}
+	if c.TracingSampleRatio == 0 {
+		c.TracingSampleRatio = 1
+	}
+
+	if c.RateLimit.Backend == "" {
+		c.RateLimit.Backend = "redis"
+	}
+	c.RateLimit.Login.setDefaults(10, time.Minute)
+	c.RateLimit.LoginPerEmail.setDefaults(5, 15*time.Minute)
+	c.RateLimit.Register.setDefaults(5, time.Hour)
+	c.RateLimit.GuestCheckout.setDefaults(10, time.Hour)
+	c.RateLimit.PaymentNotification.setDefaults(300, time.Minute)
+
+	if c.Idempotency.Backend == "" {
+		c.Idempotency.Backend = "redis"
+	}
+	if c.Idempotency.TTL == 0 {
+		c.Idempotency.TTL = 24 * time.Hour
+	}
+	if c.Idempotency.LockTimeout == 0 {
+		c.Idempotency.LockTimeout = time.Minute
+	}
+
+	if c.Lockout.MaxFailedAttempts == 0 {
+		c.Lockout.MaxFailedAttempts = 5
+	}
+	if c.Lockout.BaseDuration == 0 {
+		c.Lockout.BaseDuration = time.Minute
+	}
+	if c.Lockout.MaxDuration == 0 {
+		c.Lockout.MaxDuration = 24 * time.Hour
+	}
+	if c.Lockout.IPMaxFailures == 0 {
+		c.Lockout.IPMaxFailures = 50
+	}
+	if c.Lockout.IPWindow == 0 {
+		c.Lockout.IPWindow = time.Hour
+	}
+
+	if c.AppBaseURL == "" {
+		c.AppBaseURL = "http://localhost:3000"
+	}
+	if c.Mailer.Driver == "" {
+		c.Mailer.Driver = "log"
+	}
+	if c.Mailer.From == "" {
+		c.Mailer.From = "GoShop <no-reply@goshop.local>"
+	}
+	if c.Mailer.SMTPPort == 0 {
+		c.Mailer.SMTPPort = 587
+	}
+	if c.Mailer.FileDir == "" {
+		c.Mailer.FileDir = "tmp/mail"
+	}
+	if c.VerificationTokenTTL == 0 {
+		c.VerificationTokenTTL = 24 * time.Hour
+	}
+	if c.PasswordResetTokenTTL == 0 {
+		c.PasswordResetTokenTTL = time.Hour
+	}
+	if c.GuestOrderTokenTTL == 0 {
+		c.GuestOrderTokenTTL = 90 * 24 * time.Hour
+	}
+	if len(c.Notification.Channels) == 0 {
+		c.Notification.Channels = []string{"log"}
+	}
+	if c.Notification.DefaultLocale == "" {
+		c.Notification.DefaultLocale = "id"
+	}
+	if c.Notification.WebhookTimeout == 0 {
+		c.Notification.WebhookTimeout = 10 * time.Second
+	}
+	if c.Notification.QueueSize == 0 {
+		c.Notification.QueueSize = 1000
+	}
+	if c.Notification.Workers == 0 {
+		c.Notification.Workers = 2
+	}
+	if c.Notification.MaxAttempts == 0 {
+		c.Notification.MaxAttempts = 5
+	}
+	if c.Notification.RetryBaseDelay == 0 {
+		c.Notification.RetryBaseDelay = time.Second
+	}
+	if c.Notification.RetryMaxDelay == 0 {
+		c.Notification.RetryMaxDelay = time.Minute
+	}
+	if c.Outbox.PollInterval == 0 {
+		c.Outbox.PollInterval = time.Second
+	}
+	if c.Outbox.BatchSize == 0 {
+		c.Outbox.BatchSize = 100
+	}
+	if c.Outbox.MaxAttempts == 0 {
+		c.Outbox.MaxAttempts = 10
+	}
+	if c.Outbox.RetryBaseDelay == 0 {
+		c.Outbox.RetryBaseDelay = 5 * time.Second
+	}
+	if c.Outbox.RetryMaxDelay == 0 {
+		c.Outbox.RetryMaxDelay = 10 * time.Minute
+	}
+	if c.Outbox.LeaseDuration == 0 {
+		c.Outbox.LeaseDuration = 5 * time.Minute
+	}
+	if c.Outbox.Retention == 0 {
+		c.Outbox.Retention = 7 * 24 * time.Hour
+	}
+	if c.Outbox.RedisStreamMaxLen == 0 {
+		c.Outbox.RedisStreamMaxLen = 100000
+	}
+	if c.Webhooks.PollInterval == 0 {
+		c.Webhooks.PollInterval = 2 * time.Second
+	}
+	if c.Webhooks.BatchSize == 0 {
+		c.Webhooks.BatchSize = 50
+	}
+	if c.Webhooks.MaxAttempts == 0 {
+		c.Webhooks.MaxAttempts = 8
+	}
+	if c.Webhooks.RetryBaseDelay == 0 {
+		c.Webhooks.RetryBaseDelay = 30 * time.Second
+	}
+	if c.Webhooks.RetryMaxDelay == 0 {
+		c.Webhooks.RetryMaxDelay = 6 * time.Hour
+	}
+	if c.Webhooks.Timeout == 0 {
+		c.Webhooks.Timeout = 10 * time.Second
+	}
+	if c.Webhooks.DisableAfter == 0 {
+		c.Webhooks.DisableAfter = 20
+	}
+	if c.Wishlist.AlertLimit == 0 {
+		c.Wishlist.AlertLimit = 5
+	}
+	if c.Wishlist.AlertWindow == 0 {
+		c.Wishlist.AlertWindow = 24 * time.Hour
+	}
+	if c.Wishlist.BackInStockCooldown == 0 {
+		c.Wishlist.BackInStockCooldown = 24 * time.Hour
+	}
+	if c.Wishlist.AlertSweepInterval == 0 {
+		c.Wishlist.AlertSweepInterval = time.Minute
+	}
+	if c.Wishlist.AlertSweepBatchSize == 0 {
+		c.Wishlist.AlertSweepBatchSize = 100
+	}
+	if c.Inventory.LowStockCheckInterval == 0 {
+		c.Inventory.LowStockCheckInterval = 5 * time.Minute
+	}
+	if c.Inventory.LowStockBatchSize == 0 {
+		c.Inventory.LowStockBatchSize = 100
+	}
+	if c.Inventory.AllocationStrategy == "" {
+		c.Inventory.AllocationStrategy = "priority"
+	}
+	if c.Inventory.ReservationTTL == 0 {
+		c.Inventory.ReservationTTL = 30 * time.Minute
+	}
+	if c.Inventory.ReservationSweepInterval == 0 {
+		c.Inventory.ReservationSweepInterval = time.Minute
+	}
+	if c.Inventory.ReservationSweepBatchSize == 0 {
+		c.Inventory.ReservationSweepBatchSize = 100
+	}
+	if c.Currency.Rounding == "" {
+		c.Currency.Rounding = "half_up"
+	}
+	if c.I18n.DefaultLocale == "" {
+		c.I18n.DefaultLocale = "en"
+	}
+	if c.OrderNumber.Format == "" {
+		c.OrderNumber.Format = "ORD-{date}-{seq}{check}"
+	}
+	if c.OrderNumber.SequenceDigits == 0 {
+		c.OrderNumber.SequenceDigits = 6
+	}
+}
+
+func (r *RateLimitRule) setDefaults(limit int, window time.Duration) {
+	if r.Limit == 0 {
+		r.Limit = limit
+	}
+	if r.Window == 0 {
+		r.Window = window
+	}
+}
@@ -0,0 +1,90 @@
+// Package databasetest gives tests a migrated Postgres schema of their own.
+package databasetest
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/affandisy/goshop/pkg/database"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// URIEnv names the variable holding the Postgres URI tests connect to.
+// Tests that need a database are skipped when it is not set.
+const URIEnv = "GOSHOP_TEST_DATABASE_URI"
+
+// Open creates and migrates a fresh schema in the test database, points
+// database.DB at it and drops it when the test ends.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	uri := os.Getenv(URIEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", URIEnv)
+	}
+
+	config := &gorm.Config{
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		QueryFields: true,
+		Logger:      logger.Discard,
+	}
+
+	admin, err := gorm.Open(postgres.Open(uri), config)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+
+	schema := "goshop_test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(uri, schema)), config)
+	if err != nil {
+		t.Fatalf("connect to test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("drop schema: %v", err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	database.DB = db
+	if err := database.AutoMigrate(); err != nil {
+		t.Fatalf("migrate test schema: %v", err)
+	}
+
+	return db
+}
+
+// withSearchPath sets the schema new connections use, for both URL and
+// keyword/value connection strings.
+func withSearchPath(uri, schema string) string {
+	if !strings.Contains(uri, "://") {
+		return uri + " search_path=" + schema
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
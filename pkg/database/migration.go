@@ -1,7 +1,9 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"math"
 
 	"github.com/affandisy/goshop/internal/domain"
 	"github.com/affandisy/goshop/pkg/utils"
@@ -10,23 +12,148 @@ import (
 func AutoMigrate() error {
 	log.Println("Running auto migration...")
 
+	// Accounts created before email verification existed are treated as
+	// verified, so they are not locked out of checkout.
+	backfillEmailVerified := !DB.Migrator().HasColumn(&domain.User{}, "email_verified_at")
+	// Items ordered before price rules existed were sold at list price.
+	backfillListPrice := !DB.Migrator().HasColumn(&domain.OrderItem{}, "list_price")
+
+	if err := convertMoneyColumns(); err != nil {
+		return err
+	}
+
 	err := DB.AutoMigrate(
 		&domain.User{},
+		&domain.LoginAttempt{},
+		&domain.UserToken{},
 		&domain.Category{},
 		&domain.Product{},
 		&domain.Order{},
 		&domain.OrderItem{},
 		&domain.Payment{},
+		&domain.Review{},
+		&domain.WishlistItem{},
+		&domain.OutboxEvent{},
+		&domain.ProcessedEvent{},
+		&domain.WebhookEndpoint{},
+		&domain.WebhookDelivery{},
+		&domain.Warehouse{},
+		&domain.InventoryLevel{},
+		&domain.StockTransfer{},
+		&domain.OrderAllocation{},
+		&domain.StockReservation{},
+		&domain.PriceRule{},
+		&domain.PriceHistory{},
+		&domain.ExchangeRate{},
+		&domain.ProductTranslation{},
+		&domain.CategoryTranslation{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	if backfillEmailVerified {
+		if err := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			return err
+		}
+	}
+
+	if backfillListPrice {
+		if err := DB.Exec("UPDATE order_items SET list_price = price").Error; err != nil {
+			return err
+		}
+	}
+
+	if err := stockDefaultWarehouse(); err != nil {
+		return err
+	}
+
+	if err := DB.Exec(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s", domain.OrderNumberSequence)).Error; err != nil {
+		return err
+	}
+
 	log.Println("Auto migration completed successfully")
 	return nil
 }
 
+// moneyColumns held decimal amounts before domain.Money.
+var moneyColumns = []struct{ table, column string }{
+	{"products", "price"},
+	{"orders", "total_amount"},
+	{"order_items", "price"},
+	{"order_items", "list_price"},
+	{"payments", "amount"},
+	{"wishlist_items", "price_snapshot"},
+	{"wishlist_items", "last_alerted_price"},
+	{"price_rules", "price"},
+	{"price_histories", "previous_price"},
+	{"price_histories", "price"},
+}
+
+// convertMoneyColumns turns decimal amounts into integer minor units of the
+// base currency, rounding anything smaller. Columns that are no longer
+// decimal are left alone.
+func convertMoneyColumns() error {
+	factor := int64(math.Pow10(domain.BaseCurrency.Digits()))
+
+	for _, c := range moneyColumns {
+		var dataType string
+		err := DB.Raw(`SELECT data_type FROM information_schema.columns
+			WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?`, c.table, c.column).
+			Scan(&dataType).Error
+		if err != nil {
+			return err
+		}
+		if dataType != "numeric" {
+			continue
+		}
+
+		err = DB.Exec(fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN %q TYPE bigint USING ROUND(%q * %d)::bigint`,
+			c.table, c.column, c.column, factor)).Error
+		if err != nil {
+			return err
+		}
+		log.Printf("Converted %s.%s to minor units", c.table, c.column)
+	}
+
+	return nil
+}
+
+// stockDefaultWarehouse creates the MAIN warehouse when there is none and
+// moves stock of products without inventory levels into it, so stock held
+// before warehouses existed stays sellable.
+func stockDefaultWarehouse() error {
+	var warehouse domain.Warehouse
+	err := DB.Where("is_active = ?", true).Order("priority ASC").Order("created_at ASC").
+		Limit(1).Find(&warehouse).Error
+	if err != nil {
+		return err
+	}
+
+	if warehouse.ID == "" {
+		warehouse = domain.Warehouse{Code: "MAIN", Name: "Main Warehouse", IsActive: true}
+		if err := DB.Create(&warehouse).Error; err != nil {
+			return err
+		}
+		log.Printf("Default warehouse created: %s", warehouse.Code)
+	}
+
+	result := DB.Exec(`INSERT INTO inventory_levels (id, created_at, updated_at, product_id, warehouse_id, quantity)
+		SELECT gen_random_uuid(), NOW(), NOW(), products.id, ?, products.stock FROM products
+		WHERE products.stock > 0 AND products.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM inventory_levels WHERE inventory_levels.product_id = products.id)`, warehouse.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Stock of %d products moved to warehouse %s", result.RowsAffected, warehouse.Code)
+	}
+
+	return nil
+}
+
 func SeedData() error {
 	log.Println("Seeding initial data...")
 
@@ -42,6 +169,7 @@ func SeedData() error {
 			Role:     "admin",
 			IsActive: true,
 		}
+		admin.MarkEmailVerified()
 
 		hashedPassword, err := utils.HashPassword(admin.Password)
 		if err != nil {
@@ -54,7 +182,7 @@ func SeedData() error {
 			return err
 		}
 
-		log.Println("Default admin created: %w, %w", admin.Email, admin.Password)
+		log.Printf("Default admin created: %s", admin.Email)
 	}
 
 	DB.Model(&domain.Category{}).Count(&count)
@@ -82,7 +210,7 @@ func SeedData() error {
 			{
 				Name:        "MacBook Pro 16 M3 Max",
 				Description: "Professional laptop with M3 Max chip, 36GB RAM, 1TB SSD",
-				Price:       49999000,
+				Price:       domain.IDR(49999000),
 				Stock:       10,
 				SKU:         "MBP-16-M3MAX-1TB",
 				CategoryID:  electronicsCategory.ID,
@@ -92,7 +220,7 @@ func SeedData() error {
 			{
 				Name:        "iPhone 15 Pro Max",
 				Description: "Latest iPhone with A17 Pro chip, titanium design, 256GB",
-				Price:       19999000,
+				Price:       domain.IDR(19999000),
 				Stock:       50,
 				SKU:         "IPH-15-PM-256-BLU",
 				CategoryID:  smartphonesCategory.ID,
@@ -102,7 +230,7 @@ func SeedData() error {
 			{
 				Name:        "Samsung Galaxy S24 Ultra",
 				Description: "Premium Android flagship with S Pen, 512GB storage",
-				Price:       18999000,
+				Price:       domain.IDR(18999000),
 				Stock:       30,
 				SKU:         "SAM-S24-ULTRA-512-BLK",
 				CategoryID:  smartphonesCategory.ID,
@@ -116,6 +244,10 @@ func SeedData() error {
 		}
 
 		log.Println("Default products created")
+
+		if err := stockDefaultWarehouse(); err != nil {
+			return err
+		}
 	}
 
 	log.Println("Seeding completed successfully")
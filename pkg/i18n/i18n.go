@@ -0,0 +1,143 @@
+// Package i18n resolves the locale of a request and translates API
+// messages with the bundles in locales/.
+//
+// Response messages are written in English in the code and are their own
+// keys, so the en bundle only holds keys that are not English text, such
+// as validation messages. A key missing from a bundle falls back to the
+// en bundle and then to the key itself.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	LocaleEN = "en"
+	LocaleID = "id"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var bundles = map[string]map[string]string{}
+
+func init() {
+	for _, locale := range []string{LocaleEN, LocaleID} {
+		data, err := localeFS.ReadFile("locales/" + locale + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: %v", err))
+		}
+
+		bundle := map[string]string{}
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			panic(fmt.Sprintf("i18n: locales/%s.json: %v", locale, err))
+		}
+		bundles[locale] = bundle
+	}
+}
+
+// Supported reports whether there is a bundle for locale.
+func Supported(locale string) bool {
+	_, ok := bundles[locale]
+	return ok
+}
+
+// T translates key into locale. Args fill in the message's fmt verbs.
+func T(locale, key string, args ...any) string {
+	msg, ok := bundles[locale][key]
+	if !ok {
+		msg, ok = bundles[LocaleEN][key]
+	}
+	if !ok {
+		msg = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Resolve picks the locale for a request: the lang query parameter, then
+// the Accept-Language languages by preference, then fallback. Regional tags
+// such as id-ID fall back to their language.
+func Resolve(lang, acceptLanguage, fallback string) string {
+	for _, tag := range append([]string{lang}, parseAcceptLanguage(acceptLanguage)...) {
+		if locale, ok := match(tag); ok {
+			return locale
+		}
+	}
+	return fallback
+}
+
+func match(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	for tag != "" {
+		if Supported(tag) {
+			return tag, true
+		}
+		i := strings.LastIndexAny(tag, "-_")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	return "", false
+}
+
+// parseAcceptLanguage returns the tags of an Accept-Language header from the
+// most to the least preferred, leaving out those with q=0.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
+
+type localeKey struct{}
+
+// WithLocale stores the request's locale in ctx.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored by WithLocale, or "" if there is
+// none.
+func FromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+const KeyPrefix = "idempotency:"
+
+// Record is what is kept for a key: the fingerprint of the request that
+// claimed it and, once that request has succeeded, its response.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Store keeps a Record per key.
+type Store interface {
+	// Claim stores a pending record for key unless there is one already, in
+	// which case that record is returned and claimed is false. The claim
+	// lapses after lockTTL so a request that never finishes does not hold
+	// the key for good.
+	Claim(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (record Record, claimed bool, err error)
+	// Save replaces the record for key with the finished one for ttl.
+	Save(ctx context.Context, key string, record Record, ttl time.Duration) error
+	// Release forgets key so the request can be sent again.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,69 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore is an in-process Store for tests and single instance
+// development setups. Records are lost on restart and not shared between
+// instances.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	now     func() time.Time
+}
+
+type memoryRecord struct {
+	record    Record
+	expiresAt time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		records: make(map[string]memoryRecord),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Claim(_ context.Context, key, fingerprint string, lockTTL time.Duration) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if existing, ok := s.records[key]; ok {
+		return existing.record, false, nil
+	}
+
+	record := Record{Fingerprint: fingerprint}
+	s.records[key] = memoryRecord{record: record, expiresAt: now.Add(lockTTL)}
+	return record, true, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = memoryRecord{record: record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops expired records. It is called with mu held.
+func (s *memoryStore) sweep(now time.Time) {
+	for key, r := range s.records {
+		if !now.Before(r.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
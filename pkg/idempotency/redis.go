@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// claimAttempts bounds retries when a record expires between SETNX and GET.
+const claimAttempts = 3
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) Store {
+	return &redisStore{client: client}
+}
+
+func (s *redisStore) Claim(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (Record, bool, error) {
+	pending, err := json.Marshal(Record{Fingerprint: fingerprint})
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	for i := 0; i < claimAttempts; i++ {
+		claimed, err := s.client.SetNX(ctx, KeyPrefix+key, pending, lockTTL).Result()
+		if err != nil {
+			return Record{}, false, err
+		}
+		if claimed {
+			return Record{Fingerprint: fingerprint}, true, nil
+		}
+
+		data, err := s.client.Get(ctx, KeyPrefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return Record{}, false, err
+		}
+
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return Record{}, false, err
+		}
+		return record, false, nil
+	}
+
+	return Record{}, false, errors.New("idempotency: key keeps expiring while being claimed")
+}
+
+func (s *redisStore) Save(ctx context.Context, key string, record Record, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, KeyPrefix+key, data, ttl).Err()
+}
+
+func (s *redisStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, KeyPrefix+key).Err()
+}
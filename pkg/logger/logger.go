@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values must never reach the logs.
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"snap_token":    true,
+	"access_token":  true,
+	"authorization": true,
+	"signature_key": true,
+	"server_key":    true,
+	"secret":        true,
+}
+
+// New builds the application logger. Production writes JSON, every other
+// environment writes human readable text.
+func New(environment string) *slog.Logger {
+	return NewWithWriter(os.Stdout, environment)
+}
+
+func NewWithWriter(w io.Writer, environment string) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       slog.LevelInfo,
+		ReplaceAttr: redact,
+	}
+
+	var h slog.Handler
+	if environment == "production" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		opts.Level = slog.LevelDebug
+		h = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(&contextHandler{Handler: h})
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// IsSensitive reports whether values stored under key should be redacted.
+func IsSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+type ctxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID from the context to every record, so
+// callers only need to use the *Context logging methods.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
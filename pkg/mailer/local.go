@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileMailer writes every message as an .eml file, so emails can be opened
+// in a mail client during local development.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(_ context.Context, msg Message) error {
+	body, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102_150405.000000"), recipient)
+
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
+
+// logMailer only logs the message. Bodies are logged in full because they
+// carry the verification and reset links developers need locally; never use
+// it in production.
+type logMailer struct {
+	logger *slog.Logger
+}
+
+func NewLogMailer(logger *slog.Logger) Mailer {
+	return &logMailer{logger: logger}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.InfoContext(ctx, "Email sent",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.TextBody),
+	)
+	return nil
+}
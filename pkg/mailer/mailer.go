@@ -0,0 +1,104 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// buildMIME renders msg as an RFC 5322 message with a multipart/alternative
+// body when both text and HTML parts are present.
+func buildMIME(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@goshop>\r\n", uuid.NewString())
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTMLBody == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		buf.WriteString(normalizeNewlines(msg.TextBody))
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=UTF-8", msg.TextBody},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	}
+
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(normalizeNewlines(p.body))); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func normalizeNewlines(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+type Config struct {
+	Driver  string
+	From    string
+	SMTP    SMTPConfig
+	FileDir string
+}
+
+// New returns the Mailer selected by cfg.Driver.
+func New(cfg Config, logger *slog.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case DriverSMTP:
+		cfg.SMTP.From = cfg.From
+		return NewSMTPMailer(cfg.SMTP), nil
+	case DriverFile:
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case DriverLog, "":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", cfg.Driver)
+	}
+}
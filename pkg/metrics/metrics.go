@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "goshop"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by route template and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "GORM query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	CacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups by result (hit, miss, error).",
+	}, []string{"result"})
+
+	OrdersCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Total number of orders created.",
+	})
+
+	PaymentsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Total number of settled payments by outcome (succeeded, failed).",
+	}, []string{"result"})
+
+	StockOutsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stock_outs_total",
+		Help:      "Number of times a product's stock reached zero.",
+	})
+
+	LowStockAlertsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "low_stock_alerts_total",
+		Help:      "Number of low-stock alerts raised.",
+	})
+
+	ReservationsExpiredTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_expired_total",
+		Help:      "Number of unpaid orders cancelled because their stock reservation expired.",
+	})
+
+	OutboxDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "deliveries_total",
+		Help:      "Outbox delivery attempts by event type and result (published, retry, failed).",
+	}, []string{"type", "result"})
+
+	WebhookDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Merchant webhook delivery attempts by result (succeeded, retry, failed).",
+	}, []string{"result"})
+)
+
+// Register adds every GoShop collector, plus the Go runtime and process
+// collectors, to the registry served by Handler.
+func Register() error {
+	cs := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		CacheRequestsTotal,
+		OrdersCreatedTotal,
+		PaymentsTotal,
+		StockOutsTotal,
+		LowStockAlertsTotal,
+		ReservationsExpiredTotal,
+		OutboxDeliveriesTotal,
+		WebhookDeliveriesTotal,
+	}
+
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
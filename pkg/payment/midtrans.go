@@ -1,21 +1,29 @@
 package payment
 
 import (
+	"context"
 	"crypto/sha512"
 	"encoding/hex"
-	"log"
+	"log/slog"
 
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/snap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/affandisy/goshop/pkg/payment")
+
 type MidtransClient struct {
 	snapClient  snap.Client
 	serverKey   string
 	environment midtrans.EnvironmentType
+	logger      *slog.Logger
 }
 
-func NewMidtransClient(serverKey, clientKey, environment string) *MidtransClient {
+func NewMidtransClient(serverKey, clientKey, environment string, logger *slog.Logger) *MidtransClient {
 	var env midtrans.EnvironmentType
 	if environment == "production" {
 		env = midtrans.Production
@@ -26,12 +34,13 @@ func NewMidtransClient(serverKey, clientKey, environment string) *MidtransClient
 	snapClient := snap.Client{}
 	snapClient.New(serverKey, env)
 
-	log.Printf("Midtrans initialized (Environment: %s)", environment)
+	logger.Info("Midtrans initialized", slog.String("environment", environment))
 
 	return &MidtransClient{
 		snapClient:  snapClient,
 		serverKey:   serverKey,
 		environment: env,
+		logger:      logger,
 	}
 }
 
@@ -51,7 +60,17 @@ type ItemDetail struct {
 	Quantity int32
 }
 
-func (m *MidtransClient) CreateSnapToken(req CreateSnapTokenRequest) (*snap.Response, error) {
+func (m *MidtransClient) CreateSnapToken(ctx context.Context, req CreateSnapTokenRequest) (*snap.Response, error) {
+	ctx, span := tracer.Start(ctx, "midtrans.CreateSnapToken",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("payment.gateway", "midtrans"),
+			attribute.String("payment.order_id", req.OrderID),
+			attribute.Int64("payment.gross_amount", req.GrossAmount),
+		),
+	)
+	defer span.End()
+
 	snapReq := &snap.Request{
 		TransactionDetails: midtrans.TransactionDetails{
 			OrderID:  req.OrderID,
@@ -79,11 +98,16 @@ func (m *MidtransClient) CreateSnapToken(req CreateSnapTokenRequest) (*snap.Resp
 
 	snapResp, err := m.snapClient.CreateTransaction(snapReq)
 	if err != nil {
-		log.Printf("Failed to create Snap token: %v", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.GetMessage())
+		m.logger.ErrorContext(ctx, "Failed to create Snap token",
+			slog.String("order_id", req.OrderID),
+			slog.Any("error", err),
+		)
 		return nil, err
 	}
 
-	log.Printf("Snap token created for order %s: %s", req.OrderID, snapResp.Token)
+	m.logger.InfoContext(ctx, "Snap token created", slog.String("order_id", req.OrderID))
 
 	return snapResp, nil
 }
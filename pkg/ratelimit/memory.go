@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory limiter drops keys whose window
+// has passed, so keys seen once don't stay in memory forever.
+const sweepInterval = time.Minute
+
+// memoryLimiter is an in-process Limiter for tests and single instance
+// development setups. State is lost on restart and not shared between
+// instances.
+type memoryLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	now       func() time.Time
+	nextSweep time.Time
+}
+
+type memoryEntry struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	entry := l.entries[key]
+	if entry == nil {
+		entry = &memoryEntry{}
+	}
+	entry.window = window
+	entry.hits = expire(entry.hits, now.Add(-window))
+
+	result := Result{Limit: limit}
+	if len(entry.hits) < limit {
+		entry.hits = append(entry.hits, now)
+		result.Allowed = true
+	}
+
+	result.Remaining = limit - len(entry.hits)
+	result.ResetAfter = window
+	if len(entry.hits) > 0 {
+		result.ResetAfter = entry.hits[0].Add(window).Sub(now)
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+
+	if len(entry.hits) == 0 {
+		delete(l.entries, key)
+	} else {
+		l.entries[key] = entry
+	}
+
+	return result, nil
+}
+
+// sweep drops the keys with no hits left in their window.
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+	l.nextSweep = now.Add(sweepInterval)
+
+	for key, entry := range l.entries {
+		entry.hits = expire(entry.hits, now.Add(-entry.window))
+		if len(entry.hits) == 0 {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// expire drops the hits at or before cutoff. Hits are in time order.
+func expire(hits []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
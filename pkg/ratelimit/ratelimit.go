@@ -0,0 +1,23 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+const KeyPrefix = "ratelimit:"
+
+// Result describes the state of a key after a call to Allow.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the oldest request in the window expires
+	RetryAfter time.Duration // zero when Allowed
+}
+
+// Limiter implements a sliding window log: at most limit requests per key
+// within any window-long period.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisAddrEnv names the variable holding the Redis address tests connect
+// to. The redis limiter is only tested when it is set.
+const redisAddrEnv = "GOSHOP_TEST_REDIS_ADDR"
+
+// clock is a manually advanced time source.
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+// limiters returns every Limiter implementation available to the test,
+// each reading time from clock.
+func limiters(t *testing.T, clock *clock) map[string]Limiter {
+	t.Helper()
+
+	memory := NewMemoryLimiter().(*memoryLimiter)
+	memory.now = clock.Now
+	result := map[string]Limiter{"memory": memory}
+
+	if addr := os.Getenv(redisAddrEnv); addr != "" {
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		t.Cleanup(func() { client.Close() })
+		result["redis"] = &redisLimiter{client: client, now: clock.Now}
+	}
+
+	return result
+}
+
+func TestAllow(t *testing.T) {
+	const limit, window = 2, 10 * time.Second
+
+	type step struct {
+		advance   time.Duration
+		key       string
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "rejects over the limit within the window",
+			steps: []step{
+				{key: "a", allowed: true, remaining: 1},
+				{advance: time.Second, key: "a", allowed: true, remaining: 0},
+				{advance: time.Second, key: "a", allowed: false, remaining: 0, retry: 8 * time.Second},
+			},
+		},
+		{
+			name: "admits again once the oldest request leaves the window",
+			steps: []step{
+				{key: "a", allowed: true, remaining: 1},
+				{advance: 5 * time.Second, key: "a", allowed: true, remaining: 0},
+				{advance: 5*time.Second - time.Millisecond, key: "a", allowed: false, remaining: 0, retry: time.Millisecond},
+				{advance: time.Millisecond, key: "a", allowed: true, remaining: 0},
+				{key: "a", allowed: false, remaining: 0, retry: 5 * time.Second},
+			},
+		},
+		{
+			name: "counts keys separately",
+			steps: []step{
+				{key: "a", allowed: true, remaining: 1},
+				{key: "a", allowed: true, remaining: 0},
+				{key: "a", allowed: false, remaining: 0, retry: window},
+				{key: "b", allowed: true, remaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &clock{now: time.UnixMilli(time.Now().UnixMilli())}
+			prefix := uuid.NewString() + ":"
+
+			for name, limiter := range limiters(t, clock) {
+				start := clock.now
+				for i, step := range tt.steps {
+					clock.now = clock.now.Add(step.advance)
+
+					result, err := limiter.Allow(context.Background(), prefix+name+":"+step.key, limit, window)
+					if err != nil {
+						t.Fatalf("%s step %d: %v", name, i, err)
+					}
+					if result.Allowed != step.allowed || result.Remaining != step.remaining || result.RetryAfter != step.retry {
+						t.Errorf("%s step %d: allowed = %v, remaining = %d, retry after %v; want %v, %d, %v",
+							name, i, result.Allowed, result.Remaining, result.RetryAfter, step.allowed, step.remaining, step.retry)
+					}
+				}
+				clock.now = start
+			}
+		})
+	}
+}
+
+func TestMemoryLimiterEvictsExpiredKeys(t *testing.T) {
+	clock := &clock{now: time.Now()}
+	limiter := NewMemoryLimiter().(*memoryLimiter)
+	limiter.now = clock.Now
+
+	ctx := context.Background()
+	limiter.Allow(ctx, "short", 1, time.Second)
+	limiter.Allow(ctx, "long", 1, time.Hour)
+
+	clock.now = clock.now.Add(sweepInterval)
+	limiter.Allow(ctx, "other", 1, time.Second)
+
+	if _, ok := limiter.entries["short"]; ok {
+		t.Error("key whose window has passed was kept")
+	}
+	if _, ok := limiter.entries["long"]; !ok {
+		t.Error("key still inside its window was evicted")
+	}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow keeps one sorted set member per request, scored by its
+// timestamp in milliseconds. It returns {allowed, remaining, reset_ms}.
+var slidingWindow = redis.NewScript(`
+local key    = KEYS[1]
+local now    = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit  = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = window - (now - tonumber(oldest[2]))
+end
+
+return {allowed, limit - count, reset}
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client, now: time.Now}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	now := l.now().UnixMilli()
+	member := fmt.Sprintf("%d-%s", now, uuid.NewString())
+
+	res, err := slidingWindow.Run(ctx, l.client, []string{KeyPrefix + key}, now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit,
+		Remaining:  int(res[1]),
+		ResetAfter: time.Duration(res[2]) * time.Millisecond,
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+
+	return result, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -23,6 +24,10 @@ func Connect(uri, password string, db int) error {
 		PoolTimeout:  4 * time.Second,
 	})
 
+	if err := redisotel.InstrumentTracing(Client); err != nil {
+		return fmt.Errorf("failed to instrument redis tracing: %w", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
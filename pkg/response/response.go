@@ -3,20 +3,29 @@ package response
 import (
 	"net/http"
 
+	"github.com/affandisy/goshop/pkg/i18n"
 	"github.com/gin-gonic/gin"
 )
 
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   *ErrorBody   `json:"error,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// ErrorBody tells clients what went wrong. Code is stable and meant for
+// programs, such as "product_not_found"; Detail is for people.
+type ErrorBody struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
 }
 
 func Success(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Success: true,
-		Message: message,
+		Message: translate(c, message),
 		Data:    data,
 	})
 }
@@ -24,54 +33,56 @@ func Success(c *gin.Context, message string, data interface{}) {
 func Created(c *gin.Context, message string, data interface{}) {
 	c.JSON(http.StatusCreated, Response{
 		Success: true,
-		Message: message,
+		Message: translate(c, message),
 		Data:    data,
 	})
 }
 
+// Fail responds with an error. The message is translated; detail is not.
+func Fail(c *gin.Context, status int, code, message, detail string) {
+	c.JSON(status, Response{
+		Success: false,
+		Message: translate(c, message),
+		Error:   &ErrorBody{Code: code, Detail: detail},
+	})
+}
+
 func BadRequest(c *gin.Context, message string, err error) {
-	errorMsg := ""
+	detail := ""
 	if err != nil {
-		errorMsg = err.Error()
+		detail = err.Error()
 	}
 
-	c.JSON(http.StatusBadRequest, Response{
-		Success: false,
-		Message: message,
-		Error:   errorMsg,
-	})
+	Fail(c, http.StatusBadRequest, "bad_request", message, detail)
 }
 
 func Unauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
-		Success: false,
-		Message: message,
-	})
+	Fail(c, http.StatusUnauthorized, "unauthorized", message, "")
 }
 
 func NotFound(c *gin.Context, message string) {
-	c.JSON(http.StatusNotFound, Response{
-		Success: false,
-		Message: message,
-	})
+	Fail(c, http.StatusNotFound, "not_found", message, "")
 }
 
+// InternalServerError hides err from the client. It is added to the
+// context's errors for the request log.
 func InternalServerError(c *gin.Context, message string, err error) {
-	errorMsg := ""
 	if err != nil {
-		errorMsg = err.Error()
+		_ = c.Error(err)
 	}
 
-	c.JSON(http.StatusInternalServerError, Response{
-		Success: false,
-		Message: message,
-		Error:   errorMsg,
-	})
+	Fail(c, http.StatusInternalServerError, "internal_error", message, "")
 }
 
 func Forbidden(c *gin.Context, message string) {
-	c.JSON(http.StatusForbidden, Response{
-		Success: false,
-		Message: message,
-	})
+	Fail(c, http.StatusForbidden, "forbidden", message, "")
+}
+
+func TooManyRequests(c *gin.Context, message string) {
+	Fail(c, http.StatusTooManyRequests, "too_many_requests", message, "")
+}
+
+// translate returns message in the locale of the request.
+func translate(c *gin.Context, message string) string {
+	return i18n.T(i18n.FromContext(c.Request.Context()), message)
 }
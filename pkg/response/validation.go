@@ -0,0 +1,127 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/affandisy/goshop/pkg/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is one field of a request that failed validation. Field is the
+// JSON path of the field, such as "items[0].quantity", and Rule the binding
+// tag it failed, such as "required".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError responds to a request that gin could not bind, with a
+// FieldError for each invalid field. Errors that are not about a field, such
+// as malformed JSON, are reported in Error.
+func ValidationError(c *gin.Context, err error) {
+	locale := i18n.FromContext(c.Request.Context())
+
+	var fieldErrs []FieldError
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			fieldErrs = append(fieldErrs, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: validationMessage(locale, fieldPath(fe), fe),
+			})
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: i18n.T(locale, "validation.type", typeErr.Field, jsonType(typeErr.Type)),
+		})
+	default:
+		BadRequest(c, "Invalid request body", err)
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, Response{
+		Success: false,
+		Message: translate(c, "Invalid request body"),
+		Error:   &ErrorBody{Code: "validation_failed"},
+		Errors:  fieldErrs,
+	})
+}
+
+// InvalidParam responds to a request whose path parameter name failed rule.
+func InvalidParam(c *gin.Context, name, rule string) {
+	locale := i18n.FromContext(c.Request.Context())
+
+	c.JSON(http.StatusBadRequest, Response{
+		Success: false,
+		Message: translate(c, "Invalid path parameter"),
+		Error:   &ErrorBody{Code: "validation_failed"},
+		Errors: []FieldError{{
+			Field:   name,
+			Rule:    rule,
+			Message: i18n.T(locale, "validation."+rule, name),
+		}},
+	})
+}
+
+// fieldPath is the field's path without the name of the request struct.
+func fieldPath(fe validator.FieldError) string {
+	_, path, _ := strings.Cut(fe.Namespace(), ".")
+	return path
+}
+
+func validationMessage(locale, field string, fe validator.FieldError) string {
+	key := "validation." + fe.Tag()
+	switch fe.Tag() {
+	case "min", "max", "len":
+		switch fe.Kind() {
+		case reflect.String:
+			key += ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key += ".items"
+		}
+	}
+
+	if i18n.T(locale, key) == key {
+		return i18n.T(locale, "validation.invalid", field)
+	}
+	param := fe.Param()
+	if param == "" {
+		return i18n.T(locale, key, field)
+	}
+	if fe.Tag() == "datetime" {
+		param = layoutNames.Replace(param)
+	}
+	return i18n.T(locale, key, field, param)
+}
+
+// layoutNames spells out Go time layouts, e.g. 2006-01-02 as YYYY-MM-DD.
+var layoutNames = strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD", "15", "hh", "04", "mm", "05", "ss")
+
+// jsonType names a Go type the way JSON does.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff returns the delay before retry number attempt: base * 2^(attempt-1)
+// capped at max, with up to 20% jitter so retries don't synchronise.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	jitter := time.Duration(rand.Int64N(int64(delay)/5 + 1))
+	return delay + jitter
+}
@@ -27,7 +27,7 @@ func NewExcelGenerator(sheetName string) *ExcelGenerator {
 }
 
 func (g *ExcelGenerator) SetTitle(title string) {
-	// Merge cells untuk title
+	// Merge cells for the title
 	g.file.MergeCell(g.sheetName, "A1", "F1")
 
 	// Set title
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Group runs long-lived background jobs that share a single lifetime.
+// Jobs receive a context that is cancelled when Stop is called.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn in its own goroutine. fn must return once ctx is done.
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		log.Printf("Worker %s started", name)
+		fn(g.ctx)
+		log.Printf("Worker %s stopped", name)
+	}()
+}
+
+// Stop cancels every job and waits for them to return, or for ctx to expire.
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}